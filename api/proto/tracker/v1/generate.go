@@ -0,0 +1,4 @@
+// Package trackerv1 сообщения и сервисы gRPC API, сгенерированные из *.proto
+package trackerv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative tracker/v1/people.proto tracker/v1/task.proto
@@ -0,0 +1,374 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: tracker/v1/people.proto
+
+package trackerv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PeopleService_ListPeople_FullMethodName     = "/tracker.v1.PeopleService/ListPeople"
+	PeopleService_GetPeople_FullMethodName      = "/tracker.v1.PeopleService/GetPeople"
+	PeopleService_CreatePeople_FullMethodName   = "/tracker.v1.PeopleService/CreatePeople"
+	PeopleService_UpdatePeople_FullMethodName   = "/tracker.v1.PeopleService/UpdatePeople"
+	PeopleService_DeletePeople_FullMethodName   = "/tracker.v1.PeopleService/DeletePeople"
+	PeopleService_RestorePeople_FullMethodName  = "/tracker.v1.PeopleService/RestorePeople"
+	PeopleService_OffboardPeople_FullMethodName = "/tracker.v1.PeopleService/OffboardPeople"
+)
+
+// PeopleServiceClient is the client API for PeopleService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PeopleService сотрудники. Права проверяются так же, как в REST API. Удаление, восстановление и
+// передачу задач сотрудника, затрагивающие время в закрытом периоде, администратор выполняет
+// с причиной в метаданных x-override-reason, как в TaskService
+type PeopleServiceClient interface {
+	// ListPeople страница сотрудников, доступных вызывающей стороне
+	ListPeople(ctx context.Context, in *ListPeopleRequest, opts ...grpc.CallOption) (*ListPeopleResponse, error)
+	// GetPeople сотрудник по идентификатору. Удаленный или недоступный сотрудник — NOT_FOUND
+	GetPeople(ctx context.Context, in *GetPeopleRequest, opts ...grpc.CallOption) (*People, error)
+	// CreatePeople добавляет сотрудника по паспорту, запрашивая ФИО и адрес во внешнем API.
+	// Если паспорт уже занят, возвращает ALREADY_EXISTS
+	CreatePeople(ctx context.Context, in *CreatePeopleRequest, opts ...grpc.CallOption) (*CreatePeopleResponse, error)
+	// UpdatePeople обновляет данные сотрудника
+	UpdatePeople(ctx context.Context, in *People, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// DeletePeople удаляет сотрудника, его задачи обрабатываются по tasks
+	DeletePeople(ctx context.Context, in *DeletePeopleRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// RestorePeople восстанавливает удаленного сотрудника
+	RestorePeople(ctx context.Context, in *RestorePeopleRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// OffboardPeople останавливает таймеры уходящего сотрудника и передает его неначатые задачи
+	OffboardPeople(ctx context.Context, in *OffboardPeopleRequest, opts ...grpc.CallOption) (*Offboarding, error)
+}
+
+type peopleServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPeopleServiceClient(cc grpc.ClientConnInterface) PeopleServiceClient {
+	return &peopleServiceClient{cc}
+}
+
+func (c *peopleServiceClient) ListPeople(ctx context.Context, in *ListPeopleRequest, opts ...grpc.CallOption) (*ListPeopleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPeopleResponse)
+	err := c.cc.Invoke(ctx, PeopleService_ListPeople_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peopleServiceClient) GetPeople(ctx context.Context, in *GetPeopleRequest, opts ...grpc.CallOption) (*People, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(People)
+	err := c.cc.Invoke(ctx, PeopleService_GetPeople_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peopleServiceClient) CreatePeople(ctx context.Context, in *CreatePeopleRequest, opts ...grpc.CallOption) (*CreatePeopleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreatePeopleResponse)
+	err := c.cc.Invoke(ctx, PeopleService_CreatePeople_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peopleServiceClient) UpdatePeople(ctx context.Context, in *People, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, PeopleService_UpdatePeople_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peopleServiceClient) DeletePeople(ctx context.Context, in *DeletePeopleRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, PeopleService_DeletePeople_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peopleServiceClient) RestorePeople(ctx context.Context, in *RestorePeopleRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, PeopleService_RestorePeople_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peopleServiceClient) OffboardPeople(ctx context.Context, in *OffboardPeopleRequest, opts ...grpc.CallOption) (*Offboarding, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Offboarding)
+	err := c.cc.Invoke(ctx, PeopleService_OffboardPeople_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PeopleServiceServer is the server API for PeopleService service.
+// All implementations must embed UnimplementedPeopleServiceServer
+// for forward compatibility.
+//
+// PeopleService сотрудники. Права проверяются так же, как в REST API. Удаление, восстановление и
+// передачу задач сотрудника, затрагивающие время в закрытом периоде, администратор выполняет
+// с причиной в метаданных x-override-reason, как в TaskService
+type PeopleServiceServer interface {
+	// ListPeople страница сотрудников, доступных вызывающей стороне
+	ListPeople(context.Context, *ListPeopleRequest) (*ListPeopleResponse, error)
+	// GetPeople сотрудник по идентификатору. Удаленный или недоступный сотрудник — NOT_FOUND
+	GetPeople(context.Context, *GetPeopleRequest) (*People, error)
+	// CreatePeople добавляет сотрудника по паспорту, запрашивая ФИО и адрес во внешнем API.
+	// Если паспорт уже занят, возвращает ALREADY_EXISTS
+	CreatePeople(context.Context, *CreatePeopleRequest) (*CreatePeopleResponse, error)
+	// UpdatePeople обновляет данные сотрудника
+	UpdatePeople(context.Context, *People) (*emptypb.Empty, error)
+	// DeletePeople удаляет сотрудника, его задачи обрабатываются по tasks
+	DeletePeople(context.Context, *DeletePeopleRequest) (*emptypb.Empty, error)
+	// RestorePeople восстанавливает удаленного сотрудника
+	RestorePeople(context.Context, *RestorePeopleRequest) (*emptypb.Empty, error)
+	// OffboardPeople останавливает таймеры уходящего сотрудника и передает его неначатые задачи
+	OffboardPeople(context.Context, *OffboardPeopleRequest) (*Offboarding, error)
+	mustEmbedUnimplementedPeopleServiceServer()
+}
+
+// UnimplementedPeopleServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPeopleServiceServer struct{}
+
+func (UnimplementedPeopleServiceServer) ListPeople(context.Context, *ListPeopleRequest) (*ListPeopleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPeople not implemented")
+}
+func (UnimplementedPeopleServiceServer) GetPeople(context.Context, *GetPeopleRequest) (*People, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPeople not implemented")
+}
+func (UnimplementedPeopleServiceServer) CreatePeople(context.Context, *CreatePeopleRequest) (*CreatePeopleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePeople not implemented")
+}
+func (UnimplementedPeopleServiceServer) UpdatePeople(context.Context, *People) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePeople not implemented")
+}
+func (UnimplementedPeopleServiceServer) DeletePeople(context.Context, *DeletePeopleRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePeople not implemented")
+}
+func (UnimplementedPeopleServiceServer) RestorePeople(context.Context, *RestorePeopleRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestorePeople not implemented")
+}
+func (UnimplementedPeopleServiceServer) OffboardPeople(context.Context, *OffboardPeopleRequest) (*Offboarding, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OffboardPeople not implemented")
+}
+func (UnimplementedPeopleServiceServer) mustEmbedUnimplementedPeopleServiceServer() {}
+func (UnimplementedPeopleServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafePeopleServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PeopleServiceServer will
+// result in compilation errors.
+type UnsafePeopleServiceServer interface {
+	mustEmbedUnimplementedPeopleServiceServer()
+}
+
+func RegisterPeopleServiceServer(s grpc.ServiceRegistrar, srv PeopleServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPeopleServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PeopleService_ServiceDesc, srv)
+}
+
+func _PeopleService_ListPeople_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPeopleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeopleServiceServer).ListPeople(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeopleService_ListPeople_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeopleServiceServer).ListPeople(ctx, req.(*ListPeopleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeopleService_GetPeople_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPeopleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeopleServiceServer).GetPeople(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeopleService_GetPeople_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeopleServiceServer).GetPeople(ctx, req.(*GetPeopleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeopleService_CreatePeople_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePeopleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeopleServiceServer).CreatePeople(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeopleService_CreatePeople_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeopleServiceServer).CreatePeople(ctx, req.(*CreatePeopleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeopleService_UpdatePeople_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(People)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeopleServiceServer).UpdatePeople(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeopleService_UpdatePeople_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeopleServiceServer).UpdatePeople(ctx, req.(*People))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeopleService_DeletePeople_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePeopleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeopleServiceServer).DeletePeople(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeopleService_DeletePeople_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeopleServiceServer).DeletePeople(ctx, req.(*DeletePeopleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeopleService_RestorePeople_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestorePeopleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeopleServiceServer).RestorePeople(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeopleService_RestorePeople_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeopleServiceServer).RestorePeople(ctx, req.(*RestorePeopleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeopleService_OffboardPeople_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OffboardPeopleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeopleServiceServer).OffboardPeople(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeopleService_OffboardPeople_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeopleServiceServer).OffboardPeople(ctx, req.(*OffboardPeopleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PeopleService_ServiceDesc is the grpc.ServiceDesc for PeopleService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PeopleService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tracker.v1.PeopleService",
+	HandlerType: (*PeopleServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListPeople",
+			Handler:    _PeopleService_ListPeople_Handler,
+		},
+		{
+			MethodName: "GetPeople",
+			Handler:    _PeopleService_GetPeople_Handler,
+		},
+		{
+			MethodName: "CreatePeople",
+			Handler:    _PeopleService_CreatePeople_Handler,
+		},
+		{
+			MethodName: "UpdatePeople",
+			Handler:    _PeopleService_UpdatePeople_Handler,
+		},
+		{
+			MethodName: "DeletePeople",
+			Handler:    _PeopleService_DeletePeople_Handler,
+		},
+		{
+			MethodName: "RestorePeople",
+			Handler:    _PeopleService_RestorePeople_Handler,
+		},
+		{
+			MethodName: "OffboardPeople",
+			Handler:    _PeopleService_OffboardPeople_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "tracker/v1/people.proto",
+}
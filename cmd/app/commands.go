@@ -0,0 +1,221 @@
+package main
+
+import (
+	dbase "GoTimeTracker/database"
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/encryption"
+	"GoTimeTracker/internal/enrichment"
+	"GoTimeTracker/internal/importer"
+	"GoTimeTracker/internal/model"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// commands служебные команды, запускаемые вместо сервера: Tracker <команда> [флаги]
+var commands = map[string]func(args []string) error{
+	"import":            importCommand,
+	"encrypt-passports": encryptPassportsCommand,
+	"generate-key":      generateKeyCommand,
+	"create-user":       createUserCommand,
+	"mint-key":          mintKeyCommand,
+	"purge":             purgeCommand,
+}
+
+// runCommand выполняет команду, если она указана в аргументах запуска.
+// Возвращает false, если команды нет и нужно запускать сервер
+func runCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "неизвестная команда %s\n", args[0])
+		os.Exit(2)
+	}
+	if err := command(args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// cliActor автор изменений, выполняемых служебными командами, для журнала изменений
+func cliActor() model.AuditActor {
+	name := os.Getenv("USER")
+	if current, err := user.Current(); err == nil {
+		name = current.Username
+	}
+	return model.AuditActor{Kind: auth.KindCLI, Name: name}
+}
+
+// importCommand импортирует сотрудников из CSV или JSON файла и печатает отчет в формате JSON
+func importCommand(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	file := flags.String("file", "", "CSV или JSON файл со списком паспортов")
+	format := flags.String("format", "", "формат файла: csv или json, по умолчанию по расширению")
+	var opts importer.Options
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "только показать результат без записи")
+	flags.BoolVar(&opts.Atomic, "atomic", false, "добавить всех или никого")
+	flags.IntVar(&opts.Concurrency, "concurrency", importer.DefaultConcurrency, "количество одновременных запросов к внешнему API")
+	_ = flags.Parse(args)
+
+	if *file == "" {
+		return fmt.Errorf("не указан файл импорта (-file)")
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rows, err := importer.Parse(f, *format)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("файл импорта не содержит строк")
+	}
+
+	db, err := dbase.GetInstance()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	report, err := importer.Run(context.Background(), db, enrichment.GetInstance(), cliActor(), rows, opts)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(report); err != nil {
+		return err
+	}
+	if opts.Atomic && !opts.DryRun && !report.Committed {
+		return fmt.Errorf("импорт отменен: отклонено строк %d", report.Rejected)
+	}
+	return nil
+}
+
+// encryptPassportsCommand шифрует паспорта существующих сотрудников и перешифровывает их
+// текущим ключом после ротации
+func encryptPassportsCommand(args []string) error {
+	flags := flag.NewFlagSet("encrypt-passports", flag.ExitOnError)
+	_ = flags.Parse(args)
+
+	db, err := dbase.GetInstance()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	updated, err := db.EncryptPassports(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("обновлено сотрудников: %d\n", updated)
+	return nil
+}
+
+// generateKeyCommand печатает новый случайный ключ для PASSPORT_KEYS или PASSPORT_INDEX_KEY
+func generateKeyCommand(args []string) error {
+	flags := flag.NewFlagSet("generate-key", flag.ExitOnError)
+	id := flags.String("id", "", "идентификатор ключа, если указан, ключ печатается в виде id:base64")
+	_ = flags.Parse(args)
+
+	key, err := encryption.GenerateKey()
+	if err != nil {
+		return err
+	}
+	if *id != "" {
+		key = *id + ":" + key
+	}
+	fmt.Println(key)
+	return nil
+}
+
+// createUserCommand добавляет пользователя для входа через /login
+func createUserCommand(args []string) error {
+	flags := flag.NewFlagSet("create-user", flag.ExitOnError)
+	login := flags.String("login", "", "логин")
+	password := flags.String("password", "", "пароль, по умолчанию читается из TRACKER_PASSWORD")
+	peopleId := flags.Int("people-id", 0, "идентификатор сотрудника, которым является пользователь")
+	role := flags.String("role", string(auth.RoleEmployee), "роль: admin, manager или employee")
+	_ = flags.Parse(args)
+
+	if *password == "" {
+		*password = os.Getenv("TRACKER_PASSWORD")
+	}
+	if *login == "" || *password == "" {
+		return fmt.Errorf("необходимо указать логин (-login) и пароль (-password или TRACKER_PASSWORD)")
+	}
+	if !auth.ValidRole(auth.Role(*role)) {
+		return fmt.Errorf("неизвестная роль %s", *role)
+	}
+
+	hash, err := auth.HashPassword(*password)
+	if err != nil {
+		return err
+	}
+	user := model.User{Login: *login, PasswordHash: hash, Role: *role}
+	if *peopleId != 0 {
+		user.PeopleId = peopleId
+	}
+
+	db, err := dbase.GetInstance()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	id, err := db.AddUser(context.Background(), cliActor(), user)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("пользователь %s добавлен, id %d\n", *login, id)
+	return nil
+}
+
+// mintKeyCommand выпускает ключ доступа для интеграции. Ключ печатается один раз, в базе хранится только хеш.
+// Роль указывается явно, чтобы ключ не получал права администратора по умолчанию
+func mintKeyCommand(args []string) error {
+	flags := flag.NewFlagSet("mint-key", flag.ExitOnError)
+	name := flags.String("name", "", "название интеграции")
+	role := flags.String("role", "", "роль: admin, manager или employee")
+	_ = flags.Parse(args)
+
+	if *name == "" || *role == "" {
+		return fmt.Errorf("необходимо указать название интеграции (-name) и роль ключа (-role)")
+	}
+	if !auth.ValidRole(auth.Role(*role)) {
+		return fmt.Errorf("неизвестная роль %s", *role)
+	}
+
+	key, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return err
+	}
+
+	db, err := dbase.GetInstance()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err = db.AddAPIKey(context.Background(), cliActor(), *name, *role, hash); err != nil {
+		return err
+	}
+	fmt.Println(key)
+	return nil
+}
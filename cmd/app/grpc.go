@@ -0,0 +1,51 @@
+package main
+
+import (
+	"GoTimeTracker/internal/grpcapi"
+	"GoTimeTracker/internal/health"
+	"GoTimeTracker/pkg/config"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"go.uber.org/zap"
+	"os"
+	"time"
+)
+
+// defaultGRPCPort порт gRPC-сервера, если GRPC_PORT не задан
+const defaultGRPCPort = "9090"
+
+// startGRPC запускает gRPC-сервер на порту GRPC_PORT рядом с HTTP-сервером.
+// GRPC_PORT=off отключает gRPC. Возвращает функцию остановки: она дожидается завершения
+// начатых вызовов, но не дольше timeout. Проверки состояния прекращаются с отменой ctx
+func startGRPC(ctx context.Context, checker *health.Checker, timeout time.Duration) func() {
+	port := os.Getenv("GRPC_PORT")
+	if port == "" {
+		port = defaultGRPCPort
+	}
+	if port == "off" {
+		logger.Info("gRPC-сервер отключен")
+		return func() {}
+	}
+
+	server := grpcapi.NewServer(ctx, checker, config.Duration("READY_CACHE_TTL", defaultReadyCacheTTL))
+	go func() {
+		if err := grpcapi.Serve(server, ":"+port); err != nil {
+			logger.Fatal("Ошибка запуска gRPC-сервера", zap.Error(err))
+		}
+	}()
+
+	return func() {
+		logger.Info("Остановка gRPC-сервера")
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(timeout):
+			logger.Error("gRPC-вызовы не завершились вовремя, соединения закрываются", zap.Duration("timeout", timeout))
+			server.Stop()
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	dbase "GoTimeTracker/database"
+	"GoTimeTracker/internal/enrichment"
+	"GoTimeTracker/internal/health"
+	"GoTimeTracker/pkg/config"
+	"time"
+)
+
+// Ограничение времени одной проверки готовности и время жизни ее результата по умолчанию
+const (
+	defaultReadyTimeout  = 2 * time.Second
+	defaultReadyCacheTTL = 5 * time.Second
+)
+
+// newHealthChecker собирает проверки готовности: подключение к базе данных и ее схема критичны,
+// внешний API нет — без него сотрудники добавляются без обогащения
+func newHealthChecker(db *dbase.Database) *health.Checker {
+	checks := []health.Check{
+		{Name: "database", Critical: true, Run: db.Ping},
+		{Name: "schema", Critical: true, Run: db.CheckSchema},
+		{Name: "enrichment"},
+	}
+	if client := enrichment.GetInstance(); client != nil {
+		checks[2].Run = client.Ping
+	}
+	return health.NewChecker(
+		config.Duration("READY_CHECK_TIMEOUT", defaultReadyTimeout),
+		config.Duration("READY_CACHE_TTL", defaultReadyCacheTTL),
+		checks...)
+}
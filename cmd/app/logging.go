@@ -0,0 +1,41 @@
+package main
+
+import (
+	"GoTimeTracker/pkg/logger"
+	"errors"
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+	"io/fs"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// configureLogger применяет настройки журнала из переменных окружения
+func configureLogger() error {
+	cfg, err := logger.ConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	return logger.Configure(cfg)
+}
+
+// reloadLoggerOnSignal по SIGHUP перечитывает .env и заново настраивает журнал без перезапуска.
+// При ошибке в новых настройках продолжает работать прежний журнал
+func reloadLoggerOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			if err := godotenv.Overload(".env"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				logger.Error("Ошибка загрузки переменных окружения", zap.Error(err))
+				continue
+			}
+			if err := configureLogger(); err != nil {
+				logger.Error("Ошибка настройки журнала, настройки не изменены", zap.Error(err))
+				continue
+			}
+			logger.Info("Настройки журнала перечитаны", zap.String("level", logger.Level()))
+		}
+	}()
+}
@@ -3,16 +3,34 @@ package main
 import (
 	dbase "GoTimeTracker/database"
 	_ "GoTimeTracker/docs"
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/events"
+	"GoTimeTracker/internal/graph"
+	"GoTimeTracker/internal/health"
+	"GoTimeTracker/internal/ratelimit"
+	"GoTimeTracker/internal/request"
 	"GoTimeTracker/internal/routes"
+	"GoTimeTracker/internal/tracing"
+	"GoTimeTracker/pkg/config"
 	"GoTimeTracker/pkg/logger"
+	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
+	"io/fs"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+// defaultShutdownTimeout сколько ждать завершения начатых запросов при остановке, если SHUTDOWN_TIMEOUT не задан
+const defaultShutdownTimeout = 10 * time.Second
+
 //	@title			Task Tracker
 //	@version		1.0
 //	@description	Тестовое задание для Effective Mobile.
@@ -22,37 +40,123 @@ import (
 
 // @host		localhost:8080
 // @BasePath	/
+
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				JWT из /login в виде "Bearer <token>"
+
+//	@securityDefinitions.apikey	ApiKeyAuth
+//	@in							header
+//	@name						X-API-Key
+//	@description				Ключ доступа интеграции
 func main() {
 
+	// .env необязателен: в окружениях переменные задаются напрямую, для разработки
+	// файл создается из .env.example
 	err := godotenv.Load(".env")
-	if err != nil {
-		logger.Fatal("Ошибка загрузки переменных окружения")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.Fatal("Ошибка загрузки переменных окружения", zap.Error(err))
 	}
+	if err = configureLogger(); err != nil {
+		logger.Fatal("Ошибка настройки журнала", zap.Error(err))
+	}
+
+	if runCommand(os.Args[1:]) {
+		return
+	}
+
+	if err = auth.LoadConfig(); err != nil {
+		logger.Fatal("Ошибка настройки токенов", zap.Error(err))
+	}
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
+	reloadLoggerOnSignal()
+
+	shutdownTracing, err := tracing.Init(context.Background())
+	if err != nil {
+		logger.Fatal("Ошибка настройки трассировки", zap.Error(err))
+	}
+	defer shutdownTracing(context.Background())
+
 	db, err := dbase.GetInstance()
 	if err != nil {
 		logger.Fatal("Ошибка подключения к базе данных", zap.Error(err))
 	}
 	defer db.Close()
 
-	router := gin.Default()
+	startPurge(db)
+	registerMetrics(db)
+
+	// Запросы пишет в журнал request.AccessLog, поэтому текстовый журнал gin не подключается
+	router := gin.New()
+	router.Use(gin.Recovery())
+	// Адрес клиента для ограничения частоты и журнала берется из X-Forwarded-For только
+	// от прокси из TRUSTED_PROXIES, по умолчанию заголовку не доверяют
+	if err = router.SetTrustedProxies(config.List("TRUSTED_PROXIES")); err != nil {
+		logger.Fatal("Неверное значение TRUSTED_PROXIES", zap.Error(err))
+	}
 
 	//router.LoadHTMLGlob("web/pages/*")
 	//router.Static("/pages", "./web/pages")
 	//router.Static("/js", "./web/js")
 	//router.Static("/styles", "./web/styles")
 
-	routes.SetupRoutes(router)
+	rateLimits, err := ratelimit.ConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Ошибка настройки ограничения частоты запросов", zap.Error(err))
+	}
+	bodyLimits, err := request.BodyLimitsFromEnv()
+	if err != nil {
+		logger.Fatal("Ошибка настройки ограничения размера запросов", zap.Error(err))
+	}
+	graphOptions, err := graph.OptionsFromEnv()
+	if err != nil {
+		logger.Fatal("Ошибка настройки GraphQL", zap.Error(err))
+	}
+	eventsConfig, err := events.ConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Ошибка настройки потока событий", zap.Error(err))
+	}
+	events.Configure(eventsConfig)
+	startWebhooks(db)
+	startOutbox(db)
+	routes.SetupRoutes(router, ratelimit.New(ratelimit.NewMemoryStore(), rateLimits), bodyLimits, graphOptions)
+
+	checker := newHealthChecker(db)
+	router.GET("/healthz", health.Liveness())
+	router.GET("/readyz", checker.Readiness())
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// По SIGINT или SIGTERM серверы перестают принимать запросы и дожидаются начатых
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	shutdownTimeout := config.Duration("SHUTDOWN_TIMEOUT", defaultShutdownTimeout)
+
+	stopGRPC := startGRPC(ctx, checker, shutdownTimeout)
+	defer stopGRPC()
+	stopMetrics := startMetrics(shutdownTimeout)
+	defer stopMetrics()
+
+	server := &http.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		<-ctx.Done()
+		logger.Info("Остановка сервера")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Запросы не завершились вовремя", zap.Error(err))
+		}
+	}()
+
 	logger.Info("Запуск сервера на порту", zap.String("port", port))
-	err = router.Run(":" + port)
-	if err != nil {
+	err = server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		logger.Fatal("Ошибка запуска сервиса", zap.Error(err))
 	}
 }
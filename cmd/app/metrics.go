@@ -0,0 +1,71 @@
+package main
+
+import (
+	dbase "GoTimeTracker/database"
+	"GoTimeTracker/internal/events"
+	"GoTimeTracker/internal/metrics"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"math"
+	"net/http"
+	"os"
+	"time"
+)
+
+// defaultMetricsPort порт сервера метрик, если METRICS_PORT не задан
+const defaultMetricsPort = "9091"
+
+// startMetrics запускает сервер /metrics на порту METRICS_PORT отдельно от API: метрики
+// не требуют аутентификации, поэтому порт должен быть доступен только сборщику метрик.
+// METRICS_PORT=off отключает сервер. Возвращает функцию остановки, ожидающую не дольше timeout
+func startMetrics(timeout time.Duration) func() {
+	port := os.Getenv("METRICS_PORT")
+	if port == "" {
+		port = defaultMetricsPort
+	}
+	if port == "off" {
+		logger.Info("Сервер метрик отключен")
+		return func() {}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	server := &http.Server{Addr: ":" + port, Handler: mux}
+	go func() {
+		logger.Info("Запуск сервера метрик на порту", zap.String("port", port))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("Ошибка запуска сервера метрик", zap.Error(err))
+		}
+	}()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Error("Сервер метрик не остановился вовремя", zap.Error(err))
+		}
+	}
+}
+
+// registerMetrics публикует бизнес-показатели, вычисляемые запросом к базе при каждом сборе метрик
+func registerMetrics(db *dbase.Database) {
+	metrics.RegisterGauge("running_timers", "Количество задач с запущенным таймером", countGauge(db.CountRunningTimers))
+	metrics.RegisterGauge("people", "Количество не удаленных сотрудников", countGauge(db.CountPeople))
+	metrics.RegisterGauge("outbox_pending", "Недоставленные доменные события", countGauge(db.CountOutboxPending))
+	metrics.RegisterGauge("event_subscribers", "Подписчики на поток событий", func() float64 {
+		return float64(events.Default().Subscribers())
+	})
+}
+
+// countGauge приводит результат подсчета к значению показателя, при ошибке показатель не определен
+func countGauge(count func(context.Context) (int, error)) func() float64 {
+	return func() float64 {
+		value, err := count(context.Background())
+		if err != nil {
+			return math.NaN()
+		}
+		return float64(value)
+	}
+}
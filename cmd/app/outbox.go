@@ -0,0 +1,46 @@
+package main
+
+import (
+	dbase "GoTimeTracker/database"
+	"GoTimeTracker/internal/events"
+	"GoTimeTracker/internal/outbox"
+	"GoTimeTracker/internal/webhooks"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"go.uber.org/zap"
+)
+
+// startOutbox запускает доставку доменных событий получателям из OUTBOX_SINKS и в поток /events.
+// Нулевой OUTBOX_INTERVAL отключает доставку и поток, события при этом копятся в outbox
+func startOutbox(db *dbase.Database) {
+	cfg, err := outbox.ConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Ошибка настройки доставки доменных событий", zap.Error(err))
+	}
+	if cfg.Interval <= 0 {
+		logger.Info("Доставка доменных событий и поток /events отключены")
+		return
+	}
+
+	sinks := make([]outbox.Sink, 0, len(cfg.Sinks))
+	for _, name := range cfg.Sinks {
+		switch name {
+		case outbox.SinkWebhooks:
+			sinks = append(sinks, webhooks.NewSink(db))
+		case outbox.SinkLog:
+			sinks = append(sinks, outbox.LogSink{})
+		case outbox.SinkFile:
+			file, err := outbox.NewFileSink(cfg.File)
+			if err != nil {
+				logger.Fatal("Ошибка открытия файла доменных событий", zap.String("file", cfg.File), zap.Error(err))
+			}
+			sinks = append(sinks, file)
+		}
+	}
+	// Шина не возвращает ошибок, поэтому последней получает событие один раз: при ошибке
+	// предыдущего получателя событие повторяется, не дойдя до подписчиков
+	sinks = append(sinks, outbox.NewHubSink(events.Default()))
+
+	outbox.New(db, cfg, sinks...).Start(context.Background())
+	logger.Info("Запущена доставка доменных событий", zap.Strings("sinks", cfg.Sinks), zap.Duration("interval", cfg.Interval))
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	dbase "GoTimeTracker/database"
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/config"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"flag"
+	"fmt"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Срок хранения удаленных записей и периодичность очистки по умолчанию
+const (
+	defaultPurgeRetention = 30 * 24 * time.Hour
+	defaultPurgeInterval  = 24 * time.Hour
+)
+
+// startPurge раз в PURGE_INTERVAL окончательно удаляет записи, удаленные более PURGE_RETENTION назад,
+// и доменные события, доставленные более PURGE_RETENTION назад. Нулевой PURGE_INTERVAL отключает очистку
+func startPurge(db *dbase.Database) {
+	retention := config.Duration("PURGE_RETENTION", defaultPurgeRetention)
+	interval := config.Duration("PURGE_INTERVAL", defaultPurgeInterval)
+	if interval <= 0 {
+		logger.Info("Очистка удаленных записей отключена")
+		return
+	}
+
+	actor := model.AuditActor{Kind: auth.KindSystem, Name: "purge"}
+	go func() {
+		for {
+			if _, _, err := db.PurgeDeleted(context.Background(), actor, time.Now().Add(-retention)); err != nil {
+				logger.Error("Ошибка при очистке удаленных записей", zap.Error(err))
+			}
+			if _, err := db.PurgeOutbox(context.Background(), time.Now().Add(-retention)); err != nil {
+				logger.Error("Ошибка при очистке доставленных доменных событий", zap.Error(err))
+			}
+			time.Sleep(interval)
+		}
+	}()
+	logger.Info("Запущена очистка удаленных записей", zap.Duration("retention", retention), zap.Duration("interval", interval))
+}
+
+// purgeCommand окончательно удаляет записи, удаленные раньше срока хранения
+func purgeCommand(args []string) error {
+	flags := flag.NewFlagSet("purge", flag.ExitOnError)
+	retention := flags.Duration("retention", config.Duration("PURGE_RETENTION", defaultPurgeRetention), "срок хранения удаленных записей")
+	_ = flags.Parse(args)
+
+	db, err := dbase.GetInstance()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	people, tasks, err := db.PurgeDeleted(context.Background(), cliActor(), time.Now().Add(-*retention))
+	if err != nil {
+		return err
+	}
+	events, err := db.PurgeOutbox(context.Background(), time.Now().Add(-*retention))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("удалено сотрудников: %d, задач: %d, доставленных событий: %d\n", people, tasks, events)
+	return nil
+}
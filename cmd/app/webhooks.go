@@ -0,0 +1,26 @@
+package main
+
+import (
+	dbase "GoTimeTracker/database"
+	"GoTimeTracker/internal/webhooks"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"go.uber.org/zap"
+)
+
+// startWebhooks запускает отправку доставок подписчикам-вебхукам. В очередь доставки ставит
+// получатель webhooks доменных событий (см. startOutbox). Нулевой WEBHOOK_INTERVAL отключает отправку
+func startWebhooks(db *dbase.Database) {
+	cfg, err := webhooks.ConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Ошибка настройки вебхуков", zap.Error(err))
+	}
+	if cfg.Interval <= 0 {
+		logger.Info("Доставка вебхуков отключена")
+		return
+	}
+
+	webhooks.New(db, cfg).Start(context.Background())
+	logger.Info("Запущена доставка вебхуков", zap.Duration("interval", cfg.Interval),
+		zap.Int("maxAttempts", cfg.MaxAttempts), zap.Int("disableAfter", cfg.DisableAfter))
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"GoTimeTracker/pkg/client"
+)
+
+// requestTimeout время ожидания ответа сервера
+const requestTimeout = 30 * time.Second
+
+// newClient клиент API с адресом и учетными данными из настроек
+func newClient(cfg config) (*client.Client, error) {
+	opts := []client.Option{
+		client.WithHTTPClient(&http.Client{Timeout: requestTimeout}),
+		client.WithUserAgent("tracker-cli"),
+	}
+	switch {
+	case cfg.APIKey != "":
+		opts = append(opts, client.WithAuth(client.APIKey(cfg.APIKey)))
+	case cfg.Token != "":
+		opts = append(opts, client.WithAuth(client.BearerToken(cfg.Token)))
+	}
+	return client.New(cfg.URL, opts...)
+}
+
+// describe дополняет ошибку сервера подсказкой, что делать пользователю
+func describe(err error) error {
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%s, выполните tracker login", apiErr.Message)
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("%s, повторите позже", apiErr.Message)
+	}
+	return errors.New(apiErr.Message)
+}
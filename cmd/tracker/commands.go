@@ -0,0 +1,437 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/client"
+	"golang.org/x/term"
+)
+
+// loginCommand входит по логину и паролю или сохраняет ключ доступа
+func loginCommand(flags *flag.FlagSet, out *output) func(args []string) error {
+	address := flags.String("url", "", "адрес сервера, по умолчанию сохраненный или "+defaultURL)
+	login := flags.String("login", "", "логин, по умолчанию запрашивается")
+	passwordStdin := flags.Bool("password-stdin", false, "прочитать пароль из стандартного ввода")
+	apiKey := flags.String("api-key", "", "ключ доступа вместо логина и пароля")
+
+	return func(args []string) error {
+		cfg, err := readConfig()
+		if err != nil {
+			return err
+		}
+		if *address != "" {
+			cfg.URL = strings.TrimRight(*address, "/")
+		}
+		ctx := context.Background()
+
+		if *apiKey != "" {
+			cfg.Token, cfg.ExpiresAt, cfg.APIKey = "", nil, *apiKey
+		} else {
+			stdin := bufio.NewReader(os.Stdin)
+			if *login == "" {
+				if *login, err = prompt(stdin, "Логин: "); err != nil {
+					return err
+				}
+			}
+			password, err := readPassword(stdin, *passwordStdin)
+			if err != nil {
+				return err
+			}
+			api, err := newClient(cfg)
+			if err != nil {
+				return err
+			}
+			resp, err := api.Login(ctx, *login, password)
+			if err != nil {
+				return err
+			}
+			cfg.Token, cfg.ExpiresAt, cfg.APIKey = resp.Token, &resp.ExpiresAt, ""
+		}
+
+		api, err := newClient(cfg)
+		if err != nil {
+			return err
+		}
+		me, err := api.Me(ctx)
+		if err != nil {
+			return err
+		}
+		if err = saveConfig(cfg); err != nil {
+			return err
+		}
+		return out.print(me, func(w io.Writer) {
+			fmt.Fprintf(w, "Вход выполнен: %s (%s) на %s\n", me.Name, me.Role, cfg.URL)
+		})
+	}
+}
+
+// logoutCommand удаляет сохраненный токен и ключ доступа, адрес сервера остается
+func logoutCommand(flags *flag.FlagSet, out *output) func(args []string) error {
+	return func(args []string) error {
+		cfg, err := readConfig()
+		if err != nil {
+			return err
+		}
+		cfg.Token, cfg.ExpiresAt, cfg.APIKey = "", nil, ""
+		if err = saveConfig(cfg); err != nil {
+			return err
+		}
+		return out.print(map[string]string{"status": "logged_out"}, func(w io.Writer) {
+			fmt.Fprintln(w, "Выход выполнен")
+		})
+	}
+}
+
+// whoamiCommand показывает пользователя, от имени которого работает клиент
+func whoamiCommand(flags *flag.FlagSet, out *output) func(args []string) error {
+	return func(args []string) error {
+		cfg, api, err := authorizedClient()
+		if err != nil {
+			return err
+		}
+		me, err := api.Me(context.Background())
+		if err != nil {
+			return err
+		}
+		return out.print(me, func(w io.Writer) {
+			fmt.Fprintf(w, "%s (%s) на %s\n", me.Name, me.Role, cfg.URL)
+			if me.PeopleId != nil {
+				fmt.Fprintf(w, "Сотрудник: %d\n", *me.PeopleId)
+			}
+		})
+	}
+}
+
+// startCommand начинает отсчет времени по задаче
+func startCommand(flags *flag.FlagSet, out *output) func(args []string) error {
+	return func(args []string) error {
+		id, err := taskIdArg(args, true)
+		if err != nil {
+			return err
+		}
+		_, api, err := authorizedClient()
+		if err != nil {
+			return err
+		}
+		if err = api.StartTask(context.Background(), id); err != nil {
+			return err
+		}
+		return out.print(map[string]any{"task_id": id, "status": "started"}, func(w io.Writer) {
+			fmt.Fprintf(w, "Отсчет времени по задаче %d начат\n", id)
+		})
+	}
+}
+
+// stopCommand останавливает отсчет времени по задаче. Без id останавливает
+// единственную запущенную задачу сотрудника
+func stopCommand(flags *flag.FlagSet, out *output) func(args []string) error {
+	peopleId := flags.Int("people", 0, "идентификатор сотрудника, по умолчанию текущий пользователь")
+
+	return func(args []string) error {
+		id, err := taskIdArg(args, false)
+		if err != nil {
+			return err
+		}
+		_, api, err := authorizedClient()
+		if err != nil {
+			return err
+		}
+		ctx := context.Background()
+
+		if id == 0 {
+			running, err := runningTasks(ctx, api, *peopleId)
+			if err != nil {
+				return err
+			}
+			switch len(running) {
+			case 0:
+				return errors.New("нет запущенных задач")
+			case 1:
+				id = running[0].Id
+			default:
+				ids := make([]string, len(running))
+				for i, task := range running {
+					ids[i] = strconv.Itoa(task.Id)
+				}
+				return fmt.Errorf("запущено несколько задач (%s), укажите id", strings.Join(ids, ", "))
+			}
+		}
+
+		if err = api.EndTask(ctx, id); err != nil {
+			return err
+		}
+		return out.print(map[string]any{"task_id": id, "status": "stopped"}, func(w io.Writer) {
+			fmt.Fprintf(w, "Отсчет времени по задаче %d остановлен\n", id)
+		})
+	}
+}
+
+// runningEntry запущенная задача
+type runningEntry struct {
+	TaskId  int       `json:"task_id"`
+	Name    string    `json:"name"`
+	Project *string   `json:"project,omitempty"`
+	Start   time.Time `json:"start"`
+	Minutes int       `json:"minutes"`
+}
+
+// statusCommand показывает запущенные задачи сотрудника
+func statusCommand(flags *flag.FlagSet, out *output) func(args []string) error {
+	peopleId := flags.Int("people", 0, "идентификатор сотрудника, по умолчанию текущий пользователь")
+
+	return func(args []string) error {
+		_, api, err := authorizedClient()
+		if err != nil {
+			return err
+		}
+		running, err := runningTasks(context.Background(), api, *peopleId)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		entries := make([]runningEntry, 0, len(running))
+		for _, task := range running {
+			entry, _ := task.TimeEntry(nil, nil, now)
+			entries = append(entries, runningEntry{TaskId: task.Id, Name: task.Name, Project: task.Project,
+				Start: entry.Start, Minutes: entry.Minutes})
+		}
+		return out.print(entries, func(w io.Writer) {
+			if len(entries) == 0 {
+				fmt.Fprintln(w, "Нет запущенных задач")
+				return
+			}
+			table(w, func(w io.Writer) {
+				fmt.Fprintln(w, "ID\tЗАДАЧА\tНАЧАЛО\tИДЕТ")
+				for _, entry := range entries {
+					fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", entry.TaskId, entry.Name, formatTime(entry.Start, now), formatMinutes(entry.Minutes))
+				}
+			})
+		})
+	}
+}
+
+// reportTask затраты по задаче за период
+type reportTask struct {
+	TaskId  int     `json:"task_id"`
+	Name    string  `json:"name"`
+	Project *string `json:"project,omitempty"`
+	Running bool    `json:"running"`
+	Minutes int     `json:"minutes"`
+}
+
+// report затраты сотрудника за период [From, To)
+type report struct {
+	PeopleId     int          `json:"people_id"`
+	From         time.Time    `json:"from"`
+	To           time.Time    `json:"to"`
+	TotalMinutes int          `json:"total_minutes"`
+	Tasks        []reportTask `json:"tasks"`
+}
+
+// reportCommand показывает затраты по задачам за период, от большей к меньшей
+func reportCommand(flags *flag.FlagSet, out *output) func(args []string) error {
+	today := flags.Bool("today", false, "за сегодня")
+	week := flags.Bool("week", false, "за текущую неделю (по умолчанию)")
+	month := flags.Bool("month", false, "за текущий месяц")
+	from := flags.String("from", "", "начало периода, ГГГГ-ММ-ДД")
+	to := flags.String("to", "", "конец периода включительно, ГГГГ-ММ-ДД")
+	peopleId := flags.Int("people", 0, "идентификатор сотрудника, по умолчанию текущий пользователь")
+
+	return func(args []string) error {
+		now := time.Now()
+		selected := 0
+		for _, set := range []bool{*today, *week, *month, *from != "" || *to != ""} {
+			if set {
+				selected++
+			}
+		}
+		if selected > 1 {
+			return errors.New("укажите только один период")
+		}
+		start, end, err := reportPeriod(now, *today, *month, *from, *to)
+		if err != nil {
+			return err
+		}
+		_, api, err := authorizedClient()
+		if err != nil {
+			return err
+		}
+		ctx := context.Background()
+		id, err := resolvePeople(ctx, api, *peopleId)
+		if err != nil {
+			return err
+		}
+		tasks, err := api.Tasks(ctx, id, false)
+		if err != nil {
+			return err
+		}
+
+		result := report{PeopleId: id, From: start, To: end, Tasks: []reportTask{}}
+		for _, task := range tasks {
+			entry, ok := task.TimeEntry(&start, &end, now)
+			if !ok || entry.Minutes == 0 {
+				continue
+			}
+			result.TotalMinutes += entry.Minutes
+			result.Tasks = append(result.Tasks, reportTask{TaskId: task.Id, Name: task.Name, Project: task.Project,
+				Running: entry.End == nil, Minutes: entry.Minutes})
+		}
+		sort.SliceStable(result.Tasks, func(i, j int) bool {
+			return result.Tasks[i].Minutes > result.Tasks[j].Minutes
+		})
+
+		return out.print(result, func(w io.Writer) {
+			fmt.Fprintf(w, "Период: %s — %s\n\n", start.Format("02.01.2006"), end.AddDate(0, 0, -1).Format("02.01.2006"))
+			if len(result.Tasks) == 0 {
+				fmt.Fprintln(w, "Нет затраченного времени")
+				return
+			}
+			table(w, func(w io.Writer) {
+				fmt.Fprintln(w, "ID\tЗАДАЧА\tПРОЕКТ\tВРЕМЯ\t")
+				for _, task := range result.Tasks {
+					project, mark := "", ""
+					if task.Project != nil {
+						project = *task.Project
+					}
+					if task.Running {
+						mark = "идет"
+					}
+					fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", task.TaskId, task.Name, project, formatMinutes(task.Minutes), mark)
+				}
+				fmt.Fprintf(w, "\tИтого\t\t%s\t\n", formatMinutes(result.TotalMinutes))
+			})
+		})
+	}
+}
+
+// reportPeriod границы периода отчета в часовом поясе пользователя, конец не включается
+func reportPeriod(now time.Time, today, month bool, from, to string) (time.Time, time.Time, error) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	switch {
+	case from != "" || to != "":
+		if from == "" || to == "" {
+			return time.Time{}, time.Time{}, errors.New("период задается парой -from и -to")
+		}
+		start, err := time.ParseInLocation(time.DateOnly, from, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("неверная дата -from: %w", err)
+		}
+		end, err := time.ParseInLocation(time.DateOnly, to, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("неверная дата -to: %w", err)
+		}
+		if end.Before(start) {
+			return time.Time{}, time.Time{}, errors.New("-to раньше -from")
+		}
+		return start, end.AddDate(0, 0, 1), nil
+	case today:
+		return day, day.AddDate(0, 0, 1), nil
+	case month:
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+		return start, start.AddDate(0, 1, 0), nil
+	default:
+		// Текущая неделя, начинается с понедельника
+		start := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return start, start.AddDate(0, 0, 7), nil
+	}
+}
+
+// authorizedClient клиент с сохраненными настройками. Ошибка, если вход не выполнен
+func authorizedClient() (config, *client.Client, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return cfg, nil, err
+	}
+	if err = cfg.authorized(); err != nil {
+		return cfg, nil, err
+	}
+	api, err := newClient(cfg)
+	return cfg, api, err
+}
+
+// resolvePeople идентификатор сотрудника: указанный явно или связанный с текущим пользователем
+func resolvePeople(ctx context.Context, api *client.Client, peopleId int) (int, error) {
+	if peopleId != 0 {
+		return peopleId, nil
+	}
+	me, err := api.Me(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if me.PeopleId == nil {
+		return 0, fmt.Errorf("%s не связан с сотрудником, укажите -people", me.Name)
+	}
+	return *me.PeopleId, nil
+}
+
+// runningTasks запущенные и не остановленные задачи сотрудника
+func runningTasks(ctx context.Context, api *client.Client, peopleId int) ([]model.Task, error) {
+	id, err := resolvePeople(ctx, api, peopleId)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := api.Tasks(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	var running []model.Task
+	for _, task := range tasks {
+		if task.TimeStart != nil && task.TimeEnd == nil {
+			running = append(running, task)
+		}
+	}
+	return running, nil
+}
+
+// taskIdArg разбирает идентификатор задачи из аргументов. 0, если он необязателен и не указан
+func taskIdArg(args []string, required bool) (int, error) {
+	switch {
+	case len(args) == 0 && !required:
+		return 0, nil
+	case len(args) != 1:
+		return 0, errors.New("укажите идентификатор задачи")
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("неверный идентификатор задачи %q", args[0])
+	}
+	return id, nil
+}
+
+// prompt запрашивает строку у пользователя
+func prompt(stdin *bufio.Reader, label string) (string, error) {
+	fmt.Fprint(os.Stderr, label)
+	line, err := stdin.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// readPassword читает пароль без отображения на экране или из стандартного ввода
+func readPassword(stdin *bufio.Reader, fromStdin bool) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if fromStdin || !term.IsTerminal(fd) {
+		line, err := stdin.ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+			return "", errors.New("пароль не передан")
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	fmt.Fprint(os.Stderr, "Пароль: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	return string(password), err
+}
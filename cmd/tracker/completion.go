@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// completionCommand печатает скрипт автодополнения для оболочки. Подключение:
+//
+//	bash: source <(tracker completion bash)
+//	zsh:  source <(tracker completion zsh)
+//	fish: tracker completion fish | source
+func completionCommand(flags *flag.FlagSet, out *output) func(args []string) error {
+	return func(args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("укажите оболочку: bash, zsh или fish")
+		}
+		switch args[0] {
+		case "bash":
+			writeBashCompletion(out.w)
+		case "zsh":
+			fmt.Fprintln(out.w, "autoload -U +X bashcompinit && bashcompinit")
+			writeBashCompletion(out.w)
+		case "fish":
+			writeFishCompletion(out.w)
+		default:
+			return fmt.Errorf("неизвестная оболочка %s", args[0])
+		}
+		return nil
+	}
+}
+
+// commandFlags флаги, которые объявляет команда, включая общий -json
+func commandFlags(name string) []*flag.Flag {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Bool("json", false, "вывод в формате JSON")
+	commands[name].setup(flags, &output{})
+	var result []*flag.Flag
+	flags.VisitAll(func(f *flag.Flag) {
+		result = append(result, f)
+	})
+	return result
+}
+
+func writeBashCompletion(w io.Writer) {
+	names := commandNames()
+	fmt.Fprintln(w, "_tracker() {")
+	fmt.Fprintln(w, `	local cur="${COMP_WORDS[COMP_CWORD]}"`)
+	fmt.Fprintln(w, "	if [ \"$COMP_CWORD\" -eq 1 ]; then")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " "))
+	fmt.Fprintln(w, "\t\treturn")
+	fmt.Fprintln(w, "\tfi")
+	fmt.Fprintln(w, `	case "${COMP_WORDS[1]}" in`)
+	for _, name := range names {
+		var words []string
+		for _, f := range commandFlags(name) {
+			words = append(words, "-"+f.Name)
+		}
+		if name == "completion" {
+			words = append(words, "bash", "zsh", "fish")
+		}
+		fmt.Fprintf(w, "\t%s) COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", name, strings.Join(words, " "))
+	}
+	fmt.Fprintln(w, "\tesac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -F _tracker tracker")
+}
+
+func writeFishCompletion(w io.Writer) {
+	names := commandNames()
+	fmt.Fprintln(w, "complete -c tracker -f")
+	for _, name := range names {
+		fmt.Fprintf(w, "complete -c tracker -n '__fish_use_subcommand' -a %s -d %s\n", name, fishQuote(commands[name].summary))
+	}
+	for _, name := range names {
+		for _, f := range commandFlags(name) {
+			fmt.Fprintf(w, "complete -c tracker -n '__fish_seen_subcommand_from %s' -o %s -d %s\n", name, f.Name, fishQuote(f.Usage))
+		}
+	}
+	fmt.Fprintln(w, "complete -c tracker -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'")
+}
+
+// fishQuote строка в одинарных кавычках fish
+func fishQuote(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
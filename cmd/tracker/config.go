@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultURL адрес сервера, если он не задан при входе
+const defaultURL = "http://localhost:8080"
+
+// config настройки клиента, сохраняемые между запусками
+type config struct {
+	URL       string     `json:"url"`
+	Token     string     `json:"token,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	APIKey    string     `json:"api_key,omitempty"`
+}
+
+// configPath путь к файлу настроек: TRACKER_CONFIG или tracker/config.json
+// в каталоге настроек пользователя (~/.config на Linux)
+func configPath() (string, error) {
+	if path := os.Getenv("TRACKER_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tracker", "config.json"), nil
+}
+
+// loadConfig читает настройки из файла. Переменные TRACKER_URL, TRACKER_TOKEN и TRACKER_API_KEY
+// переопределяют сохраненные значения и не записываются в файл
+func loadConfig() (config, error) {
+	cfg, err := readConfig()
+	if err != nil {
+		return config{}, err
+	}
+	if value := os.Getenv("TRACKER_URL"); value != "" {
+		cfg.URL = value
+	}
+	if value := os.Getenv("TRACKER_TOKEN"); value != "" {
+		cfg.Token, cfg.ExpiresAt = value, nil
+	}
+	if value := os.Getenv("TRACKER_API_KEY"); value != "" {
+		cfg.APIKey = value
+	}
+	return cfg, nil
+}
+
+// readConfig читает файл настроек без учета переменных окружения
+func readConfig() (config, error) {
+	cfg := config{URL: defaultURL}
+	path, err := configPath()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("файл настроек %s поврежден: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// saveConfig записывает настройки. Файл содержит токен, поэтому доступен только владельцу
+func saveConfig(cfg config) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// authorized проверяет, что есть действующий токен или ключ доступа
+func (c config) authorized() error {
+	if c.APIKey != "" {
+		return nil
+	}
+	if c.Token == "" {
+		return errors.New("вход не выполнен, выполните tracker login")
+	}
+	if c.ExpiresAt != nil && time.Now().After(*c.ExpiresAt) {
+		return errors.New("срок действия токена истек, выполните tracker login")
+	}
+	return nil
+}
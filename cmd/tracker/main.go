@@ -0,0 +1,101 @@
+// Консольный клиент тайм-трекера. Работает с REST API сервера:
+//
+//	tracker login -url http://localhost:8080 -login ivanov
+//	tracker start 42
+//	tracker status
+//	tracker stop
+//	tracker report --week
+//
+// Адрес сервера и токен хранятся в каталоге настроек пользователя, см. configPath
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command команда клиента. setup объявляет флаги команды и возвращает функцию,
+// которая выполняет команду с позиционными аргументами после разбора флагов
+type command struct {
+	summary string
+	usage   string
+	setup   func(flags *flag.FlagSet, out *output) func(args []string) error
+}
+
+// commands команды клиента: tracker <команда> [флаги] [аргументы]
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"login":      {"войти и сохранить токен", "[-url адрес] [-login логин] [-password-stdin] [-api-key ключ]", loginCommand},
+		"logout":     {"удалить сохраненный токен", "", logoutCommand},
+		"whoami":     {"показать текущего пользователя", "", whoamiCommand},
+		"start":      {"начать отсчет времени по задаче", "<id задачи>", startCommand},
+		"stop":       {"остановить отсчет времени", "[id задачи]", stopCommand},
+		"status":     {"показать запущенные задачи", "", statusCommand},
+		"report":     {"показать затраченное время за период", "[--today | --week | --month | -from дата -to дата]", reportCommand},
+		"completion": {"вывести скрипт автодополнения", "bash|zsh|fish", completionCommand},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run выполняет команду и возвращает код завершения процесса
+func run(args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage()
+		return 0
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "неизвестная команда %s\n\n", args[0])
+		usage()
+		return 2
+	}
+
+	flags := flag.NewFlagSet("tracker "+args[0], flag.ContinueOnError)
+	out := &output{w: os.Stdout}
+	flags.BoolVar(&out.json, "json", false, "вывод в формате JSON")
+	exec := cmd.setup(flags, out)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "%s\n\nИспользование: tracker %s [-json] %s\n", cmd.summary, args[0], cmd.usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if err := exec(flags.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "ошибка:", describe(err))
+		return 1
+	}
+	return 0
+}
+
+// usage печатает список команд
+func usage() {
+	fmt.Fprintln(os.Stderr, "Использование: tracker <команда> [флаги] [аргументы]")
+	fmt.Fprintln(os.Stderr, "\nКоманды:")
+	for _, name := range commandNames() {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nСправка по команде: tracker <команда> -h")
+}
+
+// commandNames имена команд по алфавиту
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// output вывод результата команды: JSON для скриптов или текст для человека
+type output struct {
+	w    io.Writer
+	json bool
+}
+
+// print выводит value в формате JSON или вызывает human для текстового вывода
+func (o *output) print(value any, human func(w io.Writer)) error {
+	if o.json {
+		encoder := json.NewEncoder(o.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+	human(o.w)
+	return nil
+}
+
+// table вывод с выравниванием колонок
+func table(w io.Writer, write func(w io.Writer)) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	write(tw)
+	_ = tw.Flush()
+}
+
+// formatMinutes длительность в виде "3ч 05м"
+func formatMinutes(minutes int) string {
+	return fmt.Sprintf("%dч %02dм", minutes/60, minutes%60)
+}
+
+// formatTime время в часовом поясе пользователя. Сегодняшнее время печатается без даты
+func formatTime(t time.Time, now time.Time) string {
+	t = t.Local()
+	if y, m, d := t.Date(); y == now.Year() && m == now.Month() && d == now.Day() {
+		return t.Format("15:04")
+	}
+	return t.Format("02.01.2006 15:04")
+}
@@ -0,0 +1,219 @@
+package database
+
+import (
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Действия, записываемые в журнал изменений
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditAssign  = "assign"
+	AuditStart   = "start"
+	AuditStop    = "stop"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+	AuditSubmit  = "submit"
+	AuditApprove = "approve"
+	AuditReject  = "reject"
+	AuditReopen  = "reopen"
+)
+
+// auditHidden поля, которые не попадают в журнал: паспорт хранится зашифрованным,
+// а хеши паролей и ключей не нужны для разбора изменений
+var auditHidden = map[string]bool{
+	"passport_serie":       true,
+	"passport_number":      true,
+	"passport_serie_hash":  true,
+	"passport_number_hash": true,
+	"password_hash":        true,
+	"key_hash":             true,
+	"secret":               true,
+}
+
+// auditFilterFields поля журнала, по которым допускается точная фильтрация
+var auditFilterFields = map[string]bool{
+	"actor_kind": true,
+	"actor_id":   true,
+	"action":     true,
+	"entity":     true,
+	"entity_id":  true,
+	"request_id": true,
+	"client_ip":  true,
+}
+
+// AuditFilter условия выборки журнала изменений. Пустые поля не ограничивают выборку
+type AuditFilter struct {
+	// Fields точные значения полей из auditFilterFields
+	Fields map[string]string
+	From   *time.Time
+	To     *time.Time
+}
+
+// auditChange значение поля до и после изменения
+type auditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// auditRow читает строку table для журнала и блокирует ее до конца транзакции.
+// Возвращает ErrNotFound, если строки нет
+func auditRow(ctx context.Context, tx *sqlx.Tx, table string, id int) (map[string]any, error) {
+	row := make(map[string]any)
+	err := tx.QueryRowxContext(ctx, `SELECT * FROM `+table+` WHERE id = $1 FOR UPDATE`, id).MapScan(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при чтении записи для журнала изменений", zap.Error(err), zap.String("entity", table), zap.Int("id", id))
+		return nil, err
+	}
+	for column, value := range row {
+		if auditHidden[column] {
+			delete(row, column)
+		} else if b, ok := value.([]byte); ok {
+			row[column] = string(b)
+		}
+	}
+	return row, nil
+}
+
+// auditDiff оставляет только изменившиеся поля. before равен nil при создании, after — если строка удалена
+func auditDiff(before, after map[string]any) map[string]auditChange {
+	diff := make(map[string]auditChange)
+	for column, value := range after {
+		if old, ok := before[column]; !ok || !reflect.DeepEqual(old, value) {
+			diff[column] = auditChange{Before: old, After: value}
+		}
+	}
+	for column, value := range before {
+		if _, ok := after[column]; !ok {
+			diff[column] = auditChange{Before: value}
+		}
+	}
+	return diff
+}
+
+// auditTx выполняет change над строкой table в транзакции tx и записывает изменение в журнал,
+// а для сотрудников, задач и табелей — доменное событие в outbox (см. outboxTx). Для создания id равен 0, а change возвращает идентификатор новой строки
+func auditTx(ctx context.Context, tx *sqlx.Tx, actor model.AuditActor, action, table string, id int, change func() (int, error)) (int, error) {
+	var before map[string]any
+	var err error
+	if id != 0 {
+		if before, err = auditRow(ctx, tx, table, id); err != nil {
+			return 0, err
+		}
+	}
+
+	changedId, err := change()
+	if err != nil {
+		return 0, err
+	}
+	if id == 0 {
+		id = changedId
+	}
+
+	after, err := auditRow(ctx, tx, table, id)
+	if errors.Is(err, ErrNotFound) && action != AuditCreate {
+		after, err = nil, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	diff, err := json.Marshal(auditDiff(before, after))
+	if err != nil {
+		return 0, err
+	}
+	query := `INSERT INTO audit_log (actor_kind, actor_id, actor_name, action, entity, entity_id, diff, request_id, client_ip, override_reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''))`
+	_, err = tx.ExecContext(ctx, query, actor.Kind, actor.Id, actor.Name, action, table, id, diff, actor.RequestId, actor.ClientIp,
+		actor.OverrideReason)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при записи в журнал изменений", zap.Error(err), zap.String("entity", table), zap.Int("id", id))
+		return 0, err
+	}
+	if err = outboxTx(ctx, tx, action, table, id, before, after); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// audited выполняет auditTx в отдельной транзакции. Вызывается под блокировкой
+func (d *Database) audited(ctx context.Context, actor model.AuditActor, action, table string, id int, change func(tx *sqlx.Tx) (int, error)) (int, error) {
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при открытии транзакции", zap.Error(err))
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	changedId, err := auditTx(ctx, tx, actor, action, table, id, func() (int, error) {
+		return change(tx)
+	})
+	d.rollbackLocked(ctx, tx, actor, action, table, id, err)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при фиксации транзакции", zap.Error(err))
+		return 0, err
+	}
+	return changedId, nil
+}
+
+// GetAuditLog возвращает страницу журнала изменений, новые записи первыми
+func (d *Database) GetAuditLog(ctx context.Context, page, pageSize int, filter AuditFilter) ([]model.AuditEntry, error) {
+	ctx, done := observe(ctx, "GetAuditLog")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var conditions []string
+	var args []interface{}
+	for field, value := range filter.Fields {
+		if !auditFilterFields[field] {
+			return nil, fmt.Errorf("фильтрация журнала по полю %s не поддерживается", field)
+		}
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", field, len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	var query strings.Builder
+	query.WriteString("SELECT * FROM audit_log")
+	if len(conditions) > 0 {
+		query.WriteString(" WHERE " + strings.Join(conditions, " AND "))
+	}
+	query.WriteString(fmt.Sprintf(" ORDER BY id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2))
+	args = append(args, pageSize, (page-1)*pageSize)
+
+	var entries []model.AuditEntry
+	if err := d.db.SelectContext(ctx, &entries, query.String(), args...); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении журнала изменений", zap.Error(err))
+		return nil, err
+	}
+	logger.Ctx(ctx).Info("Получен журнал изменений", zap.Int("count", len(entries)))
+	return entries, nil
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// GetUserByLogin возвращает пользователя по логину или nil, если такого нет
+func (d *Database) GetUserByLogin(ctx context.Context, login string) (*model.User, error) {
+	ctx, done := observe(ctx, "GetUserByLogin")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var user model.User
+	err := d.db.GetContext(ctx, &user, `SELECT id, login, password_hash, role, people_id FROM users WHERE login = $1`, login)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении пользователя", zap.Error(err))
+		return nil, err
+	}
+	return &user, nil
+}
+
+// AddUser добавляет пользователя с уже захешированным паролем
+func (d *Database) AddUser(ctx context.Context, actor model.AuditActor, u model.User) (int, error) {
+	ctx, done := observe(ctx, "AddUser")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	query := `INSERT INTO users (login, password_hash, role, people_id) VALUES ($1, $2, $3, $4) RETURNING id`
+	id, err := d.audited(ctx, actor, AuditCreate, "users", 0, func(tx *sqlx.Tx) (int, error) {
+		var id int
+		err := tx.QueryRowContext(ctx, query, u.Login, u.PasswordHash, u.Role, u.PeopleId).Scan(&id)
+		return id, err
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при добавлении пользователя", zap.Error(err))
+		return 0, err
+	}
+	logger.Ctx(ctx).Info("Пользователь успешно добавлен", zap.Int("id", id), zap.String("login", u.Login))
+	return id, nil
+}
+
+// AddAPIKey сохраняет хеш нового ключа доступа с ролью интеграции
+func (d *Database) AddAPIKey(ctx context.Context, actor model.AuditActor, name, role, keyHash string) (int, error) {
+	ctx, done := observe(ctx, "AddAPIKey")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	id, err := d.audited(ctx, actor, AuditCreate, "api_key", 0, func(tx *sqlx.Tx) (int, error) {
+		var id int
+		err := tx.QueryRowContext(ctx, `INSERT INTO api_key (name, role, key_hash) VALUES ($1, $2, $3) RETURNING id`, name, role, keyHash).Scan(&id)
+		return id, err
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при добавлении ключа доступа", zap.Error(err))
+		return 0, err
+	}
+	logger.Ctx(ctx).Info("Ключ доступа успешно добавлен", zap.Int("id", id), zap.String("name", name))
+	return id, nil
+}
+
+// GetAPIKeyByHash возвращает действующий ключ доступа по хешу или nil, если ключ не найден или отозван
+func (d *Database) GetAPIKeyByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	ctx, done := observe(ctx, "GetAPIKeyByHash")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var key model.APIKey
+	query := `SELECT id, name, role, key_hash, created_at, revoked_at FROM api_key WHERE key_hash = $1 AND revoked_at IS NULL`
+	err := d.db.GetContext(ctx, &key, query, keyHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении ключа доступа", zap.Error(err))
+		return nil, err
+	}
+	return &key, nil
+}
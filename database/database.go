@@ -1,18 +1,39 @@
 package database
 
 import (
+	"GoTimeTracker/internal/encryption"
+	"GoTimeTracker/internal/metrics"
+	"GoTimeTracker/internal/tracing"
+	"GoTimeTracker/pkg/config"
 	"GoTimeTracker/pkg/logger"
+	"context"
+	"errors"
 	"fmt"
+	"github.com/XSAM/otelsql"
 	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.uber.org/zap"
+	"io/fs"
 	"os"
+	"strings"
 	"sync"
+	"time"
+)
+
+// defaultConnectTimeout сколько ждать базу данных при запуске, если DB_CONNECT_TIMEOUT не задан
+const defaultConnectTimeout = time.Minute
+
+// Задержка между попытками подключения удваивается от connectRetryMin до connectRetryMax
+const (
+	connectRetryMin = 500 * time.Millisecond
+	connectRetryMax = 10 * time.Second
 )
 
 type Database struct {
 	db    *sqlx.DB
+	keys  *encryption.Keyring
 	mutex sync.Mutex
 }
 
@@ -36,10 +57,15 @@ func GetInstance() (*Database, error) {
 func newDatabase() (*Database, error) {
 	logger.Info("Загрузка файла .env для конфигурации базы данных")
 	err := godotenv.Load()
-	if err != nil {
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		logger.Fatal("Ошибка загрузки файла .env", zap.Error(err))
 	}
 
+	keys, err := encryption.LoadKeyring()
+	if err != nil {
+		logger.Fatal("Ошибка загрузки ключей шифрования паспортов", zap.Error(err))
+	}
+
 	dbUser := os.Getenv("POSTGRES_USER")
 	dbPassword := os.Getenv("POSTGRES_PASSWORD")
 	dbHost := os.Getenv("POSTGRES_HOST")
@@ -48,22 +74,77 @@ func newDatabase() (*Database, error) {
 
 	connectionString := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", dbUser, dbPassword, dbHost, dbPort, dbName)
 
-	logger.Debug("Строка подключения к базе данных сформирована", zap.String("connectionString", connectionString))
+	logger.Debug("Строка подключения к базе данных сформирована",
+		zap.String("connectionString", strings.Replace(connectionString, ":"+dbPassword+"@", ":***@", 1)))
 
-	db, err := sqlx.Connect("postgres", connectionString)
+	sqlDB, err := otelsql.Open("postgres", connectionString,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}))
 	if err != nil {
 		logger.Fatal("Ошибка подключения к базе данных", zap.Error(err))
 	}
+	db := sqlx.NewDb(sqlDB, "postgres")
 
-	// Ping базы данных для проверки подключения
+	// База данных может подниматься дольше сервиса, поэтому подключение проверяется повторно
 	logger.Info("Проверка подключения к базе данных")
-	err = db.Ping()
-	if err != nil {
-		logger.Fatal("Ошибка проверки подключения к базе данных", zap.Error(err))
+	if err = waitForDB(db, config.Duration("DB_CONNECT_TIMEOUT", defaultConnectTimeout)); err != nil {
+		db.Close()
+		return nil, err
 	}
 	logger.Info("Подключение к базе данных PostgreSQL успешно")
+	metrics.RegisterDB(db.DB, dbName)
+
+	return &Database{db: db, keys: keys}, nil
+}
 
-	return &Database{db: db}, nil
+// waitForDB проверяет подключение к базе данных, повторяя попытки с экспоненциальной задержкой,
+// пока не истечет timeout
+func waitForDB(db *sqlx.DB, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	delay := connectRetryMin
+	for attempt := 1; ; attempt++ {
+		err := db.Ping()
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return fmt.Errorf("база данных недоступна после %d попыток: %w", attempt, err)
+		}
+		logger.Error("База данных недоступна, повтор подключения", zap.Error(err),
+			zap.Int("attempt", attempt), zap.Duration("delay", delay))
+		time.Sleep(delay)
+		delay = min(delay*2, connectRetryMax)
+	}
+}
+
+// observe начинает span метода Database и замер его длительности. Запросы метода
+// становятся дочерними span'ами:
+//
+//	ctx, done := observe(ctx, "GetAllPeople")
+//	defer done()
+func observe(ctx context.Context, method string) (context.Context, func()) {
+	finish := metrics.Query(method)
+	ctx, span := tracing.Start(ctx, "Database."+method)
+	return ctx, func() {
+		span.End()
+		finish()
+	}
+}
+
+// execOne выполняет изменение одной строки, ErrNotFound если ни одна строка не изменилась
+func execOne(ctx context.Context, tx *sqlx.Tx, query string, args ...interface{}) error {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
 func (d *Database) Close() error {
@@ -1,11 +1,16 @@
 CREATE TABLE people (
     id SERIAL PRIMARY KEY,
-    passport_serie INT NOT NULL,
-    passport_number INT NOT NULL,
+    passport_serie TEXT NOT NULL,
+    passport_number TEXT NOT NULL,
+    passport_serie_hash TEXT NOT NULL,
+    passport_number_hash TEXT NOT NULL,
     name VARCHAR(50),
     surname VARCHAR(50),
     patronymic VARCHAR(50),
-    address TEXT
+    address TEXT,
+    manager_id INT REFERENCES people (id),
+    deleted_at TIMESTAMP,
+    CONSTRAINT people_passport_key UNIQUE (passport_serie_hash, passport_number_hash)
 );
 
 CREATE TABLE task (
@@ -13,7 +18,165 @@ CREATE TABLE task (
     people_id INT,
     name VARCHAR(100) NOT NULL,
     description TEXT,
+    project VARCHAR(100),
+    estimate_minutes INT CHECK (estimate_minutes > 0),
     time_start TIMESTAMP,
     time_end TIMESTAMP,
+    deleted_at TIMESTAMP,
     CONSTRAINT task_fk0 FOREIGN KEY (people_id) REFERENCES people (id)
-);
\ No newline at end of file
+);
+
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    login VARCHAR(50) NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'employee' CHECK (role IN ('admin', 'manager', 'employee')),
+    people_id INT REFERENCES people (id)
+);
+
+CREATE TABLE api_key (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('admin', 'manager', 'employee')),
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT LOCALTIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    actor_kind VARCHAR(20) NOT NULL,
+    actor_id INT NOT NULL,
+    actor_name VARCHAR(100) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    entity VARCHAR(20) NOT NULL,
+    entity_id INT NOT NULL,
+    diff JSONB NOT NULL,
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    client_ip VARCHAR(45) NOT NULL DEFAULT '',
+    -- override_reason причина изменения в закрытом периоде, указанная администратором
+    override_reason TEXT
+);
+
+CREATE INDEX audit_log_entity_idx ON audit_log (entity, entity_id);
+CREATE INDEX audit_log_created_at_idx ON audit_log (created_at);
+
+-- Журнал изменений только дополняется: изменение и удаление записей запрещены
+CREATE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+
+-- Подписки на события для внешних интеграций. Секрет хранится зашифрованным,
+-- пустой event_types означает все события
+CREATE TABLE webhook (
+    id SERIAL PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    failure_count INT NOT NULL DEFAULT 0,
+    disabled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Доставки событий подписчикам: одна строка на событие и подписку, попытки повторяются до status = 'delivered'
+-- или исчерпания попыток
+CREATE TABLE webhook_delivery (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id INT NOT NULL REFERENCES webhook (id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_status_code INT,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX webhook_delivery_pending_idx ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_delivery_webhook_idx ON webhook_delivery (webhook_id, id);
+CREATE INDEX webhook_delivery_event_idx ON webhook_delivery (event_id);
+
+-- Доменные события (transactional outbox): пишутся в одной транзакции с изменением сотрудника, задачи или табеля
+-- и доставляются получателям по возрастанию id внутри одной сущности (aggregate, aggregate_id).
+-- dispatched_at заполняется, когда событие доставлено всем получателям
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate VARCHAR(20) NOT NULL,
+    aggregate_id INT NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    people_id INT,
+    project VARCHAR(100),
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    dispatched_at TIMESTAMPTZ
+);
+
+CREATE INDEX outbox_pending_idx ON outbox (aggregate, aggregate_id, id) WHERE dispatched_at IS NULL;
+CREATE INDEX outbox_dispatched_idx ON outbox (dispatched_at) WHERE dispatched_at IS NOT NULL;
+
+-- Табели сотрудников за неделю или месяц. Утвержденный табель блокирует изменение времени задач
+-- сотрудника в периоде [period_start, period_end). Сотрудника с табелями нельзя удалить окончательно,
+-- пока табели не удалены: утвержденные табели не удаляются вместе с ним
+CREATE TABLE timesheet (
+    id SERIAL PRIMARY KEY,
+    people_id INT NOT NULL REFERENCES people (id) ON DELETE RESTRICT,
+    period VARCHAR(10) NOT NULL CHECK (period IN ('week', 'month')),
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'submitted', 'approved', 'rejected')),
+    submitted_minutes INT,
+    comment TEXT,
+    submitted_at TIMESTAMPTZ,
+    decided_at TIMESTAMPTZ,
+    decided_by VARCHAR(100),
+    CONSTRAINT timesheet_period_key UNIQUE (people_id, period, period_start)
+);
+
+CREATE INDEX timesheet_approved_idx ON timesheet (people_id, period_start) WHERE status = 'approved';
+CREATE INDEX timesheet_status_idx ON timesheet (status, period_start);
+
+-- Закрытые периоды [period_start, period_end). Время задач в закрытом периоде нельзя изменить
+-- без указания администратором причины
+CREATE TABLE closed_period (
+    id SERIAL PRIMARY KEY,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    comment TEXT,
+    closed_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (period_start < period_end)
+);
+
+-- Попытки изменить время в закрытом периоде или утвержденном табеле: отклоненные (reason closed
+-- или timesheet) и выполненные администратором с указанием причины (overridden)
+CREATE TABLE locked_edit (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    actor_kind VARCHAR(20) NOT NULL,
+    actor_id INT NOT NULL,
+    actor_name VARCHAR(100) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    entity VARCHAR(20) NOT NULL,
+    entity_id INT NOT NULL,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('closed', 'timesheet')),
+    overridden BOOLEAN NOT NULL DEFAULT FALSE,
+    override_reason TEXT,
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    client_ip VARCHAR(45) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX locked_edit_created_at_idx ON locked_edit (created_at);
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrNotFound запись не найдена
+	ErrNotFound = errors.New("запись не найдена")
+	// ErrPeriodLocked изменение затрагивает время в утвержденном табеле
+	ErrPeriodLocked = errors.New("Время в утвержденном табеле, изменение запрещено до возврата табеля руководителем")
+	// ErrPeriodClosed изменение затрагивает время в закрытом периоде
+	ErrPeriodClosed = errors.New("Период закрыт, изменение времени запрещено")
+	// ErrTimesheetState действие недопустимо в текущем состоянии табеля
+	ErrTimesheetState = errors.New("Действие недопустимо в текущем состоянии табеля")
+	// ErrTimerRunning в периоде идет отсчет времени по задаче, и его нельзя заблокировать
+	ErrTimerRunning = errors.New("В периоде идет отсчет времени по задаче, остановите его")
+)
+
+// passportUniqueIndex уникальный индекс паспорта в таблице people
+const passportUniqueIndex = "people_passport_key"
+
+// DuplicatePassportError сотрудник с таким паспортом уже существует
+type DuplicatePassportError struct {
+	Passport model.Passport
+	PeopleId int
+}
+
+func (e *DuplicatePassportError) Error() string {
+	return fmt.Sprintf("Сотрудник с паспортом %s %s уже существует (id %d)", e.Passport.Serie, e.Passport.Number, e.PeopleId)
+}
+
+// isUniqueViolation проверяет, что ошибка вызвана нарушением уникального ограничения constraint
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
+
+// duplicatePassport находит сотрудника с уже занятым паспортом. Вызывается под блокировкой
+func (d *Database) duplicatePassport(ctx context.Context, passport model.Passport) error {
+	logger.Ctx(ctx).Info("Сотрудник с таким паспортом уже существует", zap.String("passportSerie", passport.Serie))
+
+	var id int
+	query := `SELECT id FROM people WHERE passport_serie_hash = $1 AND passport_number_hash = $2`
+	err := d.db.QueryRowContext(ctx, query, d.keys.BlindIndex("passport_serie", passport.Serie),
+		d.keys.BlindIndex("passport_number", passport.Number)).Scan(&id)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при поиске сотрудника по паспорту", zap.Error(err))
+		return err
+	}
+	return &DuplicatePassportError{Passport: passport, PeopleId: id}
+}
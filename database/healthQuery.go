@@ -0,0 +1,77 @@
+package database
+
+import (
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+)
+
+// schemaColumns таблицы и столбцы из db.sql, без которых сервис не работает.
+// При изменении схемы список обновляется вместе с db.sql
+var schemaColumns = map[string][]string{
+	"people": {"id", "passport_serie", "passport_number", "passport_serie_hash", "passport_number_hash",
+		"name", "surname", "patronymic", "address", "manager_id", "deleted_at"},
+	"task": {"id", "people_id", "name", "description", "project", "estimate_minutes",
+		"time_start", "time_end", "deleted_at"},
+	"users":   {"id", "login", "password_hash", "role", "people_id"},
+	"api_key": {"id", "name", "role", "key_hash", "created_at", "revoked_at"},
+	"audit_log": {"id", "created_at", "actor_kind", "actor_id", "actor_name", "action",
+		"entity", "entity_id", "diff", "request_id", "client_ip", "override_reason"},
+	"webhook": {"id", "url", "secret", "event_types", "active", "failure_count", "disabled_at", "created_at"},
+	"webhook_delivery": {"id", "webhook_id", "event_id", "event_type", "payload", "status", "attempts",
+		"next_attempt_at", "last_status_code", "last_error", "created_at", "delivered_at"},
+	"outbox": {"id", "aggregate", "aggregate_id", "event_type", "people_id", "project", "payload", "created_at",
+		"attempts", "next_attempt_at", "last_error", "dispatched_at"},
+	"timesheet": {"id", "people_id", "period", "period_start", "period_end", "status", "submitted_minutes", "comment",
+		"submitted_at", "decided_at", "decided_by"},
+	"closed_period": {"id", "period_start", "period_end", "comment", "closed_by", "created_at"},
+	"locked_edit": {"id", "created_at", "actor_kind", "actor_id", "actor_name", "action", "entity", "entity_id",
+		"reason", "overridden", "override_reason", "request_id", "client_ip"},
+}
+
+// Ping проверяет подключение к базе данных. Не блокирует Database, чтобы проверка
+// готовности не ждала завершения долгих операций
+func (d *Database) Ping(ctx context.Context) error {
+	ctx, done := observe(ctx, "Ping")
+	defer done()
+
+	return d.db.PingContext(ctx)
+}
+
+// CheckSchema сверяет схему базы данных с db.sql и возвращает ошибку со списком
+// отсутствующих столбцов, если схема устарела
+func (d *Database) CheckSchema(ctx context.Context) error {
+	ctx, done := observe(ctx, "CheckSchema")
+	defer done()
+
+	var columns []struct {
+		Table  string `db:"table_name"`
+		Column string `db:"column_name"`
+	}
+	query := `SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = current_schema()`
+	if err := d.db.SelectContext(ctx, &columns, query); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при чтении схемы базы данных", zap.Error(err))
+		return err
+	}
+
+	present := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		present[column.Table+"."+column.Column] = true
+	}
+	var missing []string
+	for table, names := range schemaColumns {
+		for _, name := range names {
+			if !present[table+"."+name] {
+				missing = append(missing, table+"."+name)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("схема базы данных не соответствует db.sql, отсутствуют: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
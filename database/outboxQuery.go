@@ -0,0 +1,172 @@
+package database
+
+import (
+	"GoTimeTracker/internal/events"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"encoding/json"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"time"
+)
+
+// domainEvents тип доменного события по сущности и действию журнала изменений. Изменения
+// остальных сущностей и окончательное удаление событий не порождают
+var domainEvents = map[string]map[string]string{
+	"people": {
+		AuditCreate:  events.PeopleCreated,
+		AuditUpdate:  events.PeopleUpdated,
+		AuditDelete:  events.PeopleDeleted,
+		AuditRestore: events.PeopleRestored,
+	},
+	"task": {
+		AuditCreate:  events.TaskCreated,
+		AuditUpdate:  events.TaskUpdated,
+		AuditAssign:  events.TaskAssigned,
+		AuditStart:   events.TimerStarted,
+		AuditStop:    events.TimerStopped,
+		AuditDelete:  events.TaskDeleted,
+		AuditRestore: events.TaskRestored,
+	},
+	"timesheet": {
+		AuditSubmit:  events.TimesheetSubmitted,
+		AuditApprove: events.TimesheetApproved,
+		AuditReject:  events.TimesheetRejected,
+		AuditReopen:  events.TimesheetReopened,
+	},
+}
+
+// outboxTx записывает доменное событие об изменении строки table в транзакции изменения.
+// before и after — строка до и после изменения без скрытых полей (см. auditRow)
+func outboxTx(ctx context.Context, tx *sqlx.Tx, action, table string, id int, before, after map[string]any) error {
+	eventType := domainEvents[table][action]
+	if eventType == "" {
+		return nil
+	}
+	row := after
+	if row == nil {
+		row = before
+	}
+	payload, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+
+	var peopleId *int
+	var project *string
+	if table == "people" {
+		peopleId = &id
+	} else {
+		if value, ok := row["people_id"].(int64); ok {
+			people := int(value)
+			peopleId = &people
+		}
+		if value, ok := row["project"].(string); ok {
+			project = &value
+		}
+	}
+
+	query := `INSERT INTO outbox (aggregate, aggregate_id, event_type, people_id, project, payload)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	if _, err = tx.ExecContext(ctx, query, table, id, eventType, peopleId, project, payload); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при записи доменного события", zap.Error(err), zap.String("entity", table), zap.Int("id", id))
+		return err
+	}
+	return nil
+}
+
+// ClaimOutbox выбирает до limit недоставленных событий, время попытки которых наступило, и
+// откладывает их следующую попытку на lease. Из каждой сущности выбирается только самое раннее
+// недоставленное событие, поэтому следующее событие сущности не доставляется раньше предыдущего,
+// в том числе другим экземпляром сервиса
+func (d *Database) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEvent, error) {
+	ctx, done := observe(ctx, "ClaimOutbox")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	query := `WITH due AS (
+			SELECT o.id FROM outbox o
+			WHERE o.dispatched_at IS NULL AND o.next_attempt_at <= NOW()
+				AND NOT EXISTS (SELECT 1 FROM outbox p WHERE p.aggregate = o.aggregate
+					AND p.aggregate_id = o.aggregate_id AND p.dispatched_at IS NULL AND p.id < o.id)
+			ORDER BY o.id LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE outbox o SET next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM due WHERE o.id = due.id
+		RETURNING o.*`
+	var claimed []model.OutboxEvent
+	if err := d.db.SelectContext(ctx, &claimed, query, limit, lease.Seconds()); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при выборе доменных событий", zap.Error(err))
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// MarkOutboxDispatched отмечает событие доставленным всем получателям
+func (d *Database) MarkOutboxDispatched(ctx context.Context, id int64) error {
+	ctx, done := observe(ctx, "MarkOutboxDispatched")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	query := `UPDATE outbox SET attempts = attempts + 1, last_error = NULL, dispatched_at = NOW() WHERE id = $1`
+	if _, err := d.db.ExecContext(ctx, query, id); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при отметке доставки доменного события", zap.Error(err), zap.Int64("id", id))
+		return err
+	}
+	return nil
+}
+
+// RecordOutboxFailure сохраняет ошибку доставки события и время следующей попытки
+func (d *Database) RecordOutboxFailure(ctx context.Context, id int64, message string, next time.Time) error {
+	ctx, done := observe(ctx, "RecordOutboxFailure")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	query := `UPDATE outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE id = $1`
+	if _, err := d.db.ExecContext(ctx, query, id, message, next); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при сохранении ошибки доставки доменного события", zap.Error(err), zap.Int64("id", id))
+		return err
+	}
+	return nil
+}
+
+// CountOutboxPending количество недоставленных доменных событий
+func (d *Database) CountOutboxPending(ctx context.Context) (int, error) {
+	ctx, done := observe(ctx, "CountOutboxPending")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var count int
+	if err := d.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM outbox WHERE dispatched_at IS NULL`); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при подсчете недоставленных доменных событий", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// PurgeOutbox удаляет события, доставленные раньше before, и возвращает их количество
+func (d *Database) PurgeOutbox(ctx context.Context, before time.Time) (int, error) {
+	ctx, done := observe(ctx, "PurgeOutbox")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	result, err := d.db.ExecContext(ctx, `DELETE FROM outbox WHERE dispatched_at < $1`, before)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при очистке доставленных доменных событий", zap.Error(err))
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	return int(count), err
+}
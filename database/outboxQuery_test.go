@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+// claimIds id событий, выбранных ClaimOutbox, по возрастанию
+func claimIds(t *testing.T, d *Database, lease time.Duration) []int64 {
+	t.Helper()
+	events, err := d.ClaimOutbox(context.Background(), 10, lease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, event := range events {
+		ids = append(ids, event.Id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestClaimOutboxOrdersByAggregate(t *testing.T) {
+	d := testDatabase(t)
+	ctx := context.Background()
+
+	// События 1-3 задачи 1, 4 задачи 2, 5 сотрудника 1
+	events := []struct {
+		aggregate   string
+		aggregateId int
+	}{{"task", 1}, {"task", 1}, {"task", 1}, {"task", 2}, {"people", 1}}
+	for _, event := range events {
+		_, err := d.db.ExecContext(ctx, `INSERT INTO outbox (aggregate, aggregate_id, event_type, payload) VALUES ($1, $2, 'test', '{}')`,
+			event.aggregate, event.aggregateId)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if ids := claimIds(t, d, time.Minute); !slices.Equal(ids, []int64{1, 4, 5}) {
+		t.Fatalf("выбраны %v, ожидались первые события каждой сущности [1 4 5]", ids)
+	}
+	// Пока событие 1 выбрано и не доставлено, следующее событие задачи не выбирается
+	if ids := claimIds(t, d, time.Minute); len(ids) != 0 {
+		t.Fatalf("выбраны %v во время аренды предыдущих событий", ids)
+	}
+
+	// Неудачная доставка не пропускает событие: оно выбирается снова раньше следующих
+	if err := d.RecordOutboxFailure(ctx, 1, "нет связи", time.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if ids := claimIds(t, d, time.Minute); !slices.Equal(ids, []int64{1}) {
+		t.Fatalf("выбраны %v, ожидалось повторно событие 1", ids)
+	}
+
+	// После доставки события выбирается следующее событие той же задачи
+	for _, id := range []int64{1, 2} {
+		if err := d.MarkOutboxDispatched(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+		if ids := claimIds(t, d, time.Minute); !slices.Equal(ids, []int64{id + 1}) {
+			t.Fatalf("выбраны %v, ожидалось событие %d после доставки %d", ids, id+1, id)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"GoTimeTracker/internal/encryption"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"strings"
+)
+
+// sealedPassport зашифрованный паспорт и его слепые индексы в том виде, в котором они хранятся в people
+type sealedPassport struct {
+	Serie      string
+	Number     string
+	SerieHash  string
+	NumberHash string
+}
+
+// sealPassport шифрует серию и номер паспорта и вычисляет слепые индексы
+func (d *Database) sealPassport(passport model.Passport) (sealedPassport, error) {
+	serie, err := d.keys.Encrypt(passport.Serie)
+	if err != nil {
+		logger.Error("Ошибка при шифровании серии паспорта", zap.Error(err))
+		return sealedPassport{}, err
+	}
+	number, err := d.keys.Encrypt(passport.Number)
+	if err != nil {
+		logger.Error("Ошибка при шифровании номера паспорта", zap.Error(err))
+		return sealedPassport{}, err
+	}
+	return sealedPassport{
+		Serie:      serie,
+		Number:     number,
+		SerieHash:  d.keys.BlindIndex("passport_serie", passport.Serie),
+		NumberHash: d.keys.BlindIndex("passport_number", passport.Number),
+	}, nil
+}
+
+// openPassport расшифровывает паспорт прочитанного из базы сотрудника
+func (d *Database) openPassport(p *model.People) error {
+	serie, err := d.keys.Decrypt(p.PassportSerie)
+	if err != nil {
+		logger.Error("Ошибка при расшифровке серии паспорта", zap.Error(err), zap.Int("id", p.Id))
+		return err
+	}
+	number, err := d.keys.Decrypt(p.PassportNumber)
+	if err != nil {
+		logger.Error("Ошибка при расшифровке номера паспорта", zap.Error(err), zap.Int("id", p.Id))
+		return err
+	}
+	p.PassportSerie, p.PassportNumber = serie, number
+	return nil
+}
+
+// encryptPassportsSchema приводит таблицу people к хранению зашифрованного паспорта.
+// Выражение идемпотентно, поэтому миграцию можно запускать повторно, в том числе для ротации ключей
+const encryptPassportsSchema = `ALTER TABLE people
+		DROP CONSTRAINT IF EXISTS people_passport_key,
+		DROP CONSTRAINT IF EXISTS people_passport_serie_check,
+		DROP CONSTRAINT IF EXISTS people_passport_number_check,
+		ALTER COLUMN passport_serie TYPE TEXT,
+		ALTER COLUMN passport_number TYPE TEXT,
+		ADD COLUMN IF NOT EXISTS passport_serie_hash TEXT,
+		ADD COLUMN IF NOT EXISTS passport_number_hash TEXT`
+
+// encryptPassportsConstraints восстанавливает ограничения после заполнения слепых индексов
+const encryptPassportsConstraints = `ALTER TABLE people
+		ALTER COLUMN passport_serie_hash SET NOT NULL,
+		ALTER COLUMN passport_number_hash SET NOT NULL,
+		ADD CONSTRAINT people_passport_key UNIQUE (passport_serie_hash, passport_number_hash)`
+
+// EncryptPassports шифрует паспорта, хранящиеся открытым текстом, перешифровывает значения,
+// зашифрованные не текущим ключом, и пересчитывает слепые индексы. Выполняется в одной транзакции.
+// Возвращает количество обновленных сотрудников
+func (d *Database) EncryptPassports(ctx context.Context) (int, error) {
+	ctx, done := observe(ctx, "EncryptPassports")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при открытии транзакции", zap.Error(err))
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, encryptPassportsSchema); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при изменении схемы people", zap.Error(err))
+		return 0, err
+	}
+
+	type stored struct {
+		Id         int     `db:"id"`
+		Serie      string  `db:"passport_serie"`
+		Number     string  `db:"passport_number"`
+		SerieHash  *string `db:"passport_serie_hash"`
+		NumberHash *string `db:"passport_number_hash"`
+	}
+	var rows []stored
+	err = tx.SelectContext(ctx, &rows, `SELECT id, passport_serie, passport_number, passport_serie_hash, passport_number_hash FROM people ORDER BY id FOR UPDATE`)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при чтении паспортов", zap.Error(err))
+		return 0, err
+	}
+
+	updated := 0
+	for _, row := range rows {
+		passport := model.Passport{}
+		if passport.Serie, err = d.plainPassportField(row.Serie, 4); err != nil {
+			return 0, fmt.Errorf("сотрудник %d: %w", row.Id, err)
+		}
+		if passport.Number, err = d.plainPassportField(row.Number, 6); err != nil {
+			return 0, fmt.Errorf("сотрудник %d: %w", row.Id, err)
+		}
+
+		serieHash := d.keys.BlindIndex("passport_serie", passport.Serie)
+		numberHash := d.keys.BlindIndex("passport_number", passport.Number)
+		if !d.keys.NeedsRotation(row.Serie) && !d.keys.NeedsRotation(row.Number) &&
+			row.SerieHash != nil && *row.SerieHash == serieHash && row.NumberHash != nil && *row.NumberHash == numberHash {
+			continue
+		}
+
+		sealed, err := d.sealPassport(passport)
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE people SET passport_serie = $2, passport_number = $3, passport_serie_hash = $4, passport_number_hash = $5 WHERE id = $1`,
+			row.Id, sealed.Serie, sealed.Number, sealed.SerieHash, sealed.NumberHash)
+		if err != nil {
+			logger.Ctx(ctx).Error("Ошибка при обновлении паспорта", zap.Error(err), zap.Int("id", row.Id))
+			return 0, err
+		}
+		updated++
+	}
+
+	if _, err = tx.ExecContext(ctx, encryptPassportsConstraints); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при восстановлении ограничений people", zap.Error(err))
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при фиксации транзакции", zap.Error(err))
+		return 0, err
+	}
+	logger.Ctx(ctx).Info("Паспорта сотрудников зашифрованы", zap.Int("total", len(rows)), zap.Int("updated", updated))
+	return updated, nil
+}
+
+// plainPassportField возвращает открытое значение поля паспорта. Значения, хранившиеся в INT,
+// дополняются ведущими нулями до length цифр
+func (d *Database) plainPassportField(value string, length int) (string, error) {
+	if encryption.IsEncrypted(value) {
+		return d.keys.Decrypt(value)
+	}
+	value = strings.TrimSpace(value)
+	if len(value) < length {
+		value = strings.Repeat("0", length-len(value)) + value
+	}
+	return value, nil
+}
@@ -3,97 +3,455 @@ package database
 import (
 	"GoTimeTracker/internal/model"
 	"GoTimeTracker/pkg/logger"
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// GetAllPeople возвращает список сотрудников из базы данных с фильтрами и пагинацией
-func (d *Database) GetAllPeople(page int, pageSize int, filterParam, filterValue string) ([]model.People, error) {
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-	offset := (page - 1) * pageSize
+// peopleColumns поля сотрудника, возвращаемые запросами. Слепые индексы паспорта наружу не отдаются
+const peopleColumns = `id, passport_serie, passport_number, name, surname, patronymic, address, manager_id, deleted_at`
 
-	var query strings.Builder
-	query.WriteString("SELECT * FROM people")
+const addPeopleQuery = `INSERT INTO people (passport_serie, passport_number, passport_serie_hash, passport_number_hash, name, surname, patronymic, address, manager_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
 
+// peopleFilterFields поля, по которым допускается фильтрация сотрудников
+var peopleFilterFields = map[string]bool{
+	"id":              true,
+	"passport_serie":  true,
+	"passport_number": true,
+	"name":            true,
+	"surname":         true,
+	"patronymic":      true,
+	"address":         true,
+}
+
+// PeopleFilter условия выборки сотрудников
+type PeopleFilter struct {
+	// Param и Value фильтр по одному полю, Param пустой — без фильтра
+	Param string
+	Value string
+	// Ids если не nil, выбираются только сотрудники с этими идентификаторами
+	Ids []int
+	// IncludeDeleted включает в выборку удаленных сотрудников
+	IncludeDeleted bool
+}
+
+// peopleQuery формирует запрос списка сотрудников с фильтром. Идентификатор сравнивается точно,
+// паспорт точно по слепому индексу, остальные поля по вхождению
+func (d *Database) peopleQuery(filter PeopleFilter) (*strings.Builder, []interface{}, error) {
+	var conditions []string
 	var args []interface{}
-	argCounter := 1
 
-	if filterParam != "" {
-		if filterParam == "passport_serie" || filterParam == "passport_number" {
-			value, err := strconv.Atoi(filterValue)
+	if filter.Param != "" {
+		if !peopleFilterFields[filter.Param] {
+			return nil, nil, fmt.Errorf("фильтрация по полю %s не поддерживается", filter.Param)
+		}
+
+		switch filter.Param {
+		case "id":
+			value, err := strconv.Atoi(filter.Value)
 			if err != nil {
-				logger.Error("Ошибка при парсинге значения фильтрации", zap.Error(err))
-				return nil, err
+				return nil, nil, err
 			}
-			query.WriteString(fmt.Sprintf(" WHERE %s LIKE $%d", filterParam, argCounter))
 			args = append(args, value)
-			argCounter++
-		} else {
-			query.WriteString(fmt.Sprintf(" WHERE %s LIKE $%d", filterParam, argCounter))
-			args = append(args, "%"+filterValue+"%")
-			argCounter++
+			conditions = append(conditions, fmt.Sprintf("id = $%d", len(args)))
+		case "passport_serie", "passport_number":
+			args = append(args, d.keys.BlindIndex(filter.Param, filter.Value))
+			conditions = append(conditions, fmt.Sprintf("%s_hash = $%d", filter.Param, len(args)))
+		default:
+			args = append(args, "%"+filter.Value+"%")
+			conditions = append(conditions, fmt.Sprintf("%s LIKE $%d", filter.Param, len(args)))
 		}
 	}
 
-	query.WriteString(fmt.Sprintf(" ORDER BY id LIMIT $%d OFFSET $%d", argCounter, argCounter+1))
+	if filter.Ids != nil {
+		args = append(args, pq.Array(filter.Ids))
+		conditions = append(conditions, fmt.Sprintf("id = ANY($%d)", len(args)))
+	}
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
+	var query strings.Builder
+	query.WriteString("SELECT " + peopleColumns + " FROM people")
+	if len(conditions) > 0 {
+		query.WriteString(" WHERE " + strings.Join(conditions, " AND "))
+	}
+	return &query, args, nil
+}
+
+// GetAllPeople возвращает список сотрудников из базы данных с фильтрами и пагинацией
+func (d *Database) GetAllPeople(ctx context.Context, page int, pageSize int, filter PeopleFilter) ([]model.People, error) {
+	ctx, done := observe(ctx, "GetAllPeople")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	offset := (page - 1) * pageSize
+
+	query, args, err := d.peopleQuery(filter)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при разборе фильтра", zap.Error(err))
+		return nil, err
+	}
+
+	query.WriteString(fmt.Sprintf(" ORDER BY id LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2))
 	args = append(args, pageSize, offset)
 
 	var peoples []model.People
-	err := d.db.Select(&peoples, query.String(), args...)
+	err = d.db.SelectContext(ctx, &peoples, query.String(), args...)
 	if err != nil {
-		logger.Error("Ошибка при получении списка сотрудников", zap.Error(err))
+		logger.Ctx(ctx).Error("Ошибка при получении списка сотрудников", zap.Error(err))
 		return nil, err
 	}
-	logger.Info("Получен список сотрудников", zap.Int("count", len(peoples)))
+	for i := range peoples {
+		if err = d.openPassport(&peoples[i]); err != nil {
+			return nil, err
+		}
+	}
+	logger.Ctx(ctx).Info("Получен список сотрудников", zap.Int("count", len(peoples)))
 	return peoples, nil
 }
 
-// AddPeople добавление сотрудника в формате
-func (d *Database) AddPeople(p model.People) error {
+// EachPeople передает в fn всех сотрудников, подходящих под фильтр, по одному,
+// не загружая весь список в память
+func (d *Database) EachPeople(ctx context.Context, filter PeopleFilter, fn func(model.People) error) error {
+	ctx, done := observe(ctx, "EachPeople")
+	defer done()
+
+	query, args, err := d.peopleQuery(filter)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при разборе фильтра", zap.Error(err))
+		return err
+	}
+	query.WriteString(" ORDER BY id")
+
+	d.mutex.Lock()
+	rows, err := d.db.QueryxContext(ctx, query.String(), args...)
+	d.mutex.Unlock()
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при выгрузке списка сотрудников", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var p model.People
+		if err = rows.StructScan(&p); err != nil {
+			logger.Ctx(ctx).Error("Ошибка при чтении сотрудника", zap.Error(err))
+			return err
+		}
+		if err = d.openPassport(&p); err != nil {
+			return err
+		}
+		if err = fn(p); err != nil {
+			return err
+		}
+		count++
+	}
+	if err = rows.Err(); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при выгрузке списка сотрудников", zap.Error(err))
+		return err
+	}
+	logger.Ctx(ctx).Info("Выгружен список сотрудников", zap.Int("count", count))
+	return nil
+}
+
+// AddPeople добавление сотрудника, возвращает идентификатор новой записи.
+// Если сотрудник с таким паспортом уже есть, возвращается *DuplicatePassportError
+func (d *Database) AddPeople(ctx context.Context, actor model.AuditActor, p model.People) (int, error) {
+	ctx, done := observe(ctx, "AddPeople")
+	defer done()
+
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	query := `INSERT INTO people (passport_serie, passport_number, name, surname, patronymic, address) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	id, err := d.audited(ctx, actor, AuditCreate, "people", 0, func(tx *sqlx.Tx) (int, error) {
+		return d.insertPeople(ctx, tx, p)
+	})
+	if err != nil {
+		if isUniqueViolation(err, passportUniqueIndex) {
+			return 0, d.duplicatePassport(ctx, p.Passport())
+		}
+		logger.Ctx(ctx).Error("Ошибка при добавлении сотрудника", zap.Error(err))
+		return 0, err
+	}
+	logger.Ctx(ctx).Info("Сотрудник успешно добавлен", zap.Int("id", id))
+	return id, nil
+}
+
+// AddPeopleBatch добавляет сотрудников в одной транзакции: либо все, либо ни одного
+func (d *Database) AddPeopleBatch(ctx context.Context, actor model.AuditActor, people []model.People) ([]int, error) {
+	ctx, done := observe(ctx, "AddPeopleBatch")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при открытии транзакции", zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids := make([]int, len(people))
+	for i, p := range people {
+		ids[i], err = auditTx(ctx, tx, actor, AuditCreate, "people", 0, func() (int, error) {
+			return d.insertPeople(ctx, tx, p)
+		})
+		if err != nil {
+			logger.Ctx(ctx).Error("Ошибка при добавлении сотрудника", zap.Error(err), zap.Int("index", i))
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при фиксации транзакции", zap.Error(err))
+		return nil, err
+	}
+	logger.Ctx(ctx).Info("Сотрудники успешно добавлены", zap.Int("count", len(ids)))
+	return ids, nil
+}
+
+// insertPeople шифрует паспорт и добавляет сотрудника в транзакции tx. ManagerId берется как есть,
+// поэтому вызывающая сторона заполняет его только из данных аутентифицированного запроса
+func (d *Database) insertPeople(ctx context.Context, tx *sqlx.Tx, p model.People) (int, error) {
+	sealed, err := d.sealPassport(p.Passport())
+	if err != nil {
+		return 0, err
+	}
+
 	var id int
-	err := d.db.QueryRow(query, p.PassportSerie, p.PassportNumber, "", "", "", "").Scan(&id)
+	err = tx.QueryRowContext(ctx, addPeopleQuery, sealed.Serie, sealed.Number, sealed.SerieHash, sealed.NumberHash,
+		p.Name, p.Surname, p.Patronymic, p.Address, p.ManagerId).Scan(&id)
+	return id, err
+}
+
+// FindPeopleByPassports возвращает идентификаторы уже существующих сотрудников по паспортам
+func (d *Database) FindPeopleByPassports(ctx context.Context, passports []model.Passport) (map[model.Passport]int, error) {
+	ctx, done := observe(ctx, "FindPeopleByPassports")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	series := make([]string, len(passports))
+	numbers := make([]string, len(passports))
+	byHash := make(map[[2]string]model.Passport, len(passports))
+	for i, passport := range passports {
+		series[i] = d.keys.BlindIndex("passport_serie", passport.Serie)
+		numbers[i] = d.keys.BlindIndex("passport_number", passport.Number)
+		byHash[[2]string{series[i], numbers[i]}] = passport
+	}
+
+	query := `SELECT id, passport_serie_hash, passport_number_hash FROM people
+		WHERE (passport_serie_hash, passport_number_hash) IN (SELECT * FROM UNNEST($1::TEXT[], $2::TEXT[]))`
+	rows, err := d.db.QueryContext(ctx, query, pq.Array(series), pq.Array(numbers))
 	if err != nil {
-		logger.Error("Ошибка при добавлении сотрудника", zap.Error(err))
+		logger.Ctx(ctx).Error("Ошибка при поиске сотрудников по паспортам", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := make(map[model.Passport]int)
+	for rows.Next() {
+		var id int
+		var serieHash, numberHash string
+		if err = rows.Scan(&id, &serieHash, &numberHash); err != nil {
+			logger.Ctx(ctx).Error("Ошибка при поиске сотрудников по паспортам", zap.Error(err))
+			return nil, err
+		}
+		existing[byHash[[2]string{serieHash, numberHash}]] = id
+	}
+	if err = rows.Err(); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при поиске сотрудников по паспортам", zap.Error(err))
+		return nil, err
+	}
+	logger.Ctx(ctx).Debug("Найдены существующие сотрудники по паспортам", zap.Int("count", len(existing)))
+	return existing, nil
+}
+
+// GetPeople возвращает сотрудника по идентификатору, ErrNotFound если его нет или он удален
+func (d *Database) GetPeople(ctx context.Context, id int) (model.People, error) {
+	ctx, done := observe(ctx, "GetPeople")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var p model.People
+	err := d.db.GetContext(ctx, &p, `SELECT `+peopleColumns+` FROM people WHERE id = $1 AND deleted_at IS NULL`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.People{}, ErrNotFound
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении сотрудника", zap.Error(err), zap.Int("id", id))
+		return model.People{}, err
+	}
+	if err = d.openPassport(&p); err != nil {
+		return model.People{}, err
+	}
+	return p, nil
+}
+
+// CountPeople количество не удаленных сотрудников
+func (d *Database) CountPeople(ctx context.Context) (int, error) {
+	ctx, done := observe(ctx, "CountPeople")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var count int
+	if err := d.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM people WHERE deleted_at IS NULL`); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при подсчете сотрудников", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetTeamIds возвращает идентификаторы руководителя и его не удаленных подчиненных
+func (d *Database) GetTeamIds(ctx context.Context, managerId int) ([]int, error) {
+	ctx, done := observe(ctx, "GetTeamIds")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var ids []int
+	err := d.db.SelectContext(ctx, &ids, `SELECT id FROM people WHERE id = $1 OR (manager_id = $1 AND deleted_at IS NULL) ORDER BY id`, managerId)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении команды сотрудника", zap.Error(err), zap.Int("managerId", managerId))
+		return nil, err
+	}
+	logger.Ctx(ctx).Debug("Получена команда сотрудника", zap.Int("managerId", managerId), zap.Int("count", len(ids)))
+	return ids, nil
+}
+
+// UpdatePeople обновление информации о сотруднике
+func (d *Database) UpdatePeople(ctx context.Context, actor model.AuditActor, p model.People) error {
+	ctx, done := observe(ctx, "UpdatePeople")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	query := `UPDATE people SET name = $2, surname = $3, patronymic = $4, address = $5, manager_id = $6 WHERE id = $1`
+	_, err := d.audited(ctx, actor, AuditUpdate, "people", p.Id, func(tx *sqlx.Tx) (int, error) {
+		_, err := tx.ExecContext(ctx, query, p.Id, p.Name, p.Surname, p.Patronymic, p.Address, p.ManagerId)
+		return p.Id, err
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при обновлении информации о сотруднике", zap.Error(err), zap.Int("id", p.Id))
 		return err
 	}
-	p.Id = id
-	logger.Info("Сотрудник успешно добавлен", zap.Int("id", p.Id))
+	logger.Ctx(ctx).Info("Информация о сотруднике успешно обновлена", zap.Int("id", p.Id))
 	return nil
 }
 
-// UpdatePeople обновление информации о сотруднике
-func (d *Database) UpdatePeople(p model.People) error {
+// Политики для задач удаляемого сотрудника
+const (
+	// TasksKeep задачи остаются за удаленным сотрудником
+	TasksKeep = "keep"
+	// TasksReassign не начатые задачи передаются другому сотруднику
+	TasksReassign = "reassign"
+	// TasksCascade задачи удаляются вместе с сотрудником и восстанавливаются вместе с ним
+	TasksCascade = "cascade"
+)
+
+// DeletePeople помечает сотрудника удаленным. Запущенные таймеры сотрудника останавливаются,
+// время остается за ним, а задачи обрабатываются согласно policy: для TasksReassign
+// не начатые задачи передаются сотруднику reassignTo
+func (d *Database) DeletePeople(ctx context.Context, actor model.AuditActor, id int, policy string, reassignTo int) error {
+	ctx, done := observe(ctx, "DeletePeople")
+	defer done()
+
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	query := `UPDATE people SET name = $2, surname = $3, patronymic = $4, address = $5 WHERE id = $1`
-	_, err := d.db.Exec(query, p.Id, p.Name, p.Surname, p.Patronymic, p.Address)
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при открытии транзакции", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = auditTx(ctx, tx, actor, AuditDelete, "people", id, func() (int, error) {
+		return id, execOne(ctx, tx, `UPDATE people SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`, id, now)
+	})
+	if err == nil {
+		_, err = stopTimersTx(ctx, tx, actor, id, now)
+	}
+	if err == nil {
+		switch policy {
+		case TasksReassign:
+			_, err = reassignTasksTx(ctx, tx, actor, id, []int{reassignTo})
+		case TasksCascade:
+			err = deleteTasksTx(ctx, tx, actor, id, now)
+		}
+	}
 	if err != nil {
-		logger.Error("Ошибка при обновлении информации о сотруднике", zap.Error(err), zap.Int("id", p.Id))
+		d.rollbackLocked(ctx, tx, actor, AuditDelete, "people", id, err)
+		logger.Ctx(ctx).Error("Ошибка при удалении информации о сотруднике", zap.Error(err), zap.Int("id", id))
 		return err
 	}
-	logger.Info("Информация о сотруднике успешно обновлена", zap.Int("id", p.Id))
+
+	if err = tx.Commit(); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при фиксации транзакции", zap.Error(err))
+		return err
+	}
+	logger.Ctx(ctx).Info("Информация о сотруднике успешно удалена", zap.Int("id", id), zap.String("tasks", policy))
 	return nil
 }
 
-// DeletePeople удаление информации о сотруднике
-func (d *Database) DeletePeople(id int) error {
+// RestorePeople восстанавливает удаленного сотрудника вместе с задачами, удаленными вместе с ним
+func (d *Database) RestorePeople(ctx context.Context, actor model.AuditActor, id int) error {
+	ctx, done := observe(ctx, "RestorePeople")
+	defer done()
+
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	query := `DELETE FROM people WHERE id = $1`
-	_, err := d.db.Exec(query, id)
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при открытии транзакции", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	var deletedAt time.Time
+	err = tx.GetContext(ctx, &deletedAt, `SELECT deleted_at FROM people WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err == nil {
+		_, err = auditTx(ctx, tx, actor, AuditRestore, "people", id, func() (int, error) {
+			return id, execOne(ctx, tx, `UPDATE people SET deleted_at = NULL WHERE id = $1`, id)
+		})
+	}
+	if err == nil {
+		err = restoreTasksTx(ctx, tx, actor, id, deletedAt)
+	}
 	if err != nil {
-		logger.Error("Ошибка при удалении информации о сотруднике", zap.Error(err), zap.Int("id", id))
+		d.rollbackLocked(ctx, tx, actor, AuditRestore, "people", id, err)
+		logger.Ctx(ctx).Error("Ошибка при восстановлении сотрудника", zap.Error(err), zap.Int("id", id))
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при фиксации транзакции", zap.Error(err))
 		return err
 	}
-	logger.Info("Информация о сотруднике успешно удалена", zap.Int("id", id))
+	logger.Ctx(ctx).Info("Сотрудник успешно восстановлен", zap.Int("id", id))
 	return nil
 }
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"time"
+)
+
+// GetClosedPeriods возвращает закрытые периоды, последние первыми
+func (d *Database) GetClosedPeriods(ctx context.Context) ([]model.ClosedPeriod, error) {
+	ctx, done := observe(ctx, "GetClosedPeriods")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	periods := []model.ClosedPeriod{}
+	if err := d.db.SelectContext(ctx, &periods, `SELECT * FROM closed_period ORDER BY period_start DESC, id DESC`); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении закрытых периодов", zap.Error(err))
+		return nil, err
+	}
+	return periods, nil
+}
+
+// ClosePeriod закрывает период [start, end) и возвращает его. ErrTimerRunning, пока по задаче,
+// пересекающейся с периодом, идет отсчет времени: иначе ее нельзя было бы остановить
+func (d *Database) ClosePeriod(ctx context.Context, actor model.AuditActor, start, end time.Time, comment *string) (model.ClosedPeriod, error) {
+	ctx, done := observe(ctx, "ClosePeriod")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	period := model.ClosedPeriod{PeriodStart: start, PeriodEnd: end, Comment: comment, ClosedBy: actor.Name}
+	query := `INSERT INTO closed_period (period_start, period_end, comment, closed_by) VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+	_, err := d.audited(ctx, actor, AuditCreate, "closed_period", 0, func(tx *sqlx.Tx) (int, error) {
+		var running bool
+		err := tx.GetContext(ctx, &running, `SELECT EXISTS (SELECT 1 FROM task
+			WHERE time_start IS NOT NULL AND time_end IS NULL AND time_start < $2 AND LOCALTIMESTAMP > $1)`, start, end)
+		if err != nil {
+			return 0, err
+		}
+		if running {
+			logger.Ctx(ctx).Info("Закрытие периода с запущенной задачей отклонено", zap.Time("start", start), zap.Time("end", end))
+			return 0, ErrTimerRunning
+		}
+		err = tx.QueryRowContext(ctx, query, start, end, comment, actor.Name).Scan(&period.Id, &period.CreatedAt)
+		return period.Id, err
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при закрытии периода", zap.Error(err))
+		return model.ClosedPeriod{}, err
+	}
+	logger.Ctx(ctx).Info("Период закрыт", zap.Int("id", period.Id), zap.Time("start", start), zap.Time("end", end))
+	return period, nil
+}
+
+// ReopenPeriod удаляет закрытый период id, снимая блокировку времени в нем
+func (d *Database) ReopenPeriod(ctx context.Context, actor model.AuditActor, id int) error {
+	ctx, done := observe(ctx, "ReopenPeriod")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	_, err := d.audited(ctx, actor, AuditDelete, "closed_period", id, func(tx *sqlx.Tx) (int, error) {
+		return id, execOne(ctx, tx, `DELETE FROM closed_period WHERE id = $1`, id)
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при открытии периода", zap.Error(err), zap.Int("id", id))
+		return err
+	}
+	logger.Ctx(ctx).Info("Период открыт", zap.Int("id", id))
+	return nil
+}
+
+// GetLockedEdits возвращает страницу отчета о попытках изменить заблокированное время, новые
+// попытки первыми. Пустой reason и nil-границы не ограничивают выборку
+func (d *Database) GetLockedEdits(ctx context.Context, page, pageSize int, reason string, from, to *time.Time) ([]model.LockedEdit, error) {
+	ctx, done := observe(ctx, "GetLockedEdits")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	edits := []model.LockedEdit{}
+	query := `SELECT * FROM locked_edit
+		WHERE ($1 = '' OR reason = $1) AND ($2::TIMESTAMPTZ IS NULL OR created_at >= $2) AND ($3::TIMESTAMPTZ IS NULL OR created_at < $3)
+		ORDER BY id DESC LIMIT $4 OFFSET $5`
+	if err := d.db.SelectContext(ctx, &edits, query, reason, from, to, pageSize, (page-1)*pageSize); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении попыток изменения закрытого времени", zap.Error(err))
+		return nil, err
+	}
+	return edits, nil
+}
+
+// lockReason причина блокировки для отчета по ошибке lockedTx
+func lockReason(err error) string {
+	if errors.Is(err, ErrPeriodLocked) {
+		return model.LockTimesheet
+	}
+	return model.LockClosed
+}
+
+// insertLockedEdit записывает попытку изменения в отчет
+func insertLockedEdit(ctx context.Context, db sqlx.ExecerContext, actor model.AuditActor, action, table string, id int, reason string, overridden bool) error {
+	query := `INSERT INTO locked_edit (actor_kind, actor_id, actor_name, action, entity, entity_id, reason, overridden,
+			override_reason, request_id, client_ip)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11)`
+	_, err := db.ExecContext(ctx, query, actor.Kind, actor.Id, actor.Name, action, table, id, reason, overridden,
+		actor.OverrideReason, actor.RequestId, actor.ClientIp)
+	return err
+}
+
+// recordLockedEdit записывает отклоненную попытку изменения вне откатываемой транзакции.
+// Ошибка записи только логируется, чтобы вызывающая сторона получила исходную ошибку блокировки.
+// Вызывается под блокировкой
+func (d *Database) recordLockedEdit(ctx context.Context, actor model.AuditActor, action, table string, id int, lockErr error) {
+	if err := insertLockedEdit(ctx, d.db, actor, action, table, id, lockReason(lockErr), false); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при записи попытки изменения закрытого времени", zap.Error(err),
+			zap.String("entity", table), zap.Int("id", id))
+	}
+}
+
+// rollbackLocked откатывает tx и записывает отклоненную попытку в отчет, если изменение отклонено
+// блокировкой времени. Вызывается под блокировкой
+func (d *Database) rollbackLocked(ctx context.Context, tx *sqlx.Tx, actor model.AuditActor, action, table string, id int, err error) {
+	if errors.Is(err, ErrPeriodClosed) || errors.Is(err, ErrPeriodLocked) {
+		// Попытка попадает в отчет, хотя само изменение откатывается
+		_ = tx.Rollback()
+		d.recordLockedEdit(ctx, actor, action, table, id, err)
+	}
+}
+
+// lockedTx проверяет, что действие action над задачей taskId не затрагивает заблокированное
+// время: утвержденный табель (см. approvedTx) или закрытый период. Закрытый период пропускается,
+// если администратор указал причину в actor.OverrideReason, и такое изменение попадает в отчет
+func lockedTx(ctx context.Context, tx *sqlx.Tx, actor model.AuditActor, action string, taskId int, peopleId *int, at *time.Time) error {
+	if err := approvedTx(ctx, tx, taskId, peopleId, at); err != nil {
+		return err
+	}
+
+	var closed bool
+	query := `SELECT EXISTS (
+		SELECT 1 FROM task t JOIN closed_period c ON
+			(t.time_start IS NOT NULL AND t.time_start < c.period_end AND COALESCE(t.time_end, LOCALTIMESTAMP) > c.period_start)
+			OR ($2::TIMESTAMP >= c.period_start AND $2::TIMESTAMP < c.period_end)
+		WHERE t.id = $1)`
+	if err := tx.GetContext(ctx, &closed, query, taskId, at); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при проверке закрытых периодов", zap.Error(err), zap.Int("taskId", taskId))
+		return err
+	}
+	switch {
+	case !closed:
+		return nil
+	case actor.OverrideReason == "":
+		logger.Ctx(ctx).Info("Изменение времени в закрытом периоде отклонено", zap.Int("taskId", taskId))
+		return ErrPeriodClosed
+	default:
+		logger.Ctx(ctx).Warn("Изменение времени в закрытом периоде по решению администратора", zap.Int("taskId", taskId),
+			zap.String("actor", actor.Name), zap.String("reason", actor.OverrideReason))
+		return insertLockedEdit(ctx, tx, actor, action, "task", taskId, model.LockClosed, true)
+	}
+}
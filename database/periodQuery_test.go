@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClosePeriodWithRunningTimer(t *testing.T) {
+	d := testDatabase(t)
+	id := insertPeople(t, d, nil)
+	// Период вокруг текущего момента при любом часовом поясе сессии
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -2)
+	end := start.AddDate(0, 0, 7)
+	running := start.Add(time.Hour)
+	task := insertOpenTask(t, d, id, &running)
+
+	if _, err := d.ClosePeriod(context.Background(), testActor, start, end, nil); !errors.Is(err, ErrTimerRunning) {
+		t.Fatalf("ClosePeriod: %v, ожидалось ErrTimerRunning", err)
+	}
+	// Задача, запущенная после периода, закрытию не мешает
+	if _, err := d.ClosePeriod(context.Background(), testActor, start.AddDate(0, -1, 0), start, nil); err != nil {
+		t.Fatalf("ClosePeriod до запуска задачи: %v", err)
+	}
+
+	if err := d.EndTaskTime(context.Background(), testActor, task); err != nil {
+		t.Fatalf("EndTaskTime: %v", err)
+	}
+	if _, err := d.ClosePeriod(context.Background(), testActor, start, end, nil); err != nil {
+		t.Fatalf("ClosePeriod после остановки задачи: %v", err)
+	}
+	// Остановленная до закрытия задача не мешает удалить сотрудника
+	if err := d.DeletePeople(context.Background(), testActor, id, TasksKeep, 0); err != nil {
+		t.Fatalf("DeletePeople: %v", err)
+	}
+}
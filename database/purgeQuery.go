@@ -0,0 +1,117 @@
+package database
+
+import (
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"time"
+)
+
+// PurgeDeleted окончательно удаляет задачи и сотрудников, удаленных раньше before.
+// Ссылки на удаляемых сотрудников из оставшихся задач, подчиненных и пользователей обнуляются,
+// а их неутвержденные табели удаляются. Задачи со временем в утвержденном табеле или закрытом
+// периоде и сотрудники с утвержденными табелями остаются. Возвращает количество удаленных
+// сотрудников и задач
+func (d *Database) PurgeDeleted(ctx context.Context, actor model.AuditActor, before time.Time) (int, int, error) {
+	ctx, done := observe(ctx, "PurgeDeleted")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при открытии транзакции", zap.Error(err))
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	var taskIds, peopleIds []int
+	err = tx.SelectContext(ctx, &taskIds, `SELECT t.id FROM task t WHERE t.deleted_at < $1
+		AND NOT EXISTS (SELECT 1 FROM timesheet s WHERE s.status = 'approved' AND s.people_id = t.people_id
+			AND t.time_start IS NOT NULL AND t.time_start < s.period_end AND COALESCE(t.time_end, LOCALTIMESTAMP) > s.period_start)
+		AND NOT EXISTS (SELECT 1 FROM closed_period c WHERE t.time_start IS NOT NULL
+			AND t.time_start < c.period_end AND COALESCE(t.time_end, LOCALTIMESTAMP) > c.period_start)
+		ORDER BY t.id FOR UPDATE OF t`, before)
+	if err == nil {
+		err = tx.SelectContext(ctx, &peopleIds, `SELECT p.id FROM people p WHERE p.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM timesheet s WHERE s.people_id = p.id AND s.status = 'approved')
+			ORDER BY p.id FOR UPDATE OF p`, before)
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при поиске удаленных записей", zap.Error(err))
+		return 0, 0, err
+	}
+
+	for _, id := range taskIds {
+		if err = purgeTx(ctx, tx, actor, "task", id); err != nil {
+			return 0, 0, err
+		}
+	}
+	for _, id := range peopleIds {
+		for _, ref := range [][2]string{{"task", "people_id"}, {"people", "manager_id"}, {"users", "people_id"}} {
+			if err = detachTx(ctx, tx, actor, ref[0], ref[1], id); err != nil {
+				return 0, 0, err
+			}
+		}
+		if err = purgeTimesheetsTx(ctx, tx, actor, id); err != nil {
+			return 0, 0, err
+		}
+		if err = purgeTx(ctx, tx, actor, "people", id); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при фиксации транзакции", zap.Error(err))
+		return 0, 0, err
+	}
+	logger.Ctx(ctx).Info("Удаленные записи очищены", zap.Time("before", before),
+		zap.Int("people", len(peopleIds)), zap.Int("tasks", len(taskIds)))
+	return len(peopleIds), len(taskIds), nil
+}
+
+// purgeTx окончательно удаляет строку table
+func purgeTx(ctx context.Context, tx *sqlx.Tx, actor model.AuditActor, table string, id int) error {
+	_, err := auditTx(ctx, tx, actor, AuditPurge, table, id, func() (int, error) {
+		return id, execOne(ctx, tx, `DELETE FROM `+table+` WHERE id = $1`, id)
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при очистке удаленной записи", zap.Error(err), zap.String("entity", table), zap.Int("id", id))
+	}
+	return err
+}
+
+// detachTx обнуляет ссылку column на сотрудника peopleId в строках table
+func detachTx(ctx context.Context, tx *sqlx.Tx, actor model.AuditActor, table, column string, peopleId int) error {
+	var ids []int
+	err := tx.SelectContext(ctx, &ids, `SELECT id FROM `+table+` WHERE `+column+` = $1 ORDER BY id FOR UPDATE`, peopleId)
+	for i := 0; err == nil && i < len(ids); i++ {
+		id := ids[i]
+		_, err = auditTx(ctx, tx, actor, AuditUpdate, table, id, func() (int, error) {
+			return id, execOne(ctx, tx, `UPDATE `+table+` SET `+column+` = NULL WHERE id = $1`, id)
+		})
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при обнулении ссылки на сотрудника", zap.Error(err), zap.String("entity", table), zap.Int("peopleId", peopleId))
+	}
+	return err
+}
+
+// purgeTimesheetsTx окончательно удаляет табели сотрудника peopleId перед удалением самого сотрудника
+func purgeTimesheetsTx(ctx context.Context, tx *sqlx.Tx, actor model.AuditActor, peopleId int) error {
+	var ids []int
+	err := tx.SelectContext(ctx, &ids, `SELECT id FROM timesheet WHERE people_id = $1 ORDER BY id FOR UPDATE`, peopleId)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при поиске табелей сотрудника", zap.Error(err), zap.Int("peopleId", peopleId))
+		return err
+	}
+	for _, id := range ids {
+		if err = purgeTx(ctx, tx, actor, "timesheet", id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// insertPeople добавляет сотрудника, удаленного в момент deletedAt (nil — не удаленного)
+func insertPeople(t *testing.T, d *Database, deletedAt *time.Time) int {
+	t.Helper()
+	var id int
+	err := d.db.QueryRowContext(context.Background(), `INSERT INTO people
+		(passport_serie, passport_number, passport_serie_hash, passport_number_hash, deleted_at)
+		VALUES ('', '', md5(random()::TEXT), md5(random()::TEXT), $1) RETURNING id`, deletedAt).Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// insertTask добавляет задачу сотрудника со временем [start, end)
+func insertTask(t *testing.T, d *Database, peopleId int, start, end time.Time, deletedAt *time.Time) int {
+	t.Helper()
+	var id int
+	err := d.db.QueryRowContext(context.Background(), `INSERT INTO task (people_id, name, time_start, time_end, deleted_at)
+		VALUES ($1, 'Задача', $2, $3, $4) RETURNING id`, peopleId, start, end, deletedAt).Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// insertTimesheet добавляет недельный табель сотрудника с начала недели start в состоянии status
+func insertTimesheet(t *testing.T, d *Database, peopleId int, start time.Time, status string) {
+	t.Helper()
+	_, err := d.db.ExecContext(context.Background(), `INSERT INTO timesheet (people_id, period, period_start, period_end, status)
+		VALUES ($1, 'week', $2, $3, $4)`, peopleId, start, start.AddDate(0, 0, 7), status)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exists(t *testing.T, d *Database, table string, id int) bool {
+	t.Helper()
+	var found bool
+	if err := d.db.Get(&found, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1)`, id); err != nil {
+		t.Fatal(err)
+	}
+	return found
+}
+
+func TestPurgeDeletedKeepsApprovedTime(t *testing.T) {
+	d := testDatabase(t)
+	week := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	deleted := week.AddDate(0, 1, 0)
+
+	approved := insertPeople(t, d, &deleted)
+	insertTimesheet(t, d, approved, week, "approved")
+	approvedTask := insertTask(t, d, approved, week.Add(time.Hour), week.Add(2*time.Hour), &deleted)
+	otherTask := insertTask(t, d, approved, week.AddDate(0, 0, 8), week.AddDate(0, 0, 8).Add(time.Hour), &deleted)
+
+	draft := insertPeople(t, d, &deleted)
+	insertTimesheet(t, d, draft, week, "draft")
+
+	people, tasks, err := d.PurgeDeleted(context.Background(), testActor, deleted.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if people != 1 || tasks != 1 {
+		t.Fatalf("удалено сотрудников %d и задач %d, ожидалось 1 и 1", people, tasks)
+	}
+	if !exists(t, d, "people", approved) || !exists(t, d, "task", approvedTask) {
+		t.Fatal("удалены сотрудник или задача с утвержденным временем")
+	}
+	if exists(t, d, "task", otherTask) || exists(t, d, "people", draft) {
+		t.Fatal("не удалены задача вне утвержденного табеля или сотрудник с черновиком табеля")
+	}
+}
+
+func TestDeletePeopleCascadeChecksApprovedTime(t *testing.T) {
+	d := testDatabase(t)
+	ctx := context.Background()
+	week := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	id := insertPeople(t, d, nil)
+	insertTimesheet(t, d, id, week, "approved")
+	task := insertTask(t, d, id, week.Add(time.Hour), week.Add(2*time.Hour), nil)
+
+	if err := d.DeletePeople(ctx, testActor, id, TasksCascade, 0); !errors.Is(err, ErrPeriodLocked) {
+		t.Fatalf("DeletePeople: %v, ожидалось %v", err, ErrPeriodLocked)
+	}
+	var deleted bool
+	if err := d.db.Get(&deleted, `SELECT deleted_at IS NOT NULL FROM task WHERE id = $1`, task); err != nil || deleted {
+		t.Fatalf("задача с утвержденным временем удалена: %v", err)
+	}
+
+	// Без удаления задач сотрудника можно удалить и восстановить
+	if err := d.DeletePeople(ctx, testActor, id, TasksKeep, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.RestorePeople(ctx, testActor, id); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPurgeDeletedKeepsClosedPeriods(t *testing.T) {
+	d := testDatabase(t)
+	ctx := context.Background()
+	month := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	deleted := month.AddDate(0, 2, 0)
+	if _, err := d.ClosePeriod(ctx, testActor, month, month.AddDate(0, 1, 0), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	id := insertPeople(t, d, nil)
+	closed := insertTask(t, d, id, month.Add(time.Hour), month.Add(2*time.Hour), &deleted)
+	open := insertTask(t, d, id, month.AddDate(0, 1, 1), month.AddDate(0, 1, 1).Add(time.Hour), &deleted)
+
+	if _, tasks, err := d.PurgeDeleted(ctx, testActor, deleted.Add(time.Hour)); err != nil || tasks != 1 {
+		t.Fatalf("удалено задач %d (%v), ожидалась 1", tasks, err)
+	}
+	if !exists(t, d, "task", closed) || exists(t, d, "task", open) {
+		t.Fatal("удалена задача в закрытом периоде или осталась задача вне его")
+	}
+}
+
+func TestDeletePeopleCascadeChecksClosedPeriods(t *testing.T) {
+	d := testDatabase(t)
+	ctx := context.Background()
+	month := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := d.ClosePeriod(ctx, testActor, month, month.AddDate(0, 1, 0), nil); err != nil {
+		t.Fatal(err)
+	}
+	id := insertPeople(t, d, nil)
+	insertTask(t, d, id, month.Add(time.Hour), month.Add(2*time.Hour), nil)
+
+	if err := d.DeletePeople(ctx, testActor, id, TasksCascade, 0); !errors.Is(err, ErrPeriodClosed) {
+		t.Fatalf("DeletePeople: %v, ожидалось %v", err, ErrPeriodClosed)
+	}
+	override := testActor
+	override.OverrideReason = "Увольнение задним числом"
+	if err := d.DeletePeople(ctx, override, id, TasksCascade, 0); err != nil {
+		t.Fatalf("DeletePeople с причиной: %v", err)
+	}
+
+	edits, err := d.GetLockedEdits(ctx, 1, 10, "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edits) != 2 || !edits[0].Overridden || edits[0].Entity != "task" || edits[1].Overridden || edits[1].Entity != "people" {
+		t.Fatalf("отчет о попытках изменения: %+v", edits)
+	}
+}
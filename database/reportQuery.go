@@ -0,0 +1,52 @@
+package database
+
+import (
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"fmt"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// estimateGroups группировки отчета по оценкам: ключ и название группы
+var estimateGroups = map[string][2]string{
+	"task":    {"t.id::TEXT", "t.name"},
+	"people":  {"COALESCE(t.people_id::TEXT, '')", "CONCAT_WS(' ', p.surname, p.name, p.patronymic)"},
+	"project": {"COALESCE(t.project, '')", "COALESCE(t.project, '')"},
+}
+
+// GetEstimateReport сравнивает оценку и фактическое время завершенных задач с оценкой
+// в разрезе задач, сотрудников или проектов. Если peopleIds не nil, учитываются только задачи
+// этих сотрудников
+func (d *Database) GetEstimateReport(ctx context.Context, groupBy string, peopleIds []int) ([]model.EstimateReport, error) {
+	ctx, done := observe(ctx, "GetEstimateReport")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	group, ok := estimateGroups[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("неизвестная группировка отчета: %s", groupBy)
+	}
+
+	query := fmt.Sprintf(`SELECT %[1]s AS key, %[2]s AS name, COUNT(*) AS tasks,
+			SUM(t.estimate_minutes) AS estimate_minutes, SUM(t.actual_minutes) AS actual_minutes,
+			ROUND(SUM(t.actual_minutes)::NUMERIC / SUM(t.estimate_minutes), 2)::FLOAT8 AS accuracy
+		FROM (SELECT *, %[3]s AS actual_minutes FROM task
+			WHERE estimate_minutes IS NOT NULL AND time_start IS NOT NULL AND time_end IS NOT NULL AND deleted_at IS NULL
+				AND ($1::INT[] IS NULL OR people_id = ANY($1))) t
+		LEFT JOIN people p ON p.id = t.people_id
+		GROUP BY 1, 2
+		ORDER BY 1`, group[0], group[1], taskActualMinutes)
+
+	var report []model.EstimateReport
+	err := d.db.SelectContext(ctx, &report, query, pq.Array(peopleIds))
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при построении отчета по оценкам", zap.Error(err), zap.String("groupBy", groupBy))
+		return nil, err
+	}
+	logger.Ctx(ctx).Info("Построен отчет по оценкам", zap.String("groupBy", groupBy), zap.Int("count", len(report)))
+	return report, nil
+}
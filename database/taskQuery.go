@@ -3,85 +3,447 @@ package database
 import (
 	"GoTimeTracker/internal/model"
 	"GoTimeTracker/pkg/logger"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 	"time"
 )
 
+// taskActualMinutes выражение для фактически затраченного на задачу времени в минутах,
+// для незавершенной задачи время считается до текущего момента
+const taskActualMinutes = `COALESCE(EXTRACT(EPOCH FROM (COALESCE(time_end, LOCALTIMESTAMP) - time_start)), 0)::INT / 60`
+
+// tasksQuery задачи, отобранные условием where, с затраченным временем и сравнением с оценкой.
+// time_start, time_end и duration остаются NULL у не начатой или незавершенной задачи
+func tasksQuery(where string) string {
+	return `SELECT *, TO_CHAR(time_end - time_start, 'HH24:MI:SS') AS duration,
+		CASE WHEN estimate_minutes IS NULL THEN NULL ELSE GREATEST(estimate_minutes - actual_minutes, 0) END AS remaining_minutes,
+		COALESCE(actual_minutes > estimate_minutes, FALSE) AS over_estimate
+	FROM (SELECT id, COALESCE(people_id, 0) AS people_id, name, COALESCE(description, '') AS description, project,
+		estimate_minutes, time_start, time_end, deleted_at, ` + taskActualMinutes + ` AS actual_minutes
+		FROM task WHERE ` + where + `) t`
+}
+
+// peopleTasksQuery задачи сотрудника, самые долгие первыми
+var peopleTasksQuery = tasksQuery(`people_id = $1 AND ($2 OR deleted_at IS NULL)`) + ` ORDER BY duration DESC`
+
 // AddTask Добавить задачу
-func (d *Database) AddTask(name, description string) error {
+func (d *Database) AddTask(ctx context.Context, actor model.AuditActor, t model.Task) (int, error) {
+	ctx, done := observe(ctx, "AddTask")
+	defer done()
+
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	query := `INSERT INTO task (name, description) VALUES ($1, $2) RETURNING id`
-	var id int
-	err := d.db.QueryRow(query, name, description).Scan(&id)
+	query := `INSERT INTO task (name, description, project, estimate_minutes) VALUES ($1, $2, $3, $4) RETURNING id`
+	id, err := d.audited(ctx, actor, AuditCreate, "task", 0, func(tx *sqlx.Tx) (int, error) {
+		var id int
+		err := tx.QueryRowContext(ctx, query, t.Name, t.Description, t.Project, t.EstimateMinutes).Scan(&id)
+		return id, err
+	})
 	if err != nil {
-		logger.Error("Ошибка при добавлении задачи", zap.Error(err))
-		return err
+		logger.Ctx(ctx).Error("Ошибка при добавлении задачи", zap.Error(err))
+		return 0, err
 	}
-	logger.Info("Задача успешно добавлена", zap.Int("id", id))
-	return nil
+	logger.Ctx(ctx).Info("Задача успешно добавлена", zap.Int("id", id))
+	return id, nil
 }
 
 // AssignPeopleOnTask Назначить сотрудников на задачу
-func (d *Database) AssignPeopleOnTask(id, peopleId int) error {
+func (d *Database) AssignPeopleOnTask(ctx context.Context, actor model.AuditActor, id, peopleId int) error {
+	ctx, done := observe(ctx, "AssignPeopleOnTask")
+	defer done()
+
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
 	query := `UPDATE task SET people_id = $2 WHERE id = $1`
-	_, err := d.db.Exec(query, id, peopleId)
+	_, err := d.audited(ctx, actor, AuditAssign, "task", id, func(tx *sqlx.Tx) (int, error) {
+		if err := lockedTx(ctx, tx, actor, AuditAssign, id, &peopleId, nil); err != nil {
+			return id, err
+		}
+		_, err := tx.ExecContext(ctx, query, id, peopleId)
+		return id, err
+	})
 	if err != nil {
-		logger.Error("Ошибка при назначении сотрудников на задачу", zap.Error(err), zap.Int("taskId", id))
+		logger.Ctx(ctx).Error("Ошибка при назначении сотрудников на задачу", zap.Error(err), zap.Int("taskId", id))
 		return err
 	}
-	logger.Info("Сотрудники успешно назначены на задачу", zap.Int("taskId", id))
+	logger.Ctx(ctx).Info("Сотрудники успешно назначены на задачу", zap.Int("taskId", id))
+	return nil
+}
+
+// GetTaskOwner возвращает сотрудника, назначенного на задачу (nil, если задача не назначена),
+// или ErrNotFound, если задачи нет или она удалена
+func (d *Database) GetTaskOwner(ctx context.Context, id int) (*int, error) {
+	ctx, done := observe(ctx, "GetTaskOwner")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var peopleId *int
+	err := d.db.GetContext(ctx, &peopleId, `SELECT people_id FROM task WHERE id = $1 AND deleted_at IS NULL`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении исполнителя задачи", zap.Error(err), zap.Int("taskId", id))
+		return nil, err
+	}
+	return peopleId, nil
+}
+
+// SetTaskEstimate Установить оценку задачи в минутах, nil сбрасывает оценку.
+// ErrNotFound, если задачи нет или она удалена
+func (d *Database) SetTaskEstimate(ctx context.Context, actor model.AuditActor, id int, estimateMinutes *int) error {
+	ctx, done := observe(ctx, "SetTaskEstimate")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	query := `UPDATE task SET estimate_minutes = $2 WHERE id = $1 AND deleted_at IS NULL`
+	_, err := d.audited(ctx, actor, AuditUpdate, "task", id, func(tx *sqlx.Tx) (int, error) {
+		return id, execOne(ctx, tx, query, id, estimateMinutes)
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при обновлении оценки задачи", zap.Error(err), zap.Int("taskId", id))
+		return err
+	}
+	logger.Ctx(ctx).Info("Оценка задачи успешно обновлена", zap.Int("taskId", id))
 	return nil
 }
 
 // StartTaskTime Начать отслеживание времени задачи
-func (d *Database) StartTaskTime(id int) error {
+func (d *Database) StartTaskTime(ctx context.Context, actor model.AuditActor, id int) error {
+	ctx, done := observe(ctx, "StartTaskTime")
+	defer done()
+
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
 	query := `UPDATE task SET time_start = $2 WHERE id = $1`
-	_, err := d.db.Exec(query, id, time.Now())
+	_, err := d.audited(ctx, actor, AuditStart, "task", id, func(tx *sqlx.Tx) (int, error) {
+		now := time.Now()
+		if err := lockedTx(ctx, tx, actor, AuditStart, id, nil, &now); err != nil {
+			return id, err
+		}
+		_, err := tx.ExecContext(ctx, query, id, now)
+		return id, err
+	})
 	if err != nil {
-		logger.Error("Ошибка при обновлении времени начала задачи", zap.Error(err), zap.Int("taskId", id))
+		logger.Ctx(ctx).Error("Ошибка при обновлении времени начала задачи", zap.Error(err), zap.Int("taskId", id))
 		return err
 	}
-	logger.Info("Время начала задачи успешно обновлено", zap.Int("taskId", id))
+	logger.Ctx(ctx).Info("Время начала задачи успешно обновлено", zap.Int("taskId", id))
 	return nil
 }
 
 // EndTaskTime Завершить отслеживание времени задачи
-func (d *Database) EndTaskTime(id int) error {
+func (d *Database) EndTaskTime(ctx context.Context, actor model.AuditActor, id int) error {
+	ctx, done := observe(ctx, "EndTaskTime")
+	defer done()
+
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
 	query := `UPDATE task SET time_end = $2 WHERE id = $1`
-	_, err := d.db.Exec(query, id, time.Now())
+	_, err := d.audited(ctx, actor, AuditStop, "task", id, func(tx *sqlx.Tx) (int, error) {
+		now := time.Now()
+		if err := lockedTx(ctx, tx, actor, AuditStop, id, nil, &now); err != nil {
+			return id, err
+		}
+		_, err := tx.ExecContext(ctx, query, id, now)
+		return id, err
+	})
 	if err != nil {
-		logger.Error("Ошибка при обновлении времени завершения задачи", zap.Error(err), zap.Int("taskId", id))
+		logger.Ctx(ctx).Error("Ошибка при обновлении времени завершения задачи", zap.Error(err), zap.Int("taskId", id))
 		return err
 	}
-	logger.Info("Время завершения задачи успешно обновлено", zap.Int("taskId", id))
+	logger.Ctx(ctx).Info("Время завершения задачи успешно обновлено", zap.Int("taskId", id))
 	return nil
 }
 
-// GetPeopleTasks Получить задачи для конкретного сотрудника
-func (d *Database) GetPeopleTasks(peopleId int) ([]model.Task, error) {
+// GetPeopleTasks Получить задачи для конкретного сотрудника, includeDeleted добавляет удаленные задачи
+func (d *Database) GetPeopleTasks(ctx context.Context, peopleId int, includeDeleted bool) ([]model.Task, error) {
+	ctx, done := observe(ctx, "GetPeopleTasks")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var tasks []model.Task
+
+	err := d.db.SelectContext(ctx, &tasks, peopleTasksQuery, peopleId, includeDeleted)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении задач для сотрудника", zap.Error(err), zap.Int("peopleId", peopleId))
+		return nil, err
+	}
+	logger.Ctx(ctx).Info("Получен список задач для сотрудника", zap.Int("peopleId", peopleId), zap.Int("tasksCount", len(tasks)))
+	return tasks, nil
+}
+
+// GetTasksByPeople возвращает задачи нескольких сотрудников одним запросом
+func (d *Database) GetTasksByPeople(ctx context.Context, peopleIds []int, includeDeleted bool) ([]model.Task, error) {
+	ctx, done := observe(ctx, "GetTasksByPeople")
+	defer done()
+
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
 	var tasks []model.Task
+	query := tasksQuery(`people_id = ANY($1) AND ($2 OR deleted_at IS NULL)`) + ` ORDER BY people_id, duration DESC`
+	if err := d.db.SelectContext(ctx, &tasks, query, pq.Array(peopleIds), includeDeleted); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении задач сотрудников", zap.Error(err), zap.Ints("peopleIds", peopleIds))
+		return nil, err
+	}
+	logger.Ctx(ctx).Info("Получены задачи сотрудников", zap.Int("peopleCount", len(peopleIds)), zap.Int("tasksCount", len(tasks)))
+	return tasks, nil
+}
+
+// GetTasks возвращает неудаленные задачи по идентификаторам одним запросом
+func (d *Database) GetTasks(ctx context.Context, ids []int) ([]model.Task, error) {
+	ctx, done := observe(ctx, "GetTasks")
+	defer done()
 
-	query := "SELECT *, TO_CHAR(time_end - time_start, 'HH24:MI:SS') AS duration  FROM task WHERE people_id = $1 ORDER BY duration DESC "
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
 
-	err := d.db.Select(&tasks, query, peopleId)
+	var tasks []model.Task
+	query := tasksQuery(`id = ANY($1) AND deleted_at IS NULL`) + ` ORDER BY id`
+	if err := d.db.SelectContext(ctx, &tasks, query, pq.Array(ids)); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении задач", zap.Error(err), zap.Ints("ids", ids))
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// EachPeopleTask передает в fn задачи сотрудника по одной, не загружая весь список в память
+func (d *Database) EachPeopleTask(ctx context.Context, peopleId int, includeDeleted bool, fn func(model.Task) error) error {
+	ctx, done := observe(ctx, "EachPeopleTask")
+	defer done()
+
+	d.mutex.Lock()
+	rows, err := d.db.QueryxContext(ctx, peopleTasksQuery, peopleId, includeDeleted)
+	d.mutex.Unlock()
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при выгрузке задач сотрудника", zap.Error(err), zap.Int("peopleId", peopleId))
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var t model.Task
+		if err = rows.StructScan(&t); err != nil {
+			logger.Ctx(ctx).Error("Ошибка при чтении задачи", zap.Error(err))
+			return err
+		}
+		if err = fn(t); err != nil {
+			return err
+		}
+		count++
+	}
+	if err = rows.Err(); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при выгрузке задач сотрудника", zap.Error(err), zap.Int("peopleId", peopleId))
+		return err
+	}
+	logger.Ctx(ctx).Info("Выгружены задачи сотрудника", zap.Int("peopleId", peopleId), zap.Int("tasksCount", count))
+	return nil
+}
+
+// DeleteTask помечает задачу удаленной
+func (d *Database) DeleteTask(ctx context.Context, actor model.AuditActor, id int) error {
+	ctx, done := observe(ctx, "DeleteTask")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	_, err := d.audited(ctx, actor, AuditDelete, "task", id, func(tx *sqlx.Tx) (int, error) {
+		if err := lockedTx(ctx, tx, actor, AuditDelete, id, nil, nil); err != nil {
+			return id, err
+		}
+		return id, execOne(ctx, tx, `UPDATE task SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`, id, time.Now())
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при удалении задачи", zap.Error(err), zap.Int("taskId", id))
+		return err
+	}
+	logger.Ctx(ctx).Info("Задача успешно удалена", zap.Int("taskId", id))
+	return nil
+}
+
+// RestoreTask восстанавливает удаленную задачу
+func (d *Database) RestoreTask(ctx context.Context, actor model.AuditActor, id int) error {
+	ctx, done := observe(ctx, "RestoreTask")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	_, err := d.audited(ctx, actor, AuditRestore, "task", id, func(tx *sqlx.Tx) (int, error) {
+		if err := lockedTx(ctx, tx, actor, AuditRestore, id, nil, nil); err != nil {
+			return id, err
+		}
+		return id, execOne(ctx, tx, `UPDATE task SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при восстановлении задачи", zap.Error(err), zap.Int("taskId", id))
+		return err
+	}
+	logger.Ctx(ctx).Info("Задача успешно восстановлена", zap.Int("taskId", id))
+	return nil
+}
+
+// stopTimersTx останавливает запущенные таймеры сотрудника и возвращает их задачи. Время каждой
+// задачи проверяется lockedTx, как при остановке одной задачи
+func stopTimersTx(ctx context.Context, tx *sqlx.Tx, actor model.AuditActor, peopleId int, now time.Time) ([]int, error) {
+	var ids []int
+	err := tx.SelectContext(ctx, &ids, `SELECT id FROM task
+		WHERE people_id = $1 AND deleted_at IS NULL AND time_start IS NOT NULL AND time_end IS NULL
+		ORDER BY id FOR UPDATE`, peopleId)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		_, err = auditTx(ctx, tx, actor, AuditStop, "task", id, func() (int, error) {
+			if err := lockedTx(ctx, tx, actor, AuditStop, id, nil, &now); err != nil {
+				return id, err
+			}
+			return id, execOne(ctx, tx, `UPDATE task SET time_end = $2 WHERE id = $1`, id, now)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// reassignTasksTx передает не начатые задачи сотрудника по кругу сотрудникам recipients
+func reassignTasksTx(ctx context.Context, tx *sqlx.Tx, actor model.AuditActor, peopleId int, recipients []int) ([]model.Reassignment, error) {
+	var tasks []model.Reassignment
+	err := tx.SelectContext(ctx, &tasks, `SELECT id, name, people_id FROM task
+		WHERE people_id = $1 AND deleted_at IS NULL AND time_start IS NULL
+		ORDER BY id FOR UPDATE`, peopleId)
 	if err != nil {
-		logger.Error("Ошибка при получении задач для сотрудника", zap.Error(err), zap.Int("peopleId", peopleId))
 		return nil, err
 	}
-	logger.Info("Получен список задач для сотрудника", zap.Int("peopleId", peopleId), zap.Int("tasksCount", len(tasks)))
+	for i := range tasks {
+		task := &tasks[i]
+		task.ToPeopleId = recipients[i%len(recipients)]
+		_, err = auditTx(ctx, tx, actor, AuditAssign, "task", task.TaskId, func() (int, error) {
+			return task.TaskId, execOne(ctx, tx, `UPDATE task SET people_id = $2 WHERE id = $1`, task.TaskId, task.ToPeopleId)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 	return tasks, nil
 }
+
+// deleteTasksTx помечает удаленными задачи сотрудника с той же отметкой времени, что и у него.
+// Время каждой задачи проверяется lockedTx, как при удалении одной задачи
+func deleteTasksTx(ctx context.Context, tx *sqlx.Tx, actor model.AuditActor, peopleId int, now time.Time) error {
+	var ids []int
+	err := tx.SelectContext(ctx, &ids, `SELECT id FROM task WHERE people_id = $1 AND deleted_at IS NULL ORDER BY id FOR UPDATE`, peopleId)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		_, err = auditTx(ctx, tx, actor, AuditDelete, "task", id, func() (int, error) {
+			if err := lockedTx(ctx, tx, actor, AuditDelete, id, nil, nil); err != nil {
+				return id, err
+			}
+			return id, execOne(ctx, tx, `UPDATE task SET deleted_at = $2 WHERE id = $1`, id, now)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreTasksTx восстанавливает задачи, удаленные вместе с сотрудником в момент deletedAt.
+// Время каждой задачи проверяется lockedTx, как при восстановлении одной задачи
+func restoreTasksTx(ctx context.Context, tx *sqlx.Tx, actor model.AuditActor, peopleId int, deletedAt time.Time) error {
+	var ids []int
+	err := tx.SelectContext(ctx, &ids, `SELECT id FROM task WHERE people_id = $1 AND deleted_at = $2 ORDER BY id FOR UPDATE`, peopleId, deletedAt)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		_, err = auditTx(ctx, tx, actor, AuditRestore, "task", id, func() (int, error) {
+			if err := lockedTx(ctx, tx, actor, AuditRestore, id, nil, nil); err != nil {
+				return id, err
+			}
+			return id, execOne(ctx, tx, `UPDATE task SET deleted_at = NULL WHERE id = $1`, id)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OffboardPeople останавливает запущенные таймеры уходящего сотрудника и передает его
+// не начатые задачи по кругу сотрудникам recipients, все в одной транзакции.
+// При preview изменения откатываются, а результат показывает, что было бы сделано
+func (d *Database) OffboardPeople(ctx context.Context, actor model.AuditActor, id int, recipients []int, preview bool) (model.Offboarding, error) {
+	ctx, done := observe(ctx, "OffboardPeople")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	result := model.Offboarding{PeopleId: id, Preview: preview}
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при открытии транзакции", zap.Error(err))
+		return result, err
+	}
+	defer tx.Rollback()
+
+	result.StoppedTasks, err = stopTimersTx(ctx, tx, actor, id, time.Now())
+	if err == nil {
+		result.Reassignments, err = reassignTasksTx(ctx, tx, actor, id, recipients)
+	}
+	if err != nil {
+		if !preview {
+			d.rollbackLocked(ctx, tx, actor, AuditStop, "people", id, err)
+		}
+		logger.Ctx(ctx).Error("Ошибка при передаче задач сотрудника", zap.Error(err), zap.Int("peopleId", id))
+		return result, err
+	}
+
+	if !preview {
+		if err = tx.Commit(); err != nil {
+			logger.Ctx(ctx).Error("Ошибка при фиксации транзакции", zap.Error(err))
+			return result, err
+		}
+	}
+	logger.Ctx(ctx).Info("Задачи сотрудника переданы", zap.Int("peopleId", id), zap.Bool("preview", preview),
+		zap.Int("stopped", len(result.StoppedTasks)), zap.Int("reassigned", len(result.Reassignments)))
+	return result, nil
+}
+
+// CountRunningTimers количество задач с запущенным таймером
+func (d *Database) CountRunningTimers(ctx context.Context) (int, error) {
+	ctx, done := observe(ctx, "CountRunningTimers")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var count int
+	err := d.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM task WHERE deleted_at IS NULL AND time_start IS NOT NULL AND time_end IS NULL`)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при подсчете запущенных таймеров", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"GoTimeTracker/internal/events"
+	"GoTimeTracker/internal/model"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// insertOpenTask добавляет незавершенную задачу сотрудника: запущенную в start или не начатую при nil
+func insertOpenTask(t *testing.T, d *Database, peopleId int, start *time.Time) int {
+	t.Helper()
+	var id int
+	err := d.db.QueryRowContext(context.Background(), `INSERT INTO task (people_id, name, time_start)
+		VALUES ($1, 'Задача', $2) RETURNING id`, peopleId, start).Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestGetTasksWithOpenTimes(t *testing.T) {
+	d := testDatabase(t)
+	id := insertPeople(t, d, nil)
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+
+	unstarted := insertOpenTask(t, d, id, nil)
+	running := insertOpenTask(t, d, id, &start)
+	finished := insertTask(t, d, id, start, start.Add(time.Hour), nil)
+	var unassigned int
+	if err := d.db.Get(&unassigned, `INSERT INTO task (name) VALUES ('Без исполнителя') RETURNING id`); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := d.GetPeopleTasks(context.Background(), id, false)
+	if err != nil {
+		t.Fatalf("GetPeopleTasks: %v", err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("получено задач %d, ожидалось 3", len(tasks))
+	}
+	for _, task := range tasks {
+		switch task.Id {
+		case unstarted:
+			if task.TimeStart != nil || task.TimeEnd != nil || task.Duration != nil || task.ActualMinutes != 0 {
+				t.Errorf("не начатая задача %+v", task)
+			}
+		case running:
+			if task.TimeStart == nil || !task.TimeStart.Equal(start) || task.TimeEnd != nil || task.Duration != nil {
+				t.Errorf("запущенная задача %+v", task)
+			}
+		case finished:
+			if task.TimeEnd == nil || task.Duration == nil || *task.Duration != "01:00:00" || task.ActualMinutes != 60 {
+				t.Errorf("завершенная задача %+v", task)
+			}
+		}
+	}
+
+	tasks, err = d.GetTasks(context.Background(), []int{unstarted, running, unassigned})
+	if err != nil {
+		t.Fatalf("GetTasks: %v", err)
+	}
+	if len(tasks) != 3 || tasks[2].PeopleId != 0 || tasks[2].Description != "" {
+		t.Fatalf("получены задачи %+v, ожидались 3 с неназначенной последней", tasks)
+	}
+}
+
+func TestSetTaskEstimateNotFound(t *testing.T) {
+	d := testDatabase(t)
+	id := insertPeople(t, d, nil)
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	deleted := start.AddDate(0, 0, 1)
+	task := insertTask(t, d, id, start, start.Add(time.Hour), nil)
+	removed := insertTask(t, d, id, start, start.Add(time.Hour), &deleted)
+	estimate := 90
+
+	if err := d.SetTaskEstimate(context.Background(), testActor, task, &estimate); err != nil {
+		t.Fatalf("SetTaskEstimate: %v", err)
+	}
+	for _, missing := range []int{removed, task + removed} {
+		if err := d.SetTaskEstimate(context.Background(), testActor, missing, &estimate); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("SetTaskEstimate(%d): %v, ожидалось ErrNotFound", missing, err)
+		}
+	}
+	var stored *int
+	if err := d.db.Get(&stored, `SELECT estimate_minutes FROM task WHERE id = $1`, removed); err != nil {
+		t.Fatal(err)
+	}
+	if stored != nil {
+		t.Fatalf("удаленной задаче установлена оценка %d", *stored)
+	}
+}
+
+func TestStartTaskTimeWritesTimerEvent(t *testing.T) {
+	d := testDatabase(t)
+	id := insertPeople(t, d, nil)
+	task := insertOpenTask(t, d, id, nil)
+
+	if err := d.StartTaskTime(context.Background(), testActor, task); err != nil {
+		t.Fatalf("StartTaskTime: %v", err)
+	}
+	tasks, err := d.GetTasks(context.Background(), []int{task})
+	if err != nil {
+		t.Fatalf("GetTasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].TimeStart == nil || tasks[0].TimeEnd != nil || tasks[0].PeopleId != id {
+		t.Fatalf("получены задачи %+v, ожидалась запущенная задача", tasks)
+	}
+
+	// Поток /events получает timer.started из outbox
+	claimed, err := d.ClaimOutbox(context.Background(), 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimOutbox: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].EventType != events.TimerStarted || claimed[0].PeopleId == nil || *claimed[0].PeopleId != id {
+		t.Fatalf("выбраны события %+v, ожидалось timer.started задачи %d", claimed, task)
+	}
+}
+
+func TestEachPeopleTaskWithOpenTimes(t *testing.T) {
+	d := testDatabase(t)
+	id := insertPeople(t, d, nil)
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	insertOpenTask(t, d, id, nil)
+	insertOpenTask(t, d, id, &start)
+	insertTask(t, d, id, start, start.Add(time.Hour), nil)
+
+	count := 0
+	err := d.EachPeopleTask(context.Background(), id, false, func(model.Task) error {
+		count++
+		return nil
+	})
+	if err != nil || count != 3 {
+		t.Fatalf("EachPeopleTask: %v, выгружено задач %d, ожидалось 3", err, count)
+	}
+}
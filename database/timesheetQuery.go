@@ -0,0 +1,221 @@
+package database
+
+import (
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"time"
+)
+
+// GetTimesheet возвращает табель сотрудника за период, начинающийся start. Если табель не
+// отправлялся, возвращается черновик с Id 0. Entries и TotalMinutes не заполняются
+func (d *Database) GetTimesheet(ctx context.Context, peopleId int, period string, start, end time.Time) (model.Timesheet, error) {
+	ctx, done := observe(ctx, "GetTimesheet")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var ts model.Timesheet
+	query := `SELECT * FROM timesheet WHERE people_id = $1 AND period = $2 AND period_start = $3`
+	err := d.db.GetContext(ctx, &ts, query, peopleId, period, start)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Timesheet{PeopleId: peopleId, Period: period, PeriodStart: start, PeriodEnd: end, Status: model.TimesheetDraft}, nil
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении табеля", zap.Error(err), zap.Int("peopleId", peopleId))
+		return model.Timesheet{}, err
+	}
+	return ts, nil
+}
+
+// GetTimesheets возвращает табели сотрудников peopleIds (nil — всех) в состоянии status
+// (пустая строка — в любом) с началом периода в [from, to), новые периоды первыми
+func (d *Database) GetTimesheets(ctx context.Context, peopleIds []int, status string, from, to *time.Time) ([]model.Timesheet, error) {
+	ctx, done := observe(ctx, "GetTimesheets")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	timesheets := []model.Timesheet{}
+	query := `SELECT * FROM timesheet
+		WHERE ($1::INT[] IS NULL OR people_id = ANY($1)) AND ($2 = '' OR status = $2)
+			AND ($3::DATE IS NULL OR period_start >= $3) AND ($4::DATE IS NULL OR period_start < $4)
+		ORDER BY period_start DESC, people_id`
+	if err := d.db.SelectContext(ctx, &timesheets, query, pq.Array(peopleIds), status, from, to); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении табелей", zap.Error(err))
+		return nil, err
+	}
+	return timesheets, nil
+}
+
+// GetTimesheetPeople возвращает сотрудника табеля или ErrNotFound
+func (d *Database) GetTimesheetPeople(ctx context.Context, id int) (int, error) {
+	ctx, done := observe(ctx, "GetTimesheetPeople")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var peopleId int
+	err := d.db.GetContext(ctx, &peopleId, `SELECT people_id FROM timesheet WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении сотрудника табеля", zap.Error(err), zap.Int("id", id))
+		return 0, err
+	}
+	return peopleId, nil
+}
+
+// SubmitTimesheet отправляет табель ts на утверждение с временем minutes и возвращает его id.
+// Отправить можно новый, черновой или отклоненный табель, иначе ErrTimesheetState
+func (d *Database) SubmitTimesheet(ctx context.Context, actor model.AuditActor, ts model.Timesheet, minutes int) (int, error) {
+	ctx, done := observe(ctx, "SubmitTimesheet")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при открытии транзакции", zap.Error(err))
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var existing struct {
+		Id     int    `db:"id"`
+		Status string `db:"status"`
+	}
+	err = tx.GetContext(ctx, &existing, `SELECT id, status FROM timesheet WHERE people_id = $1 AND period = $2 AND period_start = $3 FOR UPDATE`,
+		ts.PeopleId, ts.Period, ts.PeriodStart)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Ctx(ctx).Error("Ошибка при получении табеля", zap.Error(err), zap.Int("peopleId", ts.PeopleId))
+		return 0, err
+	}
+	if existing.Status == model.TimesheetSubmitted || existing.Status == model.TimesheetApproved {
+		return 0, ErrTimesheetState
+	}
+
+	id, err := auditTx(ctx, tx, actor, AuditSubmit, "timesheet", existing.Id, func() (int, error) {
+		if existing.Id != 0 {
+			query := `UPDATE timesheet SET status = 'submitted', submitted_minutes = $2, submitted_at = NOW(),
+				comment = NULL, decided_at = NULL, decided_by = NULL WHERE id = $1`
+			return existing.Id, execOne(ctx, tx, query, existing.Id, minutes)
+		}
+		var id int
+		query := `INSERT INTO timesheet (people_id, period, period_start, period_end, status, submitted_minutes, submitted_at)
+			VALUES ($1, $2, $3, $4, 'submitted', $5, NOW()) RETURNING id`
+		err := tx.QueryRowContext(ctx, query, ts.PeopleId, ts.Period, ts.PeriodStart, ts.PeriodEnd, minutes).Scan(&id)
+		return id, err
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при отправке табеля", zap.Error(err), zap.Int("peopleId", ts.PeopleId))
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при фиксации транзакции", zap.Error(err))
+		return 0, err
+	}
+	logger.Ctx(ctx).Info("Табель отправлен на утверждение", zap.Int("id", id), zap.Int("peopleId", ts.PeopleId),
+		zap.Time("periodStart", ts.PeriodStart), zap.Int("minutes", minutes))
+	return id, nil
+}
+
+// timesheetTransitions из каких состояний допустимо действие над табелем и в какое оно переводит
+var timesheetTransitions = map[string]struct {
+	from []string
+	to   string
+}{
+	AuditApprove: {from: []string{model.TimesheetSubmitted}, to: model.TimesheetApproved},
+	AuditReject:  {from: []string{model.TimesheetSubmitted}, to: model.TimesheetRejected},
+	AuditReopen:  {from: []string{model.TimesheetApproved, model.TimesheetRejected}, to: model.TimesheetDraft},
+}
+
+// DecideTimesheet утверждает (AuditApprove), отклоняет с комментарием (AuditReject) или
+// возвращает в черновик (AuditReopen) табель id. ErrTimesheetState, если действие недопустимо
+// в текущем состоянии табеля, ErrTimerRunning при утверждении, пока в периоде идет отсчет времени:
+// запущенную задачу иначе нельзя было бы остановить
+func (d *Database) DecideTimesheet(ctx context.Context, actor model.AuditActor, id int, action string, comment *string) error {
+	ctx, done := observe(ctx, "DecideTimesheet")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	transition, ok := timesheetTransitions[action]
+	if !ok {
+		return ErrTimesheetState
+	}
+	query := `UPDATE timesheet SET status = $2, comment = $3,
+		decided_at = CASE WHEN $2 = 'draft' THEN NULL ELSE NOW() END,
+		decided_by = CASE WHEN $2 = 'draft' THEN NULL ELSE $4 END
+		WHERE id = $1 AND status = ANY($5)`
+	_, err := d.audited(ctx, actor, action, "timesheet", id, func(tx *sqlx.Tx) (int, error) {
+		if action == AuditApprove {
+			if err := timesheetTimersTx(ctx, tx, id); err != nil {
+				return id, err
+			}
+		}
+		err := execOne(ctx, tx, query, id, transition.to, comment, actor.Name, pq.Array(transition.from))
+		if errors.Is(err, ErrNotFound) {
+			// Строка заблокирована auditRow, значит табель есть, но в другом состоянии
+			err = ErrTimesheetState
+		}
+		return id, err
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при изменении состояния табеля", zap.Error(err), zap.Int("id", id), zap.String("action", action))
+		return err
+	}
+	logger.Ctx(ctx).Info("Состояние табеля изменено", zap.Int("id", id), zap.String("status", transition.to))
+	return nil
+}
+
+// timesheetTimersTx возвращает ErrTimerRunning, если у сотрудника табеля id есть запущенная задача,
+// время которой пересекается с периодом табеля
+func timesheetTimersTx(ctx context.Context, tx *sqlx.Tx, id int) error {
+	var running bool
+	query := `SELECT EXISTS (
+		SELECT 1 FROM timesheet s JOIN task t ON t.people_id = s.people_id
+		WHERE s.id = $1 AND t.time_start IS NOT NULL AND t.time_end IS NULL
+			AND t.time_start < s.period_end AND LOCALTIMESTAMP > s.period_start)`
+	if err := tx.GetContext(ctx, &running, query, id); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при проверке запущенных задач табеля", zap.Error(err), zap.Int("id", id))
+		return err
+	}
+	if running {
+		logger.Ctx(ctx).Info("Утверждение табеля с запущенной задачей отклонено", zap.Int("id", id))
+		return ErrTimerRunning
+	}
+	return nil
+}
+
+// approvedTx возвращает ErrPeriodLocked, если изменение задачи taskId затрагивает утвержденный
+// табель: время задачи пересекается с утвержденным периодом ее сотрудника или сотрудника
+// peopleId (при назначении), либо момент at, записываемый в задачу, попадает в такой период
+func approvedTx(ctx context.Context, tx *sqlx.Tx, taskId int, peopleId *int, at *time.Time) error {
+	var locked bool
+	query := `SELECT EXISTS (
+		SELECT 1 FROM task t JOIN timesheet s ON s.status = 'approved' AND (s.people_id = t.people_id OR s.people_id = $2)
+		WHERE t.id = $1 AND (
+			(t.time_start IS NOT NULL AND t.time_start < s.period_end AND COALESCE(t.time_end, LOCALTIMESTAMP) > s.period_start)
+			OR ($3::TIMESTAMP >= s.period_start AND $3::TIMESTAMP < s.period_end AND s.people_id = t.people_id)))`
+	if err := tx.GetContext(ctx, &locked, query, taskId, peopleId, at); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при проверке утвержденных табелей", zap.Error(err), zap.Int("taskId", taskId))
+		return err
+	}
+	if locked {
+		logger.Ctx(ctx).Info("Изменение времени в утвержденном табеле отклонено", zap.Int("taskId", taskId))
+		return ErrPeriodLocked
+	}
+	return nil
+}
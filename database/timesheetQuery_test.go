@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestApproveTimesheetWithRunningTimer(t *testing.T) {
+	d := testDatabase(t)
+	id := insertPeople(t, d, nil)
+	// Период вокруг текущего момента при любом часовом поясе сессии
+	week := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -2)
+	insertTimesheet(t, d, id, week, "submitted")
+	start := week.Add(time.Hour)
+	task := insertOpenTask(t, d, id, &start)
+
+	var timesheet int
+	if err := d.db.Get(&timesheet, `SELECT id FROM timesheet WHERE people_id = $1`, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.DecideTimesheet(context.Background(), testActor, timesheet, AuditApprove, nil); !errors.Is(err, ErrTimerRunning) {
+		t.Fatalf("DecideTimesheet: %v, ожидалось ErrTimerRunning", err)
+	}
+
+	if err := d.EndTaskTime(context.Background(), testActor, task); err != nil {
+		t.Fatalf("EndTaskTime: %v", err)
+	}
+	if err := d.DecideTimesheet(context.Background(), testActor, timesheet, AuditApprove, nil); err != nil {
+		t.Fatalf("DecideTimesheet после остановки задачи: %v", err)
+	}
+	// Остановленная до утверждения задача не мешает удалить сотрудника
+	if err := d.DeletePeople(context.Background(), testActor, id, TasksKeep, 0); err != nil {
+		t.Fatalf("DeletePeople: %v", err)
+	}
+}
@@ -0,0 +1,316 @@
+package database
+
+import (
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"time"
+)
+
+// webhookColumns столбцы webhook в порядке scanWebhook
+const webhookColumns = `id, url, secret, event_types, active, failure_count, disabled_at, created_at`
+
+// scanWebhook читает строку webhook. Секрет остается зашифрованным
+func scanWebhook(row interface{ Scan(...any) error }, w *model.Webhook) error {
+	return row.Scan(&w.Id, &w.URL, &w.Secret, pq.Array(&w.EventTypes), &w.Active, &w.FailureCount, &w.DisabledAt, &w.CreatedAt)
+}
+
+// AddWebhook добавляет подписку и возвращает ее с заполненными id и created_at. Секрет
+// шифруется ключом паспортов
+func (d *Database) AddWebhook(ctx context.Context, actor model.AuditActor, w model.Webhook) (model.Webhook, error) {
+	ctx, done := observe(ctx, "AddWebhook")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	secret, err := d.keys.Encrypt(w.Secret)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при шифровании секрета подписки", zap.Error(err))
+		return model.Webhook{}, err
+	}
+
+	query := `INSERT INTO webhook (url, secret, event_types) VALUES ($1, $2, $3) RETURNING id, active, created_at`
+	_, err = d.audited(ctx, actor, AuditCreate, "webhook", 0, func(tx *sqlx.Tx) (int, error) {
+		err := tx.QueryRowContext(ctx, query, w.URL, secret, pq.Array(w.EventTypes)).Scan(&w.Id, &w.Active, &w.CreatedAt)
+		return w.Id, err
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при добавлении подписки", zap.Error(err))
+		return model.Webhook{}, err
+	}
+	logger.Ctx(ctx).Info("Подписка успешно добавлена", zap.Int("id", w.Id), zap.String("url", w.URL))
+	return w, nil
+}
+
+// UpdateWebhook меняет адрес, типы событий и активность подписки. Пустой Secret оставляет
+// прежний секрет. Включение подписки сбрасывает счетчик неудачных доставок
+func (d *Database) UpdateWebhook(ctx context.Context, actor model.AuditActor, w model.Webhook) error {
+	ctx, done := observe(ctx, "UpdateWebhook")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var secret string
+	if w.Secret != "" {
+		var err error
+		if secret, err = d.keys.Encrypt(w.Secret); err != nil {
+			logger.Ctx(ctx).Error("Ошибка при шифровании секрета подписки", zap.Error(err))
+			return err
+		}
+	}
+
+	query := `UPDATE webhook SET url = $2, event_types = $3, active = $4,
+		secret = COALESCE(NULLIF($5, ''), secret),
+		failure_count = CASE WHEN $4 AND NOT active THEN 0 ELSE failure_count END,
+		disabled_at = CASE WHEN $4 THEN NULL ELSE disabled_at END
+		WHERE id = $1`
+	_, err := d.audited(ctx, actor, AuditUpdate, "webhook", w.Id, func(tx *sqlx.Tx) (int, error) {
+		_, err := tx.ExecContext(ctx, query, w.Id, w.URL, pq.Array(w.EventTypes), w.Active, secret)
+		return w.Id, err
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при обновлении подписки", zap.Error(err), zap.Int("id", w.Id))
+		return err
+	}
+	logger.Ctx(ctx).Info("Подписка успешно обновлена", zap.Int("id", w.Id))
+	return nil
+}
+
+// DeleteWebhook удаляет подписку вместе с журналом доставок
+func (d *Database) DeleteWebhook(ctx context.Context, actor model.AuditActor, id int) error {
+	ctx, done := observe(ctx, "DeleteWebhook")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	_, err := d.audited(ctx, actor, AuditDelete, "webhook", id, func(tx *sqlx.Tx) (int, error) {
+		_, err := tx.ExecContext(ctx, `DELETE FROM webhook WHERE id = $1`, id)
+		return id, err
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при удалении подписки", zap.Error(err), zap.Int("id", id))
+		return err
+	}
+	logger.Ctx(ctx).Info("Подписка успешно удалена", zap.Int("id", id))
+	return nil
+}
+
+// GetWebhooks возвращает подписки без секретов
+func (d *Database) GetWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	ctx, done := observe(ctx, "GetWebhooks")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	rows, err := d.db.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhook ORDER BY id`)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении подписок", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []model.Webhook{}
+	for rows.Next() {
+		var w model.Webhook
+		if err = scanWebhook(rows, &w); err != nil {
+			return nil, err
+		}
+		w.Secret = ""
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+// EnqueueWebhookDeliveries создает доставки события для всех активных подписок на его тип
+// и возвращает их количество. Подпискам, которым событие уже поставлено в очередь, повторная
+// доставка не создается
+func (d *Database) EnqueueWebhookDeliveries(ctx context.Context, eventId int64, eventType string, payload []byte) (int, error) {
+	ctx, done := observe(ctx, "EnqueueWebhookDeliveries")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	query := `INSERT INTO webhook_delivery (webhook_id, event_id, event_type, payload)
+		SELECT w.id, $1, $2, $3 FROM webhook w
+		WHERE w.active AND (cardinality(w.event_types) = 0 OR $2 = ANY(w.event_types))
+			AND NOT EXISTS (SELECT 1 FROM webhook_delivery d WHERE d.event_id = $1 AND d.webhook_id = w.id)`
+	result, err := d.db.ExecContext(ctx, query, eventId, eventType, payload)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при постановке доставок в очередь", zap.Error(err), zap.Int64("eventId", eventId))
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
+// WebhookJob доставка вместе с адресом и расшифрованным секретом подписки
+type WebhookJob struct {
+	model.WebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+// ClaimWebhookDeliveries выбирает до limit доставок, время попытки которых наступило, и
+// откладывает их следующую попытку на lease. Если обработчик упадет, не записав результат,
+// доставка будет повторена после lease. Несколько экземпляров сервиса не берут одни и те же доставки
+func (d *Database) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookJob, error) {
+	ctx, done := observe(ctx, "ClaimWebhookDeliveries")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	query := `WITH due AS (
+			SELECT d.id FROM webhook_delivery d JOIN webhook w ON w.id = d.webhook_id
+			WHERE d.status = 'pending' AND d.next_attempt_at <= NOW() AND w.active
+			ORDER BY d.next_attempt_at, d.id LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_delivery d SET next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM due, webhook w WHERE d.id = due.id AND w.id = d.webhook_id
+		RETURNING d.*, w.url, w.secret`
+	var jobs []WebhookJob
+	if err := d.db.SelectContext(ctx, &jobs, query, limit, lease.Seconds()); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при выборе доставок", zap.Error(err))
+		return nil, err
+	}
+	for i := range jobs {
+		secret, err := d.keys.Decrypt(jobs[i].Secret)
+		if err != nil {
+			logger.Ctx(ctx).Error("Ошибка при расшифровке секрета подписки", zap.Error(err), zap.Int("webhookId", jobs[i].WebhookId))
+			return nil, err
+		}
+		jobs[i].Secret = secret
+	}
+	return jobs, nil
+}
+
+// WebhookAttempt результат попытки доставки
+type WebhookAttempt struct {
+	Success    bool
+	StatusCode *int
+	Error      string
+	// NextAttemptAt время следующей попытки, nil если попытки исчерпаны
+	NextAttemptAt *time.Time
+}
+
+// RecordWebhookAttempt сохраняет результат попытки доставки. Успешная доставка сбрасывает
+// счетчик неудач подписки, неудачная увеличивает его и при достижении disableAfter отключает
+// подписку от имени actor. Возвращает true, если подписка отключена этой попыткой
+func (d *Database) RecordWebhookAttempt(ctx context.Context, actor model.AuditActor, job WebhookJob, attempt WebhookAttempt, disableAfter int) (bool, error) {
+	ctx, done := observe(ctx, "RecordWebhookAttempt")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при открытии транзакции", zap.Error(err))
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var lastError *string
+	if attempt.Error != "" {
+		lastError = &attempt.Error
+	}
+	status := model.DeliveryDelivered
+	if !attempt.Success {
+		status = model.DeliveryPending
+		if attempt.NextAttemptAt == nil {
+			status = model.DeliveryFailed
+		}
+	}
+	query := `UPDATE webhook_delivery SET status = $2, attempts = attempts + 1, last_status_code = $3, last_error = $4,
+		next_attempt_at = COALESCE($5, next_attempt_at),
+		delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() ELSE delivered_at END
+		WHERE id = $1`
+	if _, err = tx.ExecContext(ctx, query, job.Id, status, attempt.StatusCode, lastError, attempt.NextAttemptAt); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при сохранении попытки доставки", zap.Error(err), zap.Int64("deliveryId", job.Id))
+		return false, err
+	}
+
+	var failures int
+	if attempt.Success {
+		_, err = tx.ExecContext(ctx, `UPDATE webhook SET failure_count = 0 WHERE id = $1 AND failure_count > 0`, job.WebhookId)
+	} else {
+		err = tx.QueryRowContext(ctx, `UPDATE webhook SET failure_count = failure_count + 1 WHERE id = $1 RETURNING failure_count`,
+			job.WebhookId).Scan(&failures)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Ctx(ctx).Error("Ошибка при обновлении счетчика неудач подписки", zap.Error(err), zap.Int("webhookId", job.WebhookId))
+		return false, err
+	}
+
+	disabled := !attempt.Success && disableAfter > 0 && failures == disableAfter
+	if disabled {
+		_, err = auditTx(ctx, tx, actor, AuditUpdate, "webhook", job.WebhookId, func() (int, error) {
+			_, err := tx.ExecContext(ctx, `UPDATE webhook SET active = FALSE, disabled_at = NOW() WHERE id = $1`, job.WebhookId)
+			return job.WebhookId, err
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при фиксации транзакции", zap.Error(err))
+		return false, err
+	}
+	return disabled, nil
+}
+
+// GetWebhookDeliveries возвращает страницу журнала доставок подписки, новые первыми.
+// Пустой status не ограничивает выборку
+func (d *Database) GetWebhookDeliveries(ctx context.Context, webhookId int, status string, page, pageSize int) ([]model.WebhookDelivery, error) {
+	ctx, done := observe(ctx, "GetWebhookDeliveries")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	deliveries := []model.WebhookDelivery{}
+	query := `SELECT * FROM webhook_delivery WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY id DESC LIMIT $3 OFFSET $4`
+	if err := d.db.SelectContext(ctx, &deliveries, query, webhookId, status, pageSize, (page-1)*pageSize); err != nil {
+		logger.Ctx(ctx).Error("Ошибка при получении журнала доставок", zap.Error(err), zap.Int("webhookId", webhookId))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RedeliverWebhook создает новую доставку того же события той же подписке и возвращает ее.
+// ErrNotFound, если исходной доставки нет
+func (d *Database) RedeliverWebhook(ctx context.Context, deliveryId int64) (model.WebhookDelivery, error) {
+	ctx, done := observe(ctx, "RedeliverWebhook")
+	defer done()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var delivery model.WebhookDelivery
+	query := `INSERT INTO webhook_delivery (webhook_id, event_id, event_type, payload)
+		SELECT webhook_id, event_id, event_type, payload FROM webhook_delivery WHERE id = $1
+		RETURNING *`
+	err := d.db.GetContext(ctx, &delivery, query, deliveryId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.WebhookDelivery{}, ErrNotFound
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при повторной доставке", zap.Error(err), zap.Int64("deliveryId", deliveryId))
+		return model.WebhookDelivery{}, err
+	}
+	logger.Ctx(ctx).Info("Доставка поставлена в очередь повторно", zap.Int64("deliveryId", deliveryId), zap.Int64("id", delivery.Id))
+	return delivery, nil
+}
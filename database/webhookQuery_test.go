@@ -0,0 +1,70 @@
+package database
+
+import (
+	"GoTimeTracker/internal/model"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRecordWebhookAttemptDisablesWebhook(t *testing.T) {
+	d := testDatabase(t)
+	ctx := context.Background()
+	const disableAfter = 3
+
+	webhook, err := d.AddWebhook(ctx, testActor, model.Webhook{URL: "https://example.com/hooks", Secret: "whsec_test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for event := int64(1); event <= disableAfter+1; event++ {
+		if _, err = d.EnqueueWebhookDeliveries(ctx, event, "task.created", []byte(`{}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	claimed, err := d.ClaimWebhookDeliveries(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != disableAfter+1 || claimed[0].Secret != "whsec_test" {
+		t.Fatalf("выбрано доставок %d, ожидалось %d с расшифрованным секретом", len(claimed), disableAfter+1)
+	}
+
+	status := http.StatusInternalServerError
+	next := time.Now().Add(time.Minute)
+	failure := WebhookAttempt{StatusCode: &status, Error: "HTTP 500", NextAttemptAt: &next}
+	ok := http.StatusOK
+
+	// Успешная доставка сбрасывает счетчик неудач подряд
+	results := []WebhookAttempt{failure, {Success: true, StatusCode: &ok}, failure, failure}
+	for i, attempt := range results {
+		disabled, err := d.RecordWebhookAttempt(ctx, testActor, claimed[i], attempt, disableAfter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if disabled {
+			t.Fatalf("подписка отключена после попытки %d", i+1)
+		}
+	}
+	jobs, err := d.ClaimWebhookDeliveries(ctx, 10, time.Minute)
+	if err != nil || len(jobs) != 0 {
+		t.Fatalf("выбрано доставок %d (%v) до наступления повтора", len(jobs), err)
+	}
+
+	disabled, err := d.RecordWebhookAttempt(ctx, testActor, claimed[disableAfter], failure, disableAfter)
+	if err != nil || !disabled {
+		t.Fatalf("подписка не отключена после %d неудач подряд: %v", disableAfter, err)
+	}
+
+	webhooks, err := d.GetWebhooks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(webhooks) != 1 || webhooks[0].Active || webhooks[0].DisabledAt == nil || webhooks[0].FailureCount != disableAfter {
+		t.Fatalf("подписка после отключения: %+v", webhooks)
+	}
+	deliveries, err := d.GetWebhookDeliveries(ctx, webhook.Id, model.DeliveryDelivered, 1, 10)
+	if err != nil || len(deliveries) != 1 || deliveries[0].DeliveredAt == nil {
+		t.Fatalf("доставленные: %+v, %v", deliveries, err)
+	}
+}
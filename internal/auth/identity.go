@@ -0,0 +1,35 @@
+package auth
+
+import "github.com/gin-gonic/gin"
+
+// Типы вызывающей стороны
+const (
+	KindUser   = "user"
+	KindAPIKey = "api_key"
+	// KindCLI служебные команды, запущенные с сервера
+	KindCLI = "cli"
+	// KindSystem фоновые задачи сервера
+	KindSystem = "system"
+)
+
+// identityKey ключ, под которым Middleware сохраняет Identity в контексте gin
+const identityKey = "identity"
+
+// Identity аутентифицированная вызывающая сторона: пользователь или интеграция с ключом доступа
+type Identity struct {
+	Kind     string `json:"kind"`
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	Role     Role   `json:"role"`
+	PeopleId *int   `json:"people_id,omitempty"`
+}
+
+// FromContext возвращает вызывающую сторону, сохраненную Middleware
+func FromContext(ctx *gin.Context) (Identity, bool) {
+	value, ok := ctx.Get(identityKey)
+	if !ok {
+		return Identity{}, false
+	}
+	identity, ok := value.(Identity)
+	return identity, ok
+}
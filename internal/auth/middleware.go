@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader заголовок с ключом доступа интеграции
+const APIKeyHeader = "X-API-Key"
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Middleware пропускает только запросы с действующим токеном (Authorization: Bearer <jwt>)
+// или ключом доступа (X-API-Key). Вызывающая сторона сохраняется в контексте, см. FromContext
+func Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		identity, err := Authenticate(ctx.Request.Context(), ctx.GetHeader("Authorization"), ctx.GetHeader(APIKeyHeader))
+		if IsDenied(err) {
+			logger.Ctx(ctx.Request.Context()).Info("Отказ в доступе", zap.String("path", ctx.FullPath()), zap.Error(err))
+			ctx.Header("WWW-Authenticate", `Bearer realm="GoTimeTracker"`)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			logger.Ctx(ctx.Request.Context()).Error("Ошибка при аутентификации", zap.Error(err))
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+			return
+		}
+
+		ctx.Set(identityKey, identity)
+		ctx.Request = ctx.Request.WithContext(logger.With(ctx.Request.Context(),
+			zap.String("actor_kind", identity.Kind),
+			zap.Int("actor_id", identity.Id),
+			zap.String("actor_name", identity.Name),
+		))
+		logger.Ctx(ctx.Request.Context()).Debug("Запрос аутентифицирован")
+		ctx.Next()
+	}
+}
+
+// authError отказ в доступе из-за отсутствующих или неверных учетных данных, в отличие от
+// внутренних ошибок проверки
+type authError string
+
+func (e authError) Error() string {
+	return string(e)
+}
+
+// IsDenied проверяет, что Authenticate отказала в доступе, а не завершилась внутренней ошибкой
+func IsDenied(err error) bool {
+	var denied authError
+	return errors.As(err, &denied)
+}
+
+// Authenticate определяет вызывающую сторону по ключу доступа apiKey или, если он пуст,
+// по значению заголовка Authorization вида "Bearer <jwt>". Используется и вне HTTP, например в gRPC
+func Authenticate(ctx context.Context, authorization, apiKey string) (Identity, error) {
+	if apiKey != "" {
+		db, err := database.GetInstance()
+		if err != nil {
+			return Identity{}, err
+		}
+		found, err := db.GetAPIKeyByHash(ctx, HashAPIKey(apiKey))
+		if err != nil {
+			return Identity{}, err
+		}
+		if found == nil {
+			return Identity{}, authError("Неверный или отозванный ключ доступа")
+		}
+		return Identity{Kind: KindAPIKey, Id: found.Id, Name: found.Name, Role: Role(found.Role)}, nil
+	}
+
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || token == "" {
+		return Identity{}, authError("Требуется аутентификация")
+	}
+	identity, err := ParseToken(token)
+	if err != nil {
+		return Identity{}, authError("Неверный или просроченный токен")
+	}
+	return identity, nil
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"GoTimeTracker/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// Role роль пользователя или ключа доступа
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleManager  Role = "manager"
+	RoleEmployee Role = "employee"
+)
+
+// Permission действие над ресурсом
+type Permission string
+
+const (
+	PeopleRead     Permission = "people:read"
+	PeopleCreate   Permission = "people:create"
+	PeopleWrite    Permission = "people:write"
+	PeopleDelete   Permission = "people:delete"
+	PeoplePassport Permission = "people:passport"
+	TaskRead       Permission = "task:read"
+	TaskWrite      Permission = "task:write"
+	TaskTimer      Permission = "task:timer"
+	ReportRead     Permission = "report:read"
+	AuditRead      Permission = "audit:read"
+	DeletedRead    Permission = "deleted:read"
+	DeletedRestore Permission = "deleted:restore"
+	LogManage      Permission = "log:manage"
+	WebhookManage  Permission = "webhook:manage"
+	// TimesheetSubmit отправка табеля на утверждение
+	TimesheetSubmit Permission = "timesheet:submit"
+	// TimesheetApprove утверждение, отклонение и возврат табеля
+	TimesheetApprove Permission = "timesheet:approve"
+	// PeriodClose закрытие периодов и отчет о попытках изменить заблокированное время
+	PeriodClose Permission = "period:close"
+	// PeriodOverride изменение времени в закрытом периоде с указанием причины
+	PeriodOverride Permission = "period:override"
+)
+
+// Scope круг сотрудников, в отношении которых разрешено действие
+type Scope int
+
+const (
+	// ScopeNone действие запрещено
+	ScopeNone Scope = iota
+	// ScopeOwn только сам сотрудник, связанный с пользователем
+	ScopeOwn
+	// ScopeTeam сотрудник и его подчиненные (people.manager_id)
+	ScopeTeam
+	// ScopeAll все сотрудники
+	ScopeAll
+)
+
+// permissions матрица прав: роль → действие → область
+var permissions = map[Role]map[Permission]Scope{
+	RoleAdmin: {
+		PeopleRead:       ScopeAll,
+		PeopleCreate:     ScopeAll,
+		PeopleWrite:      ScopeAll,
+		PeopleDelete:     ScopeAll,
+		PeoplePassport:   ScopeAll,
+		TaskRead:         ScopeAll,
+		TaskWrite:        ScopeAll,
+		TaskTimer:        ScopeAll,
+		ReportRead:       ScopeAll,
+		AuditRead:        ScopeAll,
+		DeletedRead:      ScopeAll,
+		DeletedRestore:   ScopeAll,
+		LogManage:        ScopeAll,
+		WebhookManage:    ScopeAll,
+		TimesheetSubmit:  ScopeAll,
+		TimesheetApprove: ScopeAll,
+		PeriodClose:      ScopeAll,
+		PeriodOverride:   ScopeAll,
+	},
+	RoleManager: {
+		PeopleRead:       ScopeTeam,
+		PeopleWrite:      ScopeTeam,
+		TaskRead:         ScopeTeam,
+		TaskWrite:        ScopeTeam,
+		TaskTimer:        ScopeTeam,
+		ReportRead:       ScopeTeam,
+		TimesheetSubmit:  ScopeTeam,
+		TimesheetApprove: ScopeTeam,
+	},
+	RoleEmployee: {
+		PeopleRead:      ScopeOwn,
+		TaskRead:        ScopeOwn,
+		TaskTimer:       ScopeOwn,
+		ReportRead:      ScopeOwn,
+		TimesheetSubmit: ScopeOwn,
+	},
+}
+
+// ValidRole проверяет, что роль известна
+func ValidRole(role Role) bool {
+	_, ok := permissions[role]
+	return ok
+}
+
+// ScopeOf возвращает область, в которой вызывающей стороне разрешено действие
+func (i Identity) ScopeOf(permission Permission) Scope {
+	return permissions[i.Role][permission]
+}
+
+// Require пропускает запрос, только если роли вызывающей стороны разрешено действие хотя бы
+// в какой-то области. Конкретные сотрудники и задачи проверяются в сервисном слое
+func Require(permission Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		identity, ok := FromContext(ctx)
+		if !ok || identity.ScopeOf(permission) == ScopeNone {
+			logger.Ctx(ctx.Request.Context()).Info("Недостаточно прав", zap.String("path", ctx.FullPath()),
+				zap.String("role", string(identity.Role)), zap.String("permission", string(permission)))
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse{Error: "Недостаточно прав"})
+			return
+		}
+		ctx.Next()
+	}
+}
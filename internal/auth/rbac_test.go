@@ -0,0 +1,75 @@
+package auth
+
+import "testing"
+
+func TestPermissions(t *testing.T) {
+	tests := []struct {
+		role       Role
+		permission Permission
+		want       Scope
+	}{
+		{RoleAdmin, PeopleCreate, ScopeAll},
+		{RoleAdmin, PeoplePassport, ScopeAll},
+		{RoleAdmin, WebhookManage, ScopeAll},
+		{RoleAdmin, PeriodOverride, ScopeAll},
+		{RoleManager, PeopleRead, ScopeTeam},
+		{RoleManager, TaskTimer, ScopeTeam},
+		{RoleManager, TimesheetApprove, ScopeTeam},
+		{RoleManager, PeopleCreate, ScopeNone},
+		{RoleManager, PeopleDelete, ScopeNone},
+		{RoleManager, PeoplePassport, ScopeNone},
+		{RoleManager, AuditRead, ScopeNone},
+		{RoleManager, PeriodClose, ScopeNone},
+		{RoleManager, PeriodOverride, ScopeNone},
+		{RoleEmployee, PeopleRead, ScopeOwn},
+		{RoleEmployee, TaskTimer, ScopeOwn},
+		{RoleEmployee, TimesheetSubmit, ScopeOwn},
+		{RoleEmployee, TaskWrite, ScopeNone},
+		{RoleEmployee, TimesheetApprove, ScopeNone},
+		{RoleEmployee, DeletedRestore, ScopeNone},
+		{RoleEmployee, LogManage, ScopeNone},
+		{"guest", PeopleRead, ScopeNone},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role)+" "+string(tt.permission), func(t *testing.T) {
+			if got := (Identity{Role: tt.role}).ScopeOf(tt.permission); got != tt.want {
+				t.Fatalf("область %d, ожидалась %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermissionsNarrowWithRole(t *testing.T) {
+	// Ни одна роль не получает действие в большей области, чем роль выше нее
+	order := []Role{RoleAdmin, RoleManager, RoleEmployee}
+	for i := 1; i < len(order); i++ {
+		for permission, scope := range permissions[order[i]] {
+			if higher := permissions[order[i-1]][permission]; scope > higher {
+				t.Errorf("%s: у роли %s область %d больше, чем у %s (%d)", permission, order[i], scope, order[i-1], higher)
+			}
+		}
+	}
+}
+
+func TestAdminHasEveryPermission(t *testing.T) {
+	for role, granted := range permissions {
+		for permission := range granted {
+			if permissions[RoleAdmin][permission] != ScopeAll {
+				t.Errorf("%s: есть у роли %s, но не у администратора", permission, role)
+			}
+		}
+	}
+}
+
+func TestValidRole(t *testing.T) {
+	for _, role := range []Role{RoleAdmin, RoleManager, RoleEmployee} {
+		if !ValidRole(role) {
+			t.Errorf("роль %s не признана", role)
+		}
+	}
+	for _, role := range []Role{"", "root", "Admin"} {
+		if ValidRole(role) {
+			t.Errorf("неизвестная роль %q признана", role)
+		}
+	}
+}
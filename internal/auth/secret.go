@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"GoTimeTracker/internal/model"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+)
+
+// dummyPasswordHash bcrypt-хеш случайного пароля со стоимостью bcrypt.DefaultCost. С ним сравнивается
+// пароль неизвестного пользователя, чтобы по времени ответа нельзя было узнать, существует ли логин
+const dummyPasswordHash = "$2a$10$5UJMA4svY0cRPYBKe9We2OdzbycaVpenwMhAiO7OewPE/RcPWqgVu"
+
+// apiKeyPrefix позволяет отличить ключ доступа трекера, например при поиске утекших секретов
+const apiKeyPrefix = "tt_"
+
+// GenerateAPIKey создает новый ключ доступа и его хеш для хранения
+func GenerateAPIKey() (key string, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err = io.ReadFull(rand.Reader, raw); err != nil {
+		return "", "", err
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	return key, HashAPIKey(key), nil
+}
+
+// HashAPIKey хеш ключа доступа. Ключ случайный и длинный, поэтому достаточно SHA-256 без соли,
+// что позволяет искать ключ по хешу
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashPassword хеширует пароль пользователя bcrypt
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// CheckPassword сравнивает пароль с хешем
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// CheckLogin сравнивает пароль с хешем пользователя user. Для неизвестного пользователя (nil)
+// пароль сравнивается с dummyPasswordHash, поэтому проверка занимает столько же времени
+func CheckLogin(user *model.User, password string) bool {
+	if user == nil {
+		CheckPassword(dummyPasswordHash, password)
+		return false
+	}
+	return CheckPassword(user.PasswordHash, password)
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/logger"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultTokenTTL время жизни токена, если JWT_TTL не задан
+const defaultTokenTTL = 12 * time.Hour
+
+// tokenIssuer издатель токенов, проверяется при разборе
+const tokenIssuer = "GoTimeTracker"
+
+// claims содержимое токена пользователя
+type claims struct {
+	Login    string `json:"login"`
+	Role     Role   `json:"role"`
+	PeopleId *int   `json:"people_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// minSecretLength наименьшая длина JWT_SECRET в байтах
+const minSecretLength = 32
+
+var (
+	secret    []byte
+	tokenTTL  time.Duration
+	configErr error
+	loadOnce  sync.Once
+)
+
+// LoadConfig читает JWT_SECRET и JWT_TTL. Возвращает ошибку, если JWT_SECRET не задан или короче
+// minSecretLength байт, поэтому сервер вызывает ее при запуске и без секрета не стартует
+func LoadConfig() error {
+	loadOnce.Do(func() {
+		secret, tokenTTL, configErr = readConfig()
+	})
+	return configErr
+}
+
+// readConfig разбирает настройки токенов из окружения
+func readConfig() ([]byte, time.Duration, error) {
+	value := []byte(os.Getenv("JWT_SECRET"))
+	if len(value) == 0 {
+		return nil, 0, fmt.Errorf("JWT_SECRET не задан, создайте его командой generate-key")
+	}
+	if len(value) < minSecretLength {
+		return nil, 0, fmt.Errorf("JWT_SECRET короче %d байт, создайте его командой generate-key", minSecretLength)
+	}
+
+	ttl := defaultTokenTTL
+	if raw := os.Getenv("JWT_TTL"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			logger.Error("Неверное значение JWT_TTL, используется значение по умолчанию", zap.Error(err))
+		} else {
+			ttl = parsed
+		}
+	}
+	return value, ttl, nil
+}
+
+// IssueToken выпускает токен для пользователя
+func IssueToken(user model.User) (string, time.Time, error) {
+	if err := LoadConfig(); err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expires := now.Add(tokenTTL)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		Login:    user.Login,
+		Role:     Role(user.Role),
+		PeopleId: user.PeopleId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   strconv.Itoa(user.Id),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expires),
+		},
+	})
+
+	signed, err := token.SignedString(secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expires, nil
+}
+
+// ParseToken проверяет подпись и срок действия токена и возвращает пользователя
+func ParseToken(value string) (Identity, error) {
+	if err := LoadConfig(); err != nil {
+		return Identity{}, err
+	}
+
+	var c claims
+	_, err := jwt.ParseWithClaims(value, &c, func(*jwt.Token) (interface{}, error) {
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(tokenIssuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return Identity{}, err
+	}
+
+	id, err := strconv.Atoi(c.Subject)
+	if err != nil {
+		return Identity{}, fmt.Errorf("неверный идентификатор пользователя в токене")
+	}
+	return Identity{Kind: KindUser, Id: id, Name: c.Login, Role: c.Role, PeopleId: c.PeopleId}, nil
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"GoTimeTracker/internal/model"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadConfigRequiresSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		ttl     string
+		wantTTL time.Duration
+		wantErr string
+	}{
+		{name: "секрет не задан", wantErr: "не задан"},
+		{name: "короткий секрет", secret: "change-me", wantErr: "короче"},
+		{name: "секрет задан", secret: strings.Repeat("s", minSecretLength), wantTTL: defaultTokenTTL},
+		{name: "срок из окружения", secret: strings.Repeat("s", minSecretLength), ttl: "1h", wantTTL: time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_SECRET", tt.secret)
+			t.Setenv("JWT_TTL", tt.ttl)
+
+			_, ttl, err := readConfig()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("readConfig: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("readConfig: %v, ожидалась ошибка с %q", err, tt.wantErr)
+			case ttl != tt.wantTTL:
+				t.Fatalf("срок токена %s, ожидался %s", ttl, tt.wantTTL)
+			}
+		})
+	}
+}
+
+func TestCheckLogin(t *testing.T) {
+	hash, err := HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &model.User{Login: "admin", PasswordHash: hash}
+
+	if !CheckLogin(user, "secret") {
+		t.Fatal("верный пароль отклонен")
+	}
+	if CheckLogin(user, "wrong") {
+		t.Fatal("неверный пароль принят")
+	}
+	if CheckLogin(nil, "secret") {
+		t.Fatal("принят пароль неизвестного пользователя")
+	}
+}
+
+func TestDummyPasswordHashCost(t *testing.T) {
+	// Неизвестный логин должен проверяться так же долго, как существующий
+	cost, err := bcrypt.Cost([]byte(dummyPasswordHash))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != bcrypt.DefaultCost {
+		t.Fatalf("стоимость dummyPasswordHash %d, ожидалась %d", cost, bcrypt.DefaultCost)
+	}
+}
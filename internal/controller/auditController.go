@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/service"
+	"GoTimeTracker/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// GetAuditLog godoc
+//
+//	@Summary		Журнал изменений
+//	@Description	Возвращает записи журнала изменений, новые первыми. Diff содержит только изменившиеся поля.
+//	@Description	Доступно только администраторам
+//	@Tags			audit
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			page		query		int		true	"Страница"							example(1)
+//	@Param			page_size	query		int		true	"Количество записей на странице"	example(20)
+//	@Param			actor_kind	query		string	false	"Тип автора"						Enums(user, api_key, cli, system)
+//	@Param			actor_id	query		int		false	"Идентификатор автора"
+//	@Param			action		query		string	false	"Действие"							Enums(create, update, delete, assign, start, stop, restore, purge, submit, approve, reject, reopen)
+//	@Param			entity		query		string	false	"Сущность"							Enums(people, task, users, api_key, timesheet, closed_period)
+//	@Param			entity_id	query		int		false	"Идентификатор сущности"
+//	@Param			request_id	query		string	false	"Идентификатор запроса (X-Request-ID)"
+//	@Param			client_ip	query		string	false	"Адрес клиента"
+//	@Param			from		query		string	false	"Начало периода (RFC 3339)"			example(2024-07-01T00:00:00Z)
+//	@Param			to			query		string	false	"Конец периода (RFC 3339, не включая)"
+//
+//	@Success		200			{array}		model.AuditEntry
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/auditLog [get]
+func GetAuditLog(ctx *gin.Context) {
+	page, err := strconv.Atoi(ctx.Query("page"))
+	if err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при парсинге значении страницы", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	pageSize, err := strconv.Atoi(ctx.Query("page_size"))
+	if err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при парсинге количества страниц", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	filter := database.AuditFilter{Fields: make(map[string]string)}
+	for _, field := range []string{"actor_kind", "actor_id", "action", "entity", "entity_id", "request_id", "client_ip"} {
+		value := ctx.Query(field)
+		if value == "" {
+			continue
+		}
+		if field == "actor_id" || field == "entity_id" {
+			if _, err = strconv.Atoi(value); err != nil {
+				ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение " + field})
+				return
+			}
+		}
+		filter.Fields[field] = value
+	}
+	for param, bound := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := ctx.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение " + param})
+			return
+		}
+		*bound = &t
+	}
+
+	entries, err := service.GetAuditLog(ctx.Request.Context(), actor(ctx), page, pageSize, filter)
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при получении журнала изменений")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+	logger.Ctx(ctx.Request.Context()).Info("Успешно получен журнал изменений")
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// LoginRequest логин и пароль пользователя
+type LoginRequest struct {
+	Login    string `json:"login" binding:"required" example:"ivanov"`
+	Password string `json:"password" binding:"required" example:"secret"`
+}
+
+// LoginResponse токен для заголовка Authorization: Bearer <token>
+type LoginResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Login godoc
+//
+//	@Summary		Войти
+//	@Description	Проверяет логин и пароль и выдает JWT для заголовка Authorization: Bearer
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			credentials	body		LoginRequest	true	"Логин и пароль"
+//	@Success		200			{object}	LoginResponse
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		413			{object}	ErrorResponse
+//	@Failure		429			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/login [post]
+func Login(ctx *gin.Context) {
+	var req LoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		bodyError(ctx, err)
+		return
+	}
+
+	db, err := database.GetInstance()
+	if err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка получения экземпляра базы данных", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := db.GetUserByLogin(ctx.Request.Context(), req.Login)
+	if err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при получении пользователя", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if !auth.CheckLogin(user, req.Password) {
+		logger.Ctx(ctx.Request.Context()).Info("Неудачная попытка входа", zap.String("login", req.Login))
+		ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Неверный логин или пароль"})
+		return
+	}
+
+	token, expires, err := auth.IssueToken(*user)
+	if err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при выпуске токена", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, LoginResponse{Token: token, ExpiresAt: expires})
+	logger.Ctx(ctx.Request.Context()).Info("Пользователь вошел в систему", zap.String("login", user.Login))
+}
+
+// Me godoc
+//
+//	@Summary		Текущий пользователь
+//	@Description	Возвращает пользователя или ключ доступа, от имени которого выполнен запрос
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200	{object}	auth.Identity
+//	@Failure		401	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/me [get]
+func Me(ctx *gin.Context) {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Требуется аутентификация"})
+		return
+	}
+	ctx.JSON(http.StatusOK, identity)
+}
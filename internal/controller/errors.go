@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/service"
+	"GoTimeTracker/pkg/logger"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// actor вызывающая сторона, сохраненная auth.Middleware
+func actor(ctx *gin.Context) auth.Identity {
+	identity, _ := auth.FromContext(ctx)
+	return identity
+}
+
+// serviceError отвечает на ошибку сервисного слоя: 403 при нехватке прав, 404 если запись
+// не найдена, 423 при изменении времени в утвержденном табеле или закрытом периоде, 409 при
+// недопустимом переходе табеля или запущенной в периоде задаче, иначе логирует ошибку с message
+// и отвечает 500
+func serviceError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		ctx.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrNotFound):
+		ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrPeriodLocked), errors.Is(err, service.ErrPeriodClosed):
+		ctx.JSON(http.StatusLocked, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrTimesheetState), errors.Is(err, service.ErrTimerRunning):
+		ctx.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		logger.Ctx(ctx.Request.Context()).Error(message, zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+// bodyError отвечает на ошибку чтения тела запроса: 413, если тело больше допустимого
+// (см. request.BodyLimit), иначе 400
+func bodyError(ctx *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		ctx.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Слишком большое тело запроса"})
+		return
+	}
+	ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+}
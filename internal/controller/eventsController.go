@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"GoTimeTracker/internal/events"
+	"GoTimeTracker/internal/service"
+	"GoTimeTracker/pkg/logger"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// eventsRetry через сколько миллисекунд EventSource переподключается после обрыва
+const eventsRetry = 3000
+
+// Events godoc
+//
+//	@Summary		Поток событий
+//	@Description	Server-Sent Events с изменениями таймеров, задач и сотрудников, доступных вызывающей стороне.
+//	@Description	Имя события SSE совпадает с type, id используется для переподключения: EventSource
+//	@Description	передает последний полученный id в заголовке Last-Event-ID, и пропущенные события
+//	@Description	отправляются из истории. Если история не покрывает разрыв, первым приходит событие reset:
+//	@Description	клиенту нужно заново загрузить состояние. Клиент, не успевающий читать поток, получает
+//	@Description	событие lagged и отключается. Пока событий нет, раз в EVENTS_HEARTBEAT приходит комментарий ping
+//	@Tags			events
+//	@Produce		text/event-stream
+//	@Param			people			query		string	false	"Сотрудники через запятую"								example(1,2)
+//	@Param			team			query		int		false	"Руководитель: события его команды"
+//	@Param			project			query		string	false	"Проект"												example(Трекер)
+//	@Param			types			query		string	false	"Типы событий через запятую"							example(timer.started,timer.stopped)
+//	@Param			last_event_id	query		int		false	"Последнее полученное событие, если нельзя передать Last-Event-ID"
+//	@Param			Last-Event-ID	header		int		false	"Последнее полученное событие"
+//	@Success		200				{object}	events.Event
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		403				{object}	ErrorResponse
+//	@Failure		404				{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/events [get]
+func Events(ctx *gin.Context) {
+	var peopleIds []int
+	if value := ctx.Query("people"); value != "" {
+		for _, part := range strings.Split(value, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверный список сотрудников"})
+				return
+			}
+			peopleIds = append(peopleIds, id)
+		}
+	}
+
+	var teamId *int
+	if value := ctx.Query("team"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение team"})
+			return
+		}
+		teamId = &id
+	}
+
+	var types []string
+	if value := ctx.Query("types"); value != "" {
+		for _, part := range strings.Split(value, ",") {
+			eventType := strings.TrimSpace(part)
+			if !slices.Contains(events.Types, eventType) {
+				ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неизвестный тип события " + eventType})
+				return
+			}
+			types = append(types, eventType)
+		}
+	}
+
+	lastEventId := ctx.GetHeader("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = ctx.Query("last_event_id")
+	}
+	var lastId uint64
+	if lastEventId != "" {
+		var err error
+		if lastId, err = strconv.ParseUint(lastEventId, 10, 64); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение Last-Event-ID"})
+			return
+		}
+	}
+
+	sub, replay, complete, err := service.SubscribeEvents(ctx.Request.Context(), actor(ctx), peopleIds, teamId,
+		ctx.Query("project"), types, lastId)
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при подписке на события")
+		return
+	}
+	defer sub.Close()
+
+	header := ctx.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// Отключает буферизацию ответа в nginx
+	header.Set("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	fmt.Fprintf(ctx.Writer, "retry: %d\n\n", eventsRetry)
+	if !complete {
+		writeControlEvent(ctx, "reset")
+	}
+	for _, e := range replay {
+		if writeEvent(ctx, e) != nil {
+			return
+		}
+	}
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(events.Default().Heartbeat())
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case e, ok := <-sub.Events():
+			if !ok {
+				if sub.Lagged() {
+					logger.Ctx(ctx.Request.Context()).Info("Подписчик не успевает читать события и отключен")
+					writeControlEvent(ctx, "lagged")
+				}
+				return
+			}
+			if writeEvent(ctx, e) != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(ctx.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		ctx.Writer.Flush()
+	}
+}
+
+// writeEvent пишет событие в поток SSE
+func writeEvent(ctx *gin.Context, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка сериализации события", zap.Error(err), zap.Uint64("id", e.Id))
+		return nil
+	}
+	_, err = fmt.Fprintf(ctx.Writer, "id: %d\nevent: %s\ndata: %s\n\n", e.Id, e.Type, data)
+	return err
+}
+
+// writeControlEvent пишет служебное событие без id, чтобы не сбить Last-Event-ID клиента
+func writeControlEvent(ctx *gin.Context, name string) {
+	fmt.Fprintf(ctx.Writer, "event: %s\ndata: {}\n\n", name)
+	ctx.Writer.Flush()
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"GoTimeTracker/internal/export"
+	"GoTimeTracker/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// writeExport выгружает строки, которые each передает в write, в файл выбранного формата.
+// Строки пишутся в ответ по мере чтения из базы, поэтому после начала выгрузки
+// ошибку уже нельзя вернуть клиенту в виде ErrorResponse и она только логируется
+func writeExport(ctx *gin.Context, format export.Format, name string, columns []export.Column, each func(write func(values ...any) error) error) {
+	writer, err := export.Start(ctx, format, name, columns)
+	if err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при подготовке выгрузки", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = each(writer.WriteRow)
+	if err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при выгрузке", zap.Error(err), zap.String("export", name))
+		_ = ctx.Error(err)
+		return
+	}
+	if err = writer.Close(); err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при завершении выгрузки", zap.Error(err), zap.String("export", name))
+		_ = ctx.Error(err)
+		return
+	}
+	logger.Ctx(ctx.Request.Context()).Info("Выгрузка успешно сформирована", zap.String("export", name), zap.String("format", string(format)))
+}
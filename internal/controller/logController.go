@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"GoTimeTracker/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// LogLevelResponse текущий уровень журнала
+type LogLevelResponse struct {
+	Level string `json:"level" example:"info"`
+}
+
+// GetLogLevel godoc
+//
+//	@Summary		Уровень журнала
+//	@Description	Возвращает текущий уровень журнала. Доступно только администраторам
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{object}	LogLevelResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/logLevel [get]
+func GetLogLevel(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, LogLevelResponse{Level: logger.Level()})
+}
+
+// SetLogLevel godoc
+//
+//	@Summary		Изменение уровня журнала
+//	@Description	Меняет уровень журнала без перезапуска. Действует до перезапуска или SIGHUP,
+//	@Description	после которых уровень снова берется из LOG_LEVEL. Доступно только администраторам
+//	@Tags			admin
+//	@Produce		json
+//	@Param			level	query		string	true	"Уровень"	Enums(debug, info, warn, error)
+//	@Success		200		{object}	LogLevelResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/logLevel [put]
+func SetLogLevel(ctx *gin.Context) {
+	previous := logger.Level()
+	if err := logger.SetLevel(ctx.Query("level")); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	logger.Ctx(ctx.Request.Context()).Info("Уровень журнала изменен",
+		zap.String("from", previous), zap.String("to", logger.Level()))
+	ctx.JSON(http.StatusOK, LogLevelResponse{Level: logger.Level()})
+}
@@ -2,8 +2,13 @@ package controller
 
 import (
 	"GoTimeTracker/database"
+	"GoTimeTracker/internal/export"
+	"GoTimeTracker/internal/importer"
 	"GoTimeTracker/internal/model"
+	"GoTimeTracker/internal/service"
 	"GoTimeTracker/pkg/logger"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"net/http"
@@ -15,71 +20,101 @@ import (
 // GetAllPeople godoc
 //
 //	@Summary		Получить всех сотрудников
-//	@Description	Возвращает список всех сотрудников с возможностью фильтрации
+//	@Description	Возвращает список всех сотрудников с возможностью фильтрации.
+//	@Description	В форматах csv и xlsx выгружаются все подходящие сотрудники, страница не требуется
 //	@Tags			people
 //	@Accept			json
 //	@Produce		json
+//	@Produce		text/csv
+//	@Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 //
-//	@Param			page		query		int		true	"Страница"													example(0)
-//	@Param			page_size	query		int		true	"Количество объектов на странице"							example(5)
+//	@Param			page		query		int		false	"Страница (обязательна для json)"							example(0)
+//	@Param			page_size	query		int		false	"Количество объектов на странице (обязательно для json)"	example(5)
 //	@Param			filter		query		string	false	"Фильтр (название параметра и параметр через двоеточие)"	example(name:Иванов)
+//	@Param			format		query		string	false	"Формат ответа, по умолчанию по заголовку Accept"			Enums(json, csv, xlsx)
+//	@Param			lang		query		string	false	"Язык заголовков выгрузки"									Enums(ru, en)
+//	@Param			include_deleted	query	bool	false	"Включить удаленных сотрудников (только администраторы)"
 //
 //	@Success		200			{array}		model.People
 //	@Failure		400			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
 //	@Failure		500			{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Router			/allPeople [get]
 func GetAllPeople(ctx *gin.Context) {
+	format, err := export.Negotiate(ctx)
+	if err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при выборе формата ответа", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var filter database.PeopleFilter
+	if query := ctx.Query("filter"); len(query) != 0 {
+		params := strings.SplitN(query, ":", 2)
+		if len(params) != 2 {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверный формат фильтра"})
+			return
+		}
+		filter.Param, filter.Value = params[0], params[1]
+	}
+	if filter.IncludeDeleted, err = strconv.ParseBool(ctx.DefaultQuery("include_deleted", "false")); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение include_deleted"})
+		return
+	}
+
+	if format != export.JSON {
+		writeExport(ctx, format, "people", export.PeopleColumns, func(write func(values ...any) error) error {
+			return service.EachPeople(ctx.Request.Context(), actor(ctx), filter, func(p model.People) error {
+				return write(export.PeopleRow(p)...)
+			})
+		})
+		return
+	}
+
 	page := ctx.Query("page")
 	pageSize := ctx.Query("page_size")
 
 	pageValue, err := strconv.Atoi(page)
 	if err != nil {
-		logger.Error("Ошибка при парсинге значении страницы", zap.Error(err))
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при парсинге значении страницы", zap.Error(err))
 		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 	pageSizeValue, err := strconv.Atoi(pageSize)
 	if err != nil {
-		logger.Error("Ошибка при парсинге количества страниц", zap.Error(err))
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при парсинге количества страниц", zap.Error(err))
 		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	db, err := database.GetInstance()
+	people, err := service.GetAllPeople(ctx.Request.Context(), actor(ctx), pageValue, pageSizeValue, filter)
 	if err != nil {
-		logger.Error("Ошибка получения экземпляра базы данных", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
-		return
-	}
-
-	filter := ctx.Query("filter")
-	params := []string{"", ""}
-	if len(filter) != 0 {
-		params = strings.Split(filter, ":")
-	}
-
-	people, err := db.GetAllPeople(pageValue, pageSizeValue, params[0], params[1])
-	if err != nil {
-		logger.Error("Ошибка при получении списка сотрудников", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		serviceError(ctx, err, "Ошибка при получении списка сотрудников")
 		return
 	}
 
 	ctx.JSON(http.StatusOK, people)
-	logger.Info("Успешно получен список сотрудников")
+	logger.Ctx(ctx.Request.Context()).Info("Успешно получен список сотрудников")
 }
 
 // AddPeople godoc
 //
 //	@Summary		Добавить сотрудника
-//	@Description	Добавляет нового сотрудника по номеру паспорта
+//	@Description	Добавляет нового сотрудника по номеру паспорта, ФИО и адрес запрашиваются во внешнем API
 //	@Tags			people
 //	@Accept			json
 //	@Produce		json
 //	@Param			passportNumber	query	string	true	"Номер паспорта (серия и номер через пробел)"	example(1234 567890)
 //	@Success		200
 //	@Failure		400	{object}	ErrorResponse
+//	@Failure		409	{object}	DuplicateResponse	"Сотрудник с таким паспортом уже существует"
+//	@Failure		403	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
+//	@Failure		502	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Router			/people [post]
 func AddPeople(ctx *gin.Context) {
 	passportParam := ctx.Query("passportNumber")
@@ -89,95 +124,176 @@ func AddPeople(ctx *gin.Context) {
 		return
 	}
 
-	passportParts := strings.Split(passport, " ")
-	if len(passportParts) != 2 {
-		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверный формат номера паспорта"})
+	parsed, err := model.ParsePassport(passport)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	serie, err := strconv.Atoi(passportParts[0])
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверный формат серии паспорта"})
+	_, err = service.AddPeople(ctx.Request.Context(), actor(ctx), parsed)
+	var duplicate *database.DuplicatePassportError
+	if errors.As(err, &duplicate) {
+		duplicatePeople(ctx, duplicate)
+		return
+	}
+	var enrichmentErr *service.EnrichmentError
+	if errors.As(err, &enrichmentErr) {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при получении данных сотрудника из внешнего API", zap.Error(err))
+		ctx.JSON(http.StatusBadGateway, ErrorResponse{Error: err.Error()})
 		return
 	}
-
-	number, err := strconv.Atoi(passportParts[1])
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверный формат номера паспорта"})
+		serviceError(ctx, err, "Ошибка при добавлении сотрудника")
 		return
 	}
 
-	people := model.People{
-		PassportSerie:  serie,
-		PassportNumber: number,
+	ctx.JSON(http.StatusOK, nil)
+	logger.Ctx(ctx.Request.Context()).Info("Сотрудник успешно добавлен")
+}
+
+// DuplicateResponse ответ на попытку добавить сотрудника с уже занятым паспортом
+type DuplicateResponse struct {
+	Error    string `json:"error"`
+	PeopleId int    `json:"people_id"`
+}
+
+// duplicatePeople отвечает 409 со ссылкой на уже существующего сотрудника
+func duplicatePeople(ctx *gin.Context, duplicate *database.DuplicatePassportError) {
+	logger.Ctx(ctx.Request.Context()).Info("Попытка добавить сотрудника с существующим паспортом", zap.Int("peopleId", duplicate.PeopleId))
+	ctx.Header("Location", fmt.Sprintf("/allPeople?page=1&page_size=1&filter=id:%d", duplicate.PeopleId))
+	ctx.JSON(http.StatusConflict, DuplicateResponse{Error: duplicate.Error(), PeopleId: duplicate.PeopleId})
+}
+
+// ImportPeople godoc
+//
+//	@Summary		Импортировать сотрудников
+//	@Description	Добавляет сотрудников по списку паспортов из CSV (колонка passportNumber) или JSON
+//	@Description	([{"passportNumber": "1234 567890"}]) и возвращает результат по каждой строке.
+//	@Description	В режиме dry_run ничего не записывается. В режиме atomic сотрудники добавляются,
+//	@Description	только если ни одна строка не отклонена, иначе добавляются все корректные строки
+//	@Tags			people
+//	@Accept			json
+//	@Accept			text/csv
+//	@Produce		json
+//	@Param			file		body		string	true	"Список паспортов"
+//	@Param			format		query		string	false	"Формат файла, по умолчанию по Content-Type"	Enums(csv, json)
+//	@Param			dry_run		query		bool	false	"Только показать результат без записи"
+//	@Param			atomic		query		bool	false	"Все или ничего"
+//	@Param			concurrency	query		int		false	"Количество одновременных запросов к внешнему API"	default(4)	minimum(1)	maximum(32)
+//	@Success		200			{object}	importer.Report
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		413			{object}	ErrorResponse
+//	@Failure		422			{object}	importer.Report
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/peopleImport [post]
+func ImportPeople(ctx *gin.Context) {
+	format := ctx.Query("format")
+	if format == "" {
+		format = "json"
+		if strings.Contains(ctx.ContentType(), "csv") {
+			format = "csv"
+		}
+	}
+
+	var opts importer.Options
+	var err error
+	if opts.DryRun, err = strconv.ParseBool(ctx.DefaultQuery("dry_run", "false")); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение dry_run"})
+		return
+	}
+	if opts.Atomic, err = strconv.ParseBool(ctx.DefaultQuery("atomic", "false")); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение atomic"})
+		return
+	}
+	opts.Concurrency, err = strconv.Atoi(ctx.DefaultQuery("concurrency", strconv.Itoa(importer.DefaultConcurrency)))
+	if err != nil || opts.Concurrency < 1 || opts.Concurrency > importer.MaxConcurrency {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("concurrency должно быть от 1 до %d", importer.MaxConcurrency)})
+		return
 	}
 
-	db, err := database.GetInstance()
+	rows, err := importer.Parse(ctx.Request.Body, format)
 	if err != nil {
-		logger.Error("Ошибка получения экземпляра базы данных", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при разборе файла импорта", zap.Error(err))
+		bodyError(ctx, err)
+		return
+	}
+	if len(rows) == 0 {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Файл импорта не содержит строк"})
 		return
 	}
 
-	err = db.AddPeople(people)
+	report, err := service.ImportPeople(ctx.Request.Context(), actor(ctx), rows, opts)
 	if err != nil {
-		logger.Error("Ошибка при добавлении сотрудника", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		serviceError(ctx, err, "Ошибка при импорте сотрудников")
 		return
 	}
 
-	ctx.JSON(http.StatusOK, nil)
-	logger.Info("Сотрудник успешно добавлен")
+	if opts.Atomic && !opts.DryRun && !report.Committed {
+		ctx.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+	logger.Ctx(ctx.Request.Context()).Info("Импорт сотрудников выполнен")
 }
 
 // UpdatePeople godoc
 //
 //	@Summary		Обновить информацию о сотруднике
-//	@Description	Обновляет информацию о сотруднике
+//	@Description	Обновляет информацию о сотруднике. Руководителя (manager_id) может изменить только администратор
 //	@Tags			people
 //	@Accept			json
 //	@Produce		json
 //	@Param			people	body	model.People	true	"Информация о сотруднике (серия и номер не изменяются)"
 //	@Success		200
 //	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		413	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Router			/people [put]
 func UpdatePeople(ctx *gin.Context) {
 	var p model.People
 	if err := ctx.ShouldBindJSON(&p); err != nil {
-		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
-		return
-	}
-
-	db, err := database.GetInstance()
-	if err != nil {
-		logger.Error("Ошибка получения экземпляра базы данных", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		bodyError(ctx, err)
 		return
 	}
 
-	err = db.UpdatePeople(p)
+	err := service.UpdatePeople(ctx.Request.Context(), actor(ctx), p)
 	if err != nil {
-		logger.Error("Ошибка при обновлении информации о сотруднике", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		serviceError(ctx, err, "Ошибка при обновлении информации о сотруднике")
 		return
 	}
 
 	ctx.JSON(http.StatusOK, nil)
-	logger.Info("Информация о сотруднике успешно обновлена")
+	logger.Ctx(ctx.Request.Context()).Info("Информация о сотруднике успешно обновлена")
 }
 
 // DeletePeople godoc
 //
 //	@Summary		Удалить сотрудника
-//	@Description	Удаляет сотрудника по идентификатору
+//	@Description	Помечает сотрудника удаленным и останавливает его запущенные таймеры, время остается за ним.
+//	@Description	Задачи сотрудника: keep — остаются за ним, reassign — не начатые задачи передаются reassign_to,
+//	@Description	cascade — удаляются вместе с ним и восстанавливаются вместе с ним.
+//	@Description	Удаленные записи окончательно удаляются по истечении срока хранения
 //	@Tags			people
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	query	int	true	"Идентификатор сотрудника"
+//	@Param			id				query	int		true	"Идентификатор сотрудника"
+//	@Param			tasks			query	string	false	"Что сделать с задачами сотрудника"	Enums(keep, reassign, cascade)	default(keep)
+//	@Param			reassign_to		query	int		false	"Кому передать задачи (обязательно для reassign)"
+//	@Param			override_reason	query	string	false	"Причина изменения в закрытом периоде (администраторы)"
 //	@Success		200
 //	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Router			/people [delete]
 func DeletePeople(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Query("id"))
@@ -186,20 +302,116 @@ func DeletePeople(ctx *gin.Context) {
 		return
 	}
 
-	db, err := database.GetInstance()
+	policy := ctx.DefaultQuery("tasks", database.TasksKeep)
+	var reassignTo int
+	switch policy {
+	case database.TasksKeep, database.TasksCascade:
+	case database.TasksReassign:
+		reassignTo, err = strconv.Atoi(ctx.Query("reassign_to"))
+		if err != nil || reassignTo == id {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение reassign_to"})
+			return
+		}
+	default:
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неизвестное значение tasks"})
+		return
+	}
+
+	err = service.DeletePeople(overrideContext(ctx), actor(ctx), id, policy, reassignTo)
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при удалении информации о сотруднике")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, nil)
+	logger.Ctx(ctx.Request.Context()).Info("Информация о сотруднике успешно удалена")
+}
+
+// RestorePeople godoc
+//
+//	@Summary		Восстановить сотрудника
+//	@Description	Восстанавливает удаленного сотрудника вместе с задачами, удаленными вместе с ним.
+//	@Description	Доступно только администраторам
+//	@Tags			people
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				query	int		true	"Идентификатор сотрудника"
+//	@Param			override_reason	query	string	false	"Причина изменения в закрытом периоде (администраторы)"
+//	@Success		200
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/peopleRestore [put]
+func RestorePeople(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Query("id"))
 	if err != nil {
-		logger.Error("Ошибка получения экземпляра базы данных", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	err = db.DeletePeople(id)
+	err = service.RestorePeople(overrideContext(ctx), actor(ctx), id)
 	if err != nil {
-		logger.Error("Ошибка при удалении информации о сотруднике", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		serviceError(ctx, err, "Ошибка при восстановлении сотрудника")
 		return
 	}
 
 	ctx.JSON(http.StatusOK, nil)
-	logger.Info("Информация о сотруднике успешно удалена")
+	logger.Ctx(ctx.Request.Context()).Info("Сотрудник успешно восстановлен")
+}
+
+// OffboardPeople godoc
+//
+//	@Summary		Передать задачи уходящего сотрудника
+//	@Description	Останавливает запущенные таймеры сотрудника (время остается за ним) и передает его не начатые
+//	@Description	задачи сотрудникам из to по кругу. Выполняется в одной транзакции, каждая передача записывается
+//	@Description	в журнал изменений. В режиме preview изменения не сохраняются, ответ показывает, что было бы сделано
+//	@Tags			people
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				query		int		true	"Идентификатор уходящего сотрудника"
+//	@Param			to				query		string	true	"Получатели задач через запятую"	example(2,3)
+//	@Param			preview			query		bool	false	"Только показать результат без сохранения"
+//	@Param			override_reason	query		string	false	"Причина изменения в закрытом периоде (администраторы)"
+//	@Success		200				{object}	model.Offboarding
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		403				{object}	ErrorResponse
+//	@Failure		404				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/peopleOffboard [post]
+func OffboardPeople(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var recipients []int
+	for _, value := range strings.Split(ctx.Query("to"), ",") {
+		recipient, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || recipient == id {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверный список получателей задач"})
+			return
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	preview, err := strconv.ParseBool(ctx.DefaultQuery("preview", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение preview"})
+		return
+	}
+
+	result, err := service.OffboardPeople(overrideContext(ctx), actor(ctx), id, recipients, preview)
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при передаче задач сотрудника")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+	logger.Ctx(ctx.Request.Context()).Info("Задачи сотрудника переданы")
 }
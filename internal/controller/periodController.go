@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/internal/service"
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ClosePeriodRequest закрытие периода
+type ClosePeriodRequest struct {
+	// PeriodStart первый день периода
+	PeriodStart string `json:"period_start" binding:"required" example:"2024-06-01"`
+	// PeriodEnd день после последнего дня периода
+	PeriodEnd string `json:"period_end" binding:"required" example:"2024-07-01"`
+	Comment   string `json:"comment" example:"Закрытие июня"`
+}
+
+// overrideContext контекст запроса с причиной изменения в закрытом периоде из override_reason
+func overrideContext(ctx *gin.Context) context.Context {
+	reason := strings.TrimSpace(ctx.Query("override_reason"))
+	if reason == "" {
+		return ctx.Request.Context()
+	}
+	return service.WithOverride(ctx.Request.Context(), reason)
+}
+
+// GetClosedPeriods godoc
+//
+//	@Summary		Закрытые периоды
+//	@Description	Возвращает закрытые периоды, последние первыми. Доступно только администраторам
+//	@Tags			periods
+//	@Produce		json
+//	@Success		200	{array}		model.ClosedPeriod
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/closedPeriod [get]
+func GetClosedPeriods(ctx *gin.Context) {
+	periods, err := service.GetClosedPeriods(ctx.Request.Context(), actor(ctx))
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при получении закрытых периодов")
+		return
+	}
+	ctx.JSON(http.StatusOK, periods)
+}
+
+// ClosePeriod godoc
+//
+//	@Summary		Закрыть период
+//	@Description	Закрывает период [period_start, period_end). Время задач, пересекающееся с закрытым периодом,
+//	@Description	нельзя начать, завершить, удалить, восстановить или переназначить (423). Администратор может
+//	@Description	выполнить такое изменение, указав override_reason: причина попадает в журнал изменений и
+//	@Description	отчет /lockedEdits. Пока по задаче в периоде идет отсчет времени, период не закрывается (409).
+//	@Description	Доступно только администраторам
+//	@Tags			periods
+//	@Accept			json
+//	@Produce		json
+//	@Param			period	body		ClosePeriodRequest	true	"Период"
+//	@Success		200		{object}	model.ClosedPeriod
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		413		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/closedPeriod [post]
+func ClosePeriod(ctx *gin.Context) {
+	var req ClosePeriodRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		bodyError(ctx, err)
+		return
+	}
+	start, err := time.Parse(dateLayout, req.PeriodStart)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение period_start"})
+		return
+	}
+	end, err := time.Parse(dateLayout, req.PeriodEnd)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение period_end"})
+		return
+	}
+	if !start.Before(end) {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Начало периода должно быть раньше конца"})
+		return
+	}
+	var comment *string
+	if c := strings.TrimSpace(req.Comment); c != "" {
+		comment = &c
+	}
+
+	period, err := service.ClosePeriod(ctx.Request.Context(), actor(ctx), start, end, comment)
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при закрытии периода")
+		return
+	}
+	ctx.JSON(http.StatusOK, period)
+}
+
+// ReopenPeriod godoc
+//
+//	@Summary		Открыть период
+//	@Description	Снимает закрытие периода. Доступно только администраторам
+//	@Tags			periods
+//	@Produce		json
+//	@Param			id	query	int	true	"Идентификатор закрытого периода"
+//	@Success		200
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/closedPeriod [delete]
+func ReopenPeriod(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err = service.ReopenPeriod(ctx.Request.Context(), actor(ctx), id); err != nil {
+		serviceError(ctx, err, "Ошибка при открытии периода")
+		return
+	}
+	ctx.JSON(http.StatusOK, nil)
+}
+
+// GetLockedEdits godoc
+//
+//	@Summary		Попытки изменить заблокированное время
+//	@Description	Отчет о попытках изменить время в закрытых периодах (reason=closed) и утвержденных табелях
+//	@Description	(reason=timesheet): отклоненных и выполненных администратором с указанием причины (overridden).
+//	@Description	Новые попытки первыми. Доступно только администраторам
+//	@Tags			periods
+//	@Produce		json
+//	@Param			page		query		int		true	"Страница"							example(1)
+//	@Param			page_size	query		int		true	"Количество записей на странице"	example(20)
+//	@Param			reason		query		string	false	"Причина блокировки"				Enums(closed, timesheet)
+//	@Param			from		query		string	false	"Начало периода (RFC 3339)"			example(2024-07-01T00:00:00Z)
+//	@Param			to			query		string	false	"Конец периода (RFC 3339, не включая)"
+//	@Success		200			{array}		model.LockedEdit
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/lockedEdits [get]
+func GetLockedEdits(ctx *gin.Context) {
+	page, err := strconv.Atoi(ctx.Query("page"))
+	if err != nil || page < 1 {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение page"})
+		return
+	}
+	pageSize, err := strconv.Atoi(ctx.Query("page_size"))
+	if err != nil || pageSize < 1 {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение page_size"})
+		return
+	}
+	reason := ctx.Query("reason")
+	if reason != "" && reason != model.LockClosed && reason != model.LockTimesheet {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неизвестное значение reason"})
+		return
+	}
+	var from, to *time.Time
+	for param, bound := range map[string]**time.Time{"from": &from, "to": &to} {
+		value := ctx.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение " + param})
+			return
+		}
+		*bound = &t
+	}
+
+	edits, err := service.GetLockedEdits(ctx.Request.Context(), actor(ctx), page, pageSize, reason, from, to)
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при получении попыток изменения")
+		return
+	}
+	ctx.JSON(http.StatusOK, edits)
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"GoTimeTracker/internal/export"
+	"GoTimeTracker/internal/service"
+	"GoTimeTracker/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// GetEstimateReport godoc
+//
+//	@Summary		Отчет по оценкам
+//	@Description	Сравнивает оценку и фактическое время завершенных задач по задачам, сотрудникам или проектам
+//	@Tags			reports
+//	@Accept			json
+//	@Produce		json
+//	@Produce		text/csv
+//	@Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+//
+//	@Param			group_by	query		string	false	"Группировка"										Enums(task, people, project)	default(task)
+//	@Param			format		query		string	false	"Формат ответа, по умолчанию по заголовку Accept"	Enums(json, csv, xlsx)
+//	@Param			lang		query		string	false	"Язык заголовков выгрузки"							Enums(ru, en)
+//
+//	@Success		200			{array}		model.EstimateReport
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/estimateReport [get]
+func GetEstimateReport(ctx *gin.Context) {
+	groupBy := ctx.DefaultQuery("group_by", "task")
+	if groupBy != "task" && groupBy != "people" && groupBy != "project" {
+		logger.Ctx(ctx.Request.Context()).Error("Неизвестная группировка отчета", zap.String("groupBy", groupBy))
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неизвестная группировка отчета"})
+		return
+	}
+
+	format, err := export.Negotiate(ctx)
+	if err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при выборе формата ответа", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	report, err := service.GetEstimateReport(ctx.Request.Context(), actor(ctx), groupBy)
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при построении отчета по оценкам")
+		return
+	}
+
+	if format != export.JSON {
+		writeExport(ctx, format, "estimate_report", export.EstimateReportColumns, func(write func(values ...any) error) error {
+			for _, r := range report {
+				if err := write(export.EstimateReportRow(r)...); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+	logger.Ctx(ctx.Request.Context()).Info("Успешно построен отчет по оценкам")
+}
@@ -1,8 +1,11 @@
 package controller
 
 import (
-	"GoTimeTracker/database"
+	"GoTimeTracker/internal/export"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/internal/service"
 	"GoTimeTracker/pkg/logger"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"net/http"
@@ -13,6 +16,21 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// parseEstimate разбирает необязательную оценку задачи в минутах
+func parseEstimate(value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	estimate, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	if estimate <= 0 {
+		return nil, fmt.Errorf("оценка задачи должна быть положительной")
+	}
+	return &estimate, nil
+}
+
 // AddTask godoc
 //
 //	@Summary		Добавить задачу
@@ -21,33 +39,43 @@ type ErrorResponse struct {
 //	@Accept			json
 //	@Produce		json
 //
-//	@Param			name		query	string	true	"Название задачи"	example(Новая задача)
-//	@Param			description	query	string	true	"Описание задачи"	example(Описание...)
+//	@Param			name				query	string	true	"Название задачи"			example(Новая задача)
+//	@Param			description			query	string	true	"Описание задачи"			example(Описание...)
+//	@Param			project				query	string	false	"Проект"					example(Трекер)
+//	@Param			estimate_minutes	query	int		false	"Оценка задачи в минутах"	example(90)
 //
 //	@Success		200
 //	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Router			/task [post]
 func AddTask(ctx *gin.Context) {
-	name := ctx.Query("name")
-	description := ctx.Query("description")
+	task := model.Task{
+		Name:        ctx.Query("name"),
+		Description: ctx.Query("description"),
+	}
+	if project := ctx.Query("project"); project != "" {
+		task.Project = &project
+	}
 
-	db, err := database.GetInstance()
+	estimate, err := parseEstimate(ctx.Query("estimate_minutes"))
 	if err != nil {
-		logger.Error("Ошибка получения экземпляра базы данных", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при парсинге estimate_minutes", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
+	task.EstimateMinutes = estimate
 
-	err = db.AddTask(name, description)
+	_, err = service.AddTask(ctx.Request.Context(), actor(ctx), task)
 	if err != nil {
-		logger.Error("Ошибка при добавлении задачи", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		serviceError(ctx, err, "Ошибка при добавлении задачи")
 		return
 	}
 
 	ctx.JSON(http.StatusOK, nil)
-	logger.Info("Задача успешно добавлена")
+	logger.Ctx(ctx.Request.Context()).Info("Задача успешно добавлена")
 }
 
 // AssignPeopleOnTask godoc
@@ -58,12 +86,18 @@ func AddTask(ctx *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //
-//	@Param			id			query	int	true	"Идентификатор задачи"		example(0)
-//	@Param			people_id	query	int	true	"Идентификатор работника"	example(0)
+//	@Param			id				query	int		true	"Идентификатор задачи"								example(0)
+//	@Param			people_id		query	int		true	"Идентификатор работника"							example(0)
+//	@Param			override_reason	query	string	false	"Причина изменения в закрытом периоде (администраторы)"
 //
 //	@Success		200
 //	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		423	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Router			/taskAssign [put]
 func AssignPeopleOnTask(ctx *gin.Context) {
 	id := ctx.Query("id")
@@ -71,33 +105,70 @@ func AssignPeopleOnTask(ctx *gin.Context) {
 
 	idValue, err := strconv.Atoi(id)
 	if err != nil {
-		logger.Error("Ошибка при парсинге id", zap.Error(err))
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при парсинге id", zap.Error(err))
 		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 	peopleIdValue, err := strconv.Atoi(peopleId)
 	if err != nil {
-		logger.Error("Ошибка при парсинге people_id", zap.Error(err))
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при парсинге people_id", zap.Error(err))
 		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	db, err := database.GetInstance()
+	err = service.AssignPeopleOnTask(overrideContext(ctx), actor(ctx), idValue, peopleIdValue)
 	if err != nil {
-		logger.Error("Ошибка получения экземпляра базы данных", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		serviceError(ctx, err, "Ошибка при назначении сотрудников на задачу")
 		return
 	}
 
-	err = db.AssignPeopleOnTask(idValue, peopleIdValue)
+	ctx.JSON(http.StatusOK, nil)
+	logger.Ctx(ctx.Request.Context()).Info("Сотрудники успешно назначены на задачу")
+}
+
+// SetTaskEstimate godoc
+//
+//	@Summary		Оценить задачу
+//	@Description	Устанавливает оценку задачи в минутах, без параметра estimate_minutes оценка сбрасывается
+//	@Tags			tasks
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			id					query	int	true	"Идентификатор задачи"		example(0)
+//	@Param			estimate_minutes	query	int	false	"Оценка задачи в минутах"	example(90)
+//
+//	@Success		200
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/taskEstimate [put]
+func SetTaskEstimate(ctx *gin.Context) {
+	id := ctx.Query("id")
+
+	idValue, err := strconv.Atoi(id)
+	if err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при парсинге id", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	estimate, err := parseEstimate(ctx.Query("estimate_minutes"))
+	if err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при парсинге estimate_minutes", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = service.SetTaskEstimate(ctx.Request.Context(), actor(ctx), idValue, estimate)
 	if err != nil {
-		logger.Error("Ошибка при назначении сотрудников на задачу", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		serviceError(ctx, err, "Ошибка при обновлении оценки задачи")
 		return
 	}
 
 	ctx.JSON(http.StatusOK, nil)
-	logger.Info("Сотрудники успешно назначены на задачу")
+	logger.Ctx(ctx.Request.Context()).Info("Оценка задачи успешно обновлена")
 }
 
 // StartTask godoc
@@ -108,38 +179,36 @@ func AssignPeopleOnTask(ctx *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //
-//	@Param			id	query	int	true	"Идентификатор задачи"	example(0)
+//	@Param			id				query	int		true	"Идентификатор задачи"	example(0)
+//	@Param			override_reason	query	string	false	"Причина изменения в закрытом периоде (администраторы)"
 //
 //	@Success		200
 //	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		423	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Router			/taskStart [put]
 func StartTask(ctx *gin.Context) {
 	id := ctx.Query("id")
 
 	idValue, err := strconv.Atoi(id)
 	if err != nil {
-		logger.Error("Ошибка при парсинге id", zap.Error(err))
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при парсинге id", zap.Error(err))
 		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	db, err := database.GetInstance()
-	if err != nil {
-		logger.Error("Ошибка получения экземпляра базы данных", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
-		return
-	}
-
-	err = db.StartTaskTime(idValue)
+	err = service.StartTask(overrideContext(ctx), actor(ctx), idValue)
 	if err != nil {
-		logger.Error("Ошибка при начале отслеживания времени задачи", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		serviceError(ctx, err, "Ошибка при начале отслеживания времени задачи")
 		return
 	}
 
 	ctx.JSON(http.StatusOK, nil)
-	logger.Info("Время начала задачи успешно обновлено")
+	logger.Ctx(ctx.Request.Context()).Info("Время начала задачи успешно обновлено")
 }
 
 // EndTask godoc
@@ -150,38 +219,112 @@ func StartTask(ctx *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //
-//	@Param			id	query	int	true	"Идентификатор задачи"	example(0)
+//	@Param			id				query	int		true	"Идентификатор задачи"	example(0)
+//	@Param			override_reason	query	string	false	"Причина изменения в закрытом периоде (администраторы)"
 //
 //	@Success		200
 //	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		423	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Router			/taskEnd [put]
 func EndTask(ctx *gin.Context) {
 	id := ctx.Query("id")
 
 	idValue, err := strconv.Atoi(id)
 	if err != nil {
-		logger.Error("Ошибка при парсинге id", zap.Error(err))
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при парсинге id", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = service.EndTask(overrideContext(ctx), actor(ctx), idValue)
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при завершении отслеживания времени задачи")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, nil)
+	logger.Ctx(ctx.Request.Context()).Info("Время завершения задачи успешно обновлено")
+}
+
+// DeleteTask godoc
+//
+//	@Summary		Удалить задачу
+//	@Description	Помечает задачу удаленной. Удаленные задачи окончательно удаляются по истечении срока хранения
+//	@Tags			tasks
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			id				query	int		true	"Идентификатор задачи"	example(0)
+//	@Param			override_reason	query	string	false	"Причина изменения в закрытом периоде (администраторы)"
+//
+//	@Success		200
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		423	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/task [delete]
+func DeleteTask(ctx *gin.Context) {
+	idValue, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при парсинге id", zap.Error(err))
 		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	db, err := database.GetInstance()
+	err = service.DeleteTask(overrideContext(ctx), actor(ctx), idValue)
 	if err != nil {
-		logger.Error("Ошибка получения экземпляра базы данных", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		serviceError(ctx, err, "Ошибка при удалении задачи")
 		return
 	}
 
-	err = db.EndTaskTime(idValue)
+	ctx.JSON(http.StatusOK, nil)
+	logger.Ctx(ctx.Request.Context()).Info("Задача успешно удалена")
+}
+
+// RestoreTask godoc
+//
+//	@Summary		Восстановить задачу
+//	@Description	Восстанавливает удаленную задачу. Доступно только администраторам
+//	@Tags			tasks
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			id				query	int		true	"Идентификатор задачи"	example(0)
+//	@Param			override_reason	query	string	false	"Причина изменения в закрытом периоде (администраторы)"
+//
+//	@Success		200
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		423	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/taskRestore [put]
+func RestoreTask(ctx *gin.Context) {
+	idValue, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при парсинге id", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = service.RestoreTask(overrideContext(ctx), actor(ctx), idValue)
 	if err != nil {
-		logger.Error("Ошибка при завершении отслеживания времени задачи", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		serviceError(ctx, err, "Ошибка при восстановлении задачи")
 		return
 	}
 
 	ctx.JSON(http.StatusOK, nil)
-	logger.Info("Время завершения задачи успешно обновлено")
+	logger.Ctx(ctx.Request.Context()).Info("Задача успешно восстановлена")
 }
 
 // GetTasks godoc
@@ -191,37 +334,58 @@ func EndTask(ctx *gin.Context) {
 //	@Tags			tasks
 //	@Accept			json
 //	@Produce		json
+//	@Produce		text/csv
+//	@Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 //
-//	@Param			people_id	query		int	true	"Идентификатор работника"	example(0)
+//	@Param			people_id	query		int		true	"Идентификатор работника"							example(0)
+//	@Param			format		query		string	false	"Формат ответа, по умолчанию по заголовку Accept"	Enums(json, csv, xlsx)
+//	@Param			lang		query		string	false	"Язык заголовков выгрузки"							Enums(ru, en)
+//	@Param			include_deleted	query	bool	false	"Включить удаленные задачи (только администраторы)"
 //
 //	@Success		200			{array}		model.Task
 //	@Failure		400			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
 //	@Failure		500			{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Router			/task [get]
 func GetTasks(ctx *gin.Context) {
 	peopleId := ctx.Query("people_id")
 
 	peopleIdValue, err := strconv.Atoi(peopleId)
 	if err != nil {
-		logger.Error("Ошибка при парсинге people_id", zap.Error(err))
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при парсинге people_id", zap.Error(err))
 		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	db, err := database.GetInstance()
+	includeDeleted, err := strconv.ParseBool(ctx.DefaultQuery("include_deleted", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение include_deleted"})
+		return
+	}
+
+	format, err := export.Negotiate(ctx)
 	if err != nil {
-		logger.Error("Ошибка получения экземпляра базы данных", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка при выборе формата ответа", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if format != export.JSON {
+		writeExport(ctx, format, "tasks", export.TaskColumns, func(write func(values ...any) error) error {
+			return service.EachPeopleTask(ctx.Request.Context(), actor(ctx), peopleIdValue, includeDeleted, func(t model.Task) error {
+				return write(export.TaskRow(t)...)
+			})
+		})
 		return
 	}
 
-	tasks, err := db.GetPeopleTasks(peopleIdValue)
+	tasks, err := service.GetPeopleTasks(ctx.Request.Context(), actor(ctx), peopleIdValue, includeDeleted)
 	if err != nil {
-		logger.Error("Ошибка при получении задач для сотрудника", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		serviceError(ctx, err, "Ошибка при получении задач для сотрудника")
 		return
 	}
 
 	ctx.JSON(http.StatusOK, tasks)
-	logger.Info("Успешно получен список задач для сотрудника")
+	logger.Ctx(ctx.Request.Context()).Info("Успешно получен список задач для сотрудника")
 }
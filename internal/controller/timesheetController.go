@@ -0,0 +1,291 @@
+package controller
+
+import (
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/internal/service"
+	"GoTimeTracker/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayout формат дня в параметрах табеля
+const dateLayout = "2006-01-02"
+
+// TimesheetRejectRequest отклонение табеля
+type TimesheetRejectRequest struct {
+	// Comment причина отклонения, которую увидит сотрудник
+	Comment string `json:"comment" binding:"required" example:"Не указано время по задаче 12"`
+}
+
+// timesheetPeriod читает параметры period и date. Без date берется текущий день
+func timesheetPeriod(ctx *gin.Context) (string, time.Time, bool) {
+	period := ctx.DefaultQuery("period", model.TimesheetWeek)
+	if period != model.TimesheetWeek && period != model.TimesheetMonth {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неизвестное значение period"})
+		return "", time.Time{}, false
+	}
+	value := ctx.Query("date")
+	if value == "" {
+		now := time.Now()
+		return period, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), true
+	}
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение date"})
+		return "", time.Time{}, false
+	}
+	return period, date, true
+}
+
+// timesheetPeople читает people_id. Без него берется сотрудник, связанный с пользователем
+func timesheetPeople(ctx *gin.Context) (int, bool) {
+	value := ctx.Query("people_id")
+	if value == "" {
+		if identity := actor(ctx); identity.PeopleId != nil {
+			return *identity.PeopleId, true
+		}
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Не указан people_id"})
+		return 0, false
+	}
+	peopleId, err := strconv.Atoi(value)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение people_id"})
+		return 0, false
+	}
+	return peopleId, true
+}
+
+// GetTimesheet godoc
+//
+//	@Summary		Табель сотрудника
+//	@Description	Возвращает табель за неделю (с понедельника) или календарный месяц, содержащий день date,
+//	@Description	с отрезками времени сотрудника в периоде. Не отправлявшийся табель возвращается черновиком с id 0
+//	@Tags			timesheets
+//	@Produce		json
+//	@Param			people_id	query		int		false	"Идентификатор работника, по умолчанию свой"
+//	@Param			period		query		string	false	"Период"							Enums(week, month)	default(week)
+//	@Param			date		query		string	false	"День периода, по умолчанию сегодня"	example(2024-07-03)
+//	@Success		200			{object}	model.Timesheet
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/timesheet [get]
+func GetTimesheet(ctx *gin.Context) {
+	peopleId, ok := timesheetPeople(ctx)
+	if !ok {
+		return
+	}
+	period, date, ok := timesheetPeriod(ctx)
+	if !ok {
+		return
+	}
+
+	ts, err := service.GetTimesheet(ctx.Request.Context(), actor(ctx), peopleId, period, date)
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при получении табеля")
+		return
+	}
+	ctx.JSON(http.StatusOK, ts)
+}
+
+// GetTimesheets godoc
+//
+//	@Summary		Отправленные табели
+//	@Description	Возвращает табели доступных сотрудников, новые периоды первыми. Руководитель видит
+//	@Description	табели своей команды, например ожидающие утверждения со status=submitted
+//	@Tags			timesheets
+//	@Produce		json
+//	@Param			status	query		string	false	"Состояние табеля"						Enums(draft, submitted, approved, rejected)
+//	@Param			from	query		string	false	"Начало периода не раньше дня"			example(2024-07-01)
+//	@Param			to		query		string	false	"Начало периода раньше дня"
+//	@Success		200		{array}		model.Timesheet
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/timesheets [get]
+func GetTimesheets(ctx *gin.Context) {
+	status := ctx.Query("status")
+	statuses := []string{model.TimesheetDraft, model.TimesheetSubmitted, model.TimesheetApproved, model.TimesheetRejected}
+	if status != "" && !slices.Contains(statuses, status) {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неизвестное значение status"})
+		return
+	}
+	var from, to *time.Time
+	for param, bound := range map[string]**time.Time{"from": &from, "to": &to} {
+		value := ctx.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(dateLayout, value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение " + param})
+			return
+		}
+		*bound = &t
+	}
+
+	timesheets, err := service.GetTimesheets(ctx.Request.Context(), actor(ctx), status, from, to)
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при получении табелей")
+		return
+	}
+	ctx.JSON(http.StatusOK, timesheets)
+}
+
+// SubmitTimesheet godoc
+//
+//	@Summary		Отправить табель на утверждение
+//	@Description	Отправляет табель за период, содержащий день date, сохраняя время сотрудника на момент
+//	@Description	отправки. Отправить можно черновик или отклоненный табель, иначе 409. Будущий период
+//	@Description	отправить нельзя
+//	@Tags			timesheets
+//	@Produce		json
+//	@Param			people_id	query		int		false	"Идентификатор работника, по умолчанию свой"
+//	@Param			period		query		string	false	"Период"							Enums(week, month)	default(week)
+//	@Param			date		query		string	false	"День периода, по умолчанию сегодня"	example(2024-07-03)
+//	@Success		200			{object}	model.Timesheet
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/timesheetSubmit [put]
+func SubmitTimesheet(ctx *gin.Context) {
+	peopleId, ok := timesheetPeople(ctx)
+	if !ok {
+		return
+	}
+	period, date, ok := timesheetPeriod(ctx)
+	if !ok {
+		return
+	}
+	if start, _, _ := model.PeriodBounds(period, date); start.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Период табеля еще не начался"})
+		return
+	}
+
+	ts, err := service.SubmitTimesheet(ctx.Request.Context(), actor(ctx), peopleId, period, date)
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при отправке табеля")
+		return
+	}
+	ctx.JSON(http.StatusOK, ts)
+}
+
+// ApproveTimesheet godoc
+//
+//	@Summary		Утвердить табель
+//	@Description	Утверждает отправленный табель. Пока табель утвержден, время сотрудника в периоде нельзя
+//	@Description	начать, завершить, удалить или переназначить (423). Свой табель руководитель не утверждает.
+//	@Description	Пока по задаче сотрудника в периоде идет отсчет времени, табель не утверждается (409)
+//	@Tags			timesheets
+//	@Produce		json
+//	@Param			id	query	int	true	"Идентификатор табеля"
+//	@Success		200
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/timesheetApprove [put]
+func ApproveTimesheet(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err = service.ApproveTimesheet(ctx.Request.Context(), actor(ctx), id); err != nil {
+		serviceError(ctx, err, "Ошибка при утверждении табеля")
+		return
+	}
+	ctx.JSON(http.StatusOK, nil)
+	logger.Ctx(ctx.Request.Context()).Info("Табель утвержден", zap.Int("id", id))
+}
+
+// RejectTimesheet godoc
+//
+//	@Summary		Отклонить табель
+//	@Description	Отклоняет отправленный табель с комментарием. Сотрудник может исправить время и отправить табель снова
+//	@Tags			timesheets
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		query	int						true	"Идентификатор табеля"
+//	@Param			reject	body	TimesheetRejectRequest	true	"Причина отклонения"
+//	@Success		200
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Failure		413	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/timesheetReject [put]
+func RejectTimesheet(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	var req TimesheetRejectRequest
+	if err = ctx.ShouldBindJSON(&req); err != nil {
+		bodyError(ctx, err)
+		return
+	}
+	comment := strings.TrimSpace(req.Comment)
+	if comment == "" {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Укажите причину отклонения"})
+		return
+	}
+
+	if err = service.RejectTimesheet(ctx.Request.Context(), actor(ctx), id, comment); err != nil {
+		serviceError(ctx, err, "Ошибка при отклонении табеля")
+		return
+	}
+	ctx.JSON(http.StatusOK, nil)
+	logger.Ctx(ctx.Request.Context()).Info("Табель отклонен", zap.Int("id", id))
+}
+
+// ReopenTimesheet godoc
+//
+//	@Summary		Вернуть табель
+//	@Description	Возвращает утвержденный или отклоненный табель в черновик и снимает блокировку времени в периоде
+//	@Tags			timesheets
+//	@Produce		json
+//	@Param			id	query	int	true	"Идентификатор табеля"
+//	@Success		200
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/timesheetReopen [put]
+func ReopenTimesheet(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err = service.ReopenTimesheet(ctx.Request.Context(), actor(ctx), id); err != nil {
+		serviceError(ctx, err, "Ошибка при возврате табеля")
+		return
+	}
+	ctx.JSON(http.StatusOK, nil)
+	logger.Ctx(ctx.Request.Context()).Info("Табель возвращен в черновик", zap.Int("id", id))
+}
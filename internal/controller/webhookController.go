@@ -0,0 +1,256 @@
+package controller
+
+import (
+	"GoTimeTracker/internal/events"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/internal/service"
+	"GoTimeTracker/internal/webhooks"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// WebhookRequest подписка на вебхуки
+type WebhookRequest struct {
+	URL string `json:"url" binding:"required" example:"https://example.com/hooks/tracker"`
+	// Secret ключ подписи. При создании генерируется, если не задан, при изменении пустой оставляет прежний
+	Secret string `json:"secret"`
+	// EventTypes типы событий, пустой список означает все события
+	EventTypes []string `json:"event_types" example:"task.assigned,timer.stopped"`
+}
+
+// WebhookUpdateRequest изменение подписки
+type WebhookUpdateRequest struct {
+	Id int `json:"id" binding:"required"`
+	WebhookRequest
+	// Active включение подписки сбрасывает счетчик неудачных доставок
+	Active *bool `json:"active" binding:"required"`
+}
+
+// webhook проверяет адрес и типы событий подписки. Адреса во внутренней сети отклоняются
+func (r WebhookRequest) webhook(ctx context.Context) (model.Webhook, string) {
+	if err := webhooks.CheckTarget(ctx, r.URL); err != nil {
+		return model.Webhook{}, err.Error()
+	}
+	for _, eventType := range r.EventTypes {
+		if !slices.Contains(events.DomainTypes, eventType) {
+			return model.Webhook{}, "Неизвестный тип события " + eventType
+		}
+	}
+	return model.Webhook{URL: r.URL, Secret: r.Secret, EventTypes: r.EventTypes}, ""
+}
+
+// GetWebhooks godoc
+//
+//	@Summary		Подписки на вебхуки
+//	@Description	Возвращает подписки без секретов. Доступно только администраторам
+//	@Tags			webhook
+//	@Produce		json
+//	@Success		200	{array}		model.Webhook
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/webhook [get]
+func GetWebhooks(ctx *gin.Context) {
+	webhooks, err := service.GetWebhooks(ctx.Request.Context(), actor(ctx))
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при получении подписок")
+		return
+	}
+	ctx.JSON(http.StatusOK, webhooks)
+}
+
+// AddWebhook godoc
+//
+//	@Summary		Добавить подписку на вебхуки
+//	@Description	Доменные события отправляются POST-запросом на url. Тело — JSON события: id, type, at,
+//	@Description	people_id, project и data — запись сотрудника или задачи после изменения. Заголовок
+//	@Description	X-Tracker-Signature содержит "t=<unix-время>,v1=<hex>", где hex — HMAC-SHA256 от
+//	@Description	"<unix-время>.<тело>" с секретом подписки (проверка — pkg/webhook.Verify), X-Tracker-Event —
+//	@Description	тип события, X-Tracker-Delivery — идентификатор доставки. Ответ не 2xx повторяется с
+//	@Description	растущей задержкой, после WEBHOOK_DISABLE_AFTER неудач подряд подписка отключается.
+//	@Description	Адреса во внутренней сети (loopback, частные, link-local) не принимаются.
+//	@Description	Секрет возвращается только в этом ответе. Доступно только администраторам
+//	@Tags			webhook
+//	@Accept			json
+//	@Produce		json
+//	@Param			webhook	body		WebhookRequest	true	"Подписка"
+//	@Success		200		{object}	model.Webhook
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		413		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/webhook [post]
+func AddWebhook(ctx *gin.Context) {
+	var req WebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		bodyError(ctx, err)
+		return
+	}
+	w, invalid := req.webhook(ctx.Request.Context())
+	if invalid != "" {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: invalid})
+		return
+	}
+
+	w, err := service.AddWebhook(ctx.Request.Context(), actor(ctx), w)
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при добавлении подписки")
+		return
+	}
+	ctx.JSON(http.StatusOK, w)
+}
+
+// UpdateWebhook godoc
+//
+//	@Summary		Изменить подписку на вебхуки
+//	@Description	Меняет адрес, типы событий, секрет и активность подписки. Доступно только администраторам
+//	@Tags			webhook
+//	@Accept			json
+//	@Produce		json
+//	@Param			webhook	body	WebhookUpdateRequest	true	"Подписка"
+//	@Success		200
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		413	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/webhook [put]
+func UpdateWebhook(ctx *gin.Context) {
+	var req WebhookUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		bodyError(ctx, err)
+		return
+	}
+	w, invalid := req.webhook(ctx.Request.Context())
+	if invalid != "" {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: invalid})
+		return
+	}
+	w.Id, w.Active = req.Id, *req.Active
+
+	if err := service.UpdateWebhook(ctx.Request.Context(), actor(ctx), w); err != nil {
+		serviceError(ctx, err, "Ошибка при обновлении подписки")
+		return
+	}
+	ctx.JSON(http.StatusOK, nil)
+}
+
+// DeleteWebhook godoc
+//
+//	@Summary		Удалить подписку на вебхуки
+//	@Description	Удаляет подписку вместе с журналом доставок. Доступно только администраторам
+//	@Tags			webhook
+//	@Produce		json
+//	@Param			id	query	int	true	"Идентификатор подписки"
+//	@Success		200
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/webhook [delete]
+func DeleteWebhook(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err = service.DeleteWebhook(ctx.Request.Context(), actor(ctx), id); err != nil {
+		serviceError(ctx, err, "Ошибка при удалении подписки")
+		return
+	}
+	ctx.JSON(http.StatusOK, nil)
+}
+
+// GetWebhookDeliveries godoc
+//
+//	@Summary		Журнал доставок вебхука
+//	@Description	Возвращает доставки подписки с результатом последней попытки, новые первыми.
+//	@Description	Доступно только администраторам
+//	@Tags			webhook
+//	@Produce		json
+//	@Param			webhook_id	query		int		true	"Идентификатор подписки"
+//	@Param			status		query		string	false	"Состояние доставки"				Enums(pending, delivered, failed)
+//	@Param			page		query		int		true	"Страница"							example(1)
+//	@Param			page_size	query		int		true	"Количество записей на странице"	example(20)
+//	@Success		200			{array}		model.WebhookDelivery
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/webhookDeliveries [get]
+func GetWebhookDeliveries(ctx *gin.Context) {
+	webhookId, err := strconv.Atoi(ctx.Query("webhook_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение webhook_id"})
+		return
+	}
+	page, err := strconv.Atoi(ctx.Query("page"))
+	if err != nil || page < 1 {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение page"})
+		return
+	}
+	pageSize, err := strconv.Atoi(ctx.Query("page_size"))
+	if err != nil || pageSize < 1 {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неверное значение page_size"})
+		return
+	}
+	status := ctx.Query("status")
+	if status != "" && !slices.Contains([]string{model.DeliveryPending, model.DeliveryDelivered, model.DeliveryFailed}, status) {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Неизвестное значение status"})
+		return
+	}
+
+	deliveries, err := service.GetWebhookDeliveries(ctx.Request.Context(), actor(ctx), webhookId, status, page, pageSize)
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при получении журнала доставок")
+		return
+	}
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+// RedeliverWebhook godoc
+//
+//	@Summary		Повторить доставку вебхука
+//	@Description	Ставит событие доставки в очередь повторно новой доставкой, в том числе успешной или
+//	@Description	исчерпавшей попытки. Отключенной подписке доставка уйдет после ее включения.
+//	@Description	Доступно только администраторам
+//	@Tags			webhook
+//	@Produce		json
+//	@Param			id	query		int	true	"Идентификатор доставки"
+//	@Success		200	{object}	model.WebhookDelivery
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/webhookRedeliver [post]
+func RedeliverWebhook(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Query("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	delivery, err := service.RedeliverWebhook(ctx.Request.Context(), actor(ctx), id)
+	if err != nil {
+		serviceError(ctx, err, "Ошибка при повторной доставке")
+		return
+	}
+	logger.Ctx(ctx.Request.Context()).Info("Доставка вебхука поставлена в очередь повторно", zap.Int64("id", delivery.Id))
+	ctx.JSON(http.StatusOK, delivery)
+}
@@ -0,0 +1,244 @@
+package encryption
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// envelopePrefix версия формата зашифрованного значения
+const envelopePrefix = "v1"
+
+// keySize размер мастер-ключей, ключа данных и ключа слепого индекса (AES-256)
+const keySize = 32
+
+// Keyring набор мастер-ключей для конвертного шифрования и ключ слепого индекса.
+//
+// Каждое значение шифруется собственным случайным ключом данных (AES-256-GCM), а ключ данных
+// шифруется текущим мастер-ключом и хранится рядом с шифротекстом:
+//
+//	v1:<id мастер-ключа>:<зашифрованный ключ данных>:<шифротекст>
+//
+// Старые мастер-ключи остаются в наборе только для расшифровки, пока значения не будут
+// перешифрованы текущим ключом. Слепой индекс — HMAC-SHA256 от значения, он не зависит
+// от мастер-ключей и позволяет искать по точному совпадению и проверять уникальность
+type Keyring struct {
+	keys    map[string][]byte
+	current string
+	index   []byte
+}
+
+// NewKeyring создает набор ключей. current — идентификатор ключа для шифрования новых значений
+func NewKeyring(keys map[string][]byte, current string, index []byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("не задано ни одного ключа шифрования паспортов")
+	}
+	for id, key := range keys {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("ключ %s должен быть длиной %d байт", id, keySize)
+		}
+		if strings.Contains(id, ":") {
+			return nil, fmt.Errorf("идентификатор ключа %s не должен содержать ':'", id)
+		}
+	}
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("текущий ключ шифрования %s не найден", current)
+	}
+	if len(index) != keySize {
+		return nil, fmt.Errorf("ключ слепого индекса должен быть длиной %d байт", keySize)
+	}
+	return &Keyring{keys: keys, current: current, index: index}, nil
+}
+
+// LoadKeyring читает ключи из окружения:
+//
+//	PASSPORT_KEYS       мастер-ключи через запятую в виде id:base64
+//	PASSPORT_KEY_FILE   файл с мастер-ключами id:base64 по одному в строке, вместо PASSPORT_KEYS
+//	PASSPORT_KEY_ID     текущий мастер-ключ, по умолчанию последний из перечисленных
+//	PASSPORT_INDEX_KEY  ключ слепого индекса в base64
+//
+// Для ротации новый ключ добавляется в конец списка, после чего выполняется команда
+// encrypt-passports, а старый ключ удаляется
+func LoadKeyring() (*Keyring, error) {
+	var entries []string
+	if file := os.Getenv("PASSPORT_KEY_FILE"); file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось открыть файл ключей: %w", err)
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+				entries = append(entries, line)
+			}
+		}
+		if err = scanner.Err(); err != nil {
+			return nil, fmt.Errorf("не удалось прочитать файл ключей: %w", err)
+		}
+	} else {
+		for _, entry := range strings.Split(os.Getenv("PASSPORT_KEYS"), ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	keys := make(map[string][]byte, len(entries))
+	var last string
+	for _, entry := range entries {
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("ключ шифрования должен быть задан в виде id:base64")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("ключ %s: %w", id, err)
+		}
+		keys[id] = key
+		last = id
+	}
+
+	current := os.Getenv("PASSPORT_KEY_ID")
+	if current == "" {
+		current = last
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("не заданы ключи шифрования паспортов PASSPORT_KEYS или PASSPORT_KEY_FILE, " +
+			"новый ключ печатает команда generate-key -id <id>")
+	}
+	if os.Getenv("PASSPORT_INDEX_KEY") == "" {
+		return nil, fmt.Errorf("не задан ключ слепого индекса PASSPORT_INDEX_KEY, новый ключ печатает команда generate-key")
+	}
+	index, err := base64.StdEncoding.DecodeString(os.Getenv("PASSPORT_INDEX_KEY"))
+	if err != nil {
+		return nil, fmt.Errorf("ключ слепого индекса: %w", err)
+	}
+	return NewKeyring(keys, current, index)
+}
+
+// GenerateKey создает случайный ключ и возвращает его в base64
+func GenerateKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// Encrypt шифрует значение новым ключом данных, зашифрованным текущим мастер-ключом
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(dataKey, []byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.keys[k.current], dataKey, []byte(k.current))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{
+		envelopePrefix,
+		k.current,
+		base64.RawStdEncoding.EncodeToString(wrapped),
+		base64.RawStdEncoding.EncodeToString(ciphertext),
+	}, ":"), nil
+}
+
+// Decrypt расшифровывает значение, зашифрованное любым ключом из набора
+func (k *Keyring) Decrypt(value string) (string, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 4 || parts[0] != envelopePrefix {
+		return "", fmt.Errorf("значение не зашифровано")
+	}
+
+	masterKey, ok := k.keys[parts[1]]
+	if !ok {
+		return "", fmt.Errorf("ключ шифрования %s не найден", parts[1])
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := open(masterKey, wrapped, []byte(parts[1]))
+	if err != nil {
+		return "", fmt.Errorf("не удалось расшифровать ключ данных: %w", err)
+	}
+	plaintext, err := open(dataKey, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("не удалось расшифровать значение: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted проверяет, что значение хранится в зашифрованном виде
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, envelopePrefix+":")
+}
+
+// NeedsRotation проверяет, что значение не зашифровано или зашифровано не текущим ключом
+func (k *Keyring) NeedsRotation(value string) bool {
+	return !strings.HasPrefix(value, envelopePrefix+":"+k.current+":")
+}
+
+// BlindIndex детерминированный HMAC значения поля для поиска по точному совпадению
+func (k *Keyring) BlindIndex(field, value string) string {
+	mac := hmac.New(sha256.New, k.index)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func seal(key, plaintext, additional []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+func open(key, sealed, additional []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("шифротекст поврежден")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additional)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
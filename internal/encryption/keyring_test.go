@@ -0,0 +1,64 @@
+package encryption
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadKeyringRequiresKeys(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		keys    string
+		index   string
+		wantErr string
+	}{
+		{name: "нет ключей", index: key, wantErr: "PASSPORT_KEYS"},
+		{name: "нет ключа индекса", keys: "k1:" + key, wantErr: "PASSPORT_INDEX_KEY"},
+		{name: "ключ не base64", keys: "k1:change-me", index: key, wantErr: "k1"},
+		{name: "ключи заданы", keys: "k1:" + key, index: key},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PASSPORT_KEY_FILE", "")
+			t.Setenv("PASSPORT_KEY_ID", "")
+			t.Setenv("PASSPORT_KEYS", tt.keys)
+			t.Setenv("PASSPORT_INDEX_KEY", tt.index)
+
+			_, err := LoadKeyring()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("LoadKeyring: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("LoadKeyring: %v, ожидалась ошибка с %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	key, _ := GenerateKey()
+	t.Setenv("PASSPORT_KEY_FILE", "")
+	t.Setenv("PASSPORT_KEY_ID", "")
+	t.Setenv("PASSPORT_KEYS", "k1:"+key)
+	t.Setenv("PASSPORT_INDEX_KEY", key)
+	keys, err := LoadKeyring()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := keys.Encrypt("0123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sealed, "0123") {
+		t.Errorf("зашифрованное значение содержит исходное: %s", sealed)
+	}
+	opened, err := keys.Decrypt(sealed)
+	if err != nil || opened != "0123" {
+		t.Fatalf("Decrypt = %q, %v", opened, err)
+	}
+}
@@ -0,0 +1,141 @@
+package enrichment
+
+import (
+	"GoTimeTracker/internal/metrics"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultTimeout время ожидания ответа API, если INFO_API_TIMEOUT не задан
+const defaultTimeout = 10 * time.Second
+
+// Client клиент внешнего API /info, возвращающего данные человека по паспорту
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+var (
+	instance *Client
+	once     sync.Once
+)
+
+// GetInstance возвращает клиент, настроенный по INFO_API_URL и INFO_API_TIMEOUT.
+// Если адрес API не задан, возвращается nil и обогащение данных не выполняется
+func GetInstance() *Client {
+	once.Do(func() {
+		baseURL := os.Getenv("INFO_API_URL")
+		if baseURL == "" {
+			logger.Info("INFO_API_URL не задан, обогащение данных сотрудников отключено")
+			return
+		}
+
+		timeout := defaultTimeout
+		if value := os.Getenv("INFO_API_TIMEOUT"); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				logger.Error("Неверное значение INFO_API_TIMEOUT, используется значение по умолчанию", zap.Error(err))
+			} else {
+				timeout = parsed
+			}
+		}
+		instance = NewClient(baseURL, timeout)
+	})
+	return instance
+}
+
+// Info данные человека из внешнего API. Остальные поля ответа не читаются: внешний API
+// не должен влиять, например, на руководителя сотрудника
+type Info struct {
+	Name       string `json:"name"`
+	Surname    string `json:"surname"`
+	Patronymic string `json:"patronymic"`
+	Address    string `json:"address"`
+}
+
+// Apply переносит ФИО и адрес в p, не затрагивая остальные поля
+func (i Info) Apply(p *model.People) {
+	p.Name, p.Surname, p.Patronymic, p.Address = i.Name, i.Surname, i.Patronymic, i.Address
+}
+
+// NewClient создает клиент API по базовому адресу
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: timeout, Transport: otelhttp.NewTransport(http.DefaultTransport)},
+	}
+}
+
+// Info запрашивает ФИО и адрес человека по серии и номеру паспорта
+func (c *Client) Info(ctx context.Context, passport model.Passport) (Info, error) {
+	query := url.Values{}
+	query.Set("passportSerie", passport.Serie)
+	query.Set("passportNumber", passport.Number)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/info?"+query.Encode(), nil)
+	if err != nil {
+		return Info{}, err
+	}
+
+	logger.Ctx(ctx).Debug("Запрос данных сотрудника во внешнем API", zap.String("passportSerie", passport.Serie))
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка запроса к внешнему API", zap.Error(err))
+		outcome := metrics.EnrichmentError
+		var netErr net.Error
+		if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+			outcome = metrics.EnrichmentTimeout
+		}
+		metrics.Enrichment(outcome, time.Since(start))
+		return Info{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Ctx(ctx).Error("Внешний API вернул ошибку", zap.Int("status", resp.StatusCode))
+		metrics.Enrichment(metrics.EnrichmentStatus, time.Since(start))
+		return Info{}, fmt.Errorf("внешний API вернул статус %d", resp.StatusCode)
+	}
+
+	var info Info
+	if err = json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		logger.Ctx(ctx).Error("Ошибка разбора ответа внешнего API", zap.Error(err))
+		metrics.Enrichment(metrics.EnrichmentDecode, time.Since(start))
+		return Info{}, err
+	}
+	metrics.Enrichment(metrics.EnrichmentSuccess, time.Since(start))
+	logger.Ctx(ctx).Debug("Получены данные сотрудника из внешнего API", zap.String("surname", info.Surname))
+	return info, nil
+}
+
+// Ping проверяет доступность внешнего API запросом к /info без параметров.
+// Любой ответ, кроме 5xx, означает, что API отвечает
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/info", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("внешний API вернул статус %d", resp.StatusCode)
+	}
+	return nil
+}
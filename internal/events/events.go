@@ -0,0 +1,130 @@
+// Package events внутрипроцессная шина событий для потоковых обновлений (GET /events).
+// События поступают из outbox после фиксации изменения (см. outbox.HubSink), подписчики
+// получают их через Hub с фильтром по сотрудникам, проекту и типу события
+package events
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Типы событий
+const (
+	TimerStarted  = "timer.started"
+	TimerStopped  = "timer.stopped"
+	TaskCreated   = "task.created"
+	TaskAssigned  = "task.assigned"
+	TaskDeleted   = "task.deleted"
+	PeopleUpdated = "people.updated"
+	PeopleDeleted = "people.deleted"
+
+	// Только доменные события (outbox)
+	TaskUpdated    = "task.updated"
+	TaskRestored   = "task.restored"
+	PeopleCreated  = "people.created"
+	PeopleRestored = "people.restored"
+
+	TimesheetSubmitted = "timesheet.submitted"
+	TimesheetApproved  = "timesheet.approved"
+	TimesheetRejected  = "timesheet.rejected"
+	TimesheetReopened  = "timesheet.reopened"
+)
+
+// Types типы событий потока /events
+var Types = []string{TimerStarted, TimerStopped, TaskCreated, TaskAssigned, TaskDeleted, PeopleUpdated, PeopleDeleted}
+
+// DomainTypes типы доменных событий, которые слой database записывает в outbox вместе с изменением
+var DomainTypes = append(slices.Clone(Types), TaskUpdated, TaskRestored, PeopleCreated, PeopleRestored,
+	TimesheetSubmitted, TimesheetApproved, TimesheetRejected, TimesheetReopened)
+
+// Event событие. Id возрастает и используется клиентом как Last-Event-ID при переподключении
+type Event struct {
+	Id       uint64    `json:"id"`
+	Type     string    `json:"type" example:"timer.started"`
+	At       time.Time `json:"at"`
+	PeopleId *int      `json:"people_id,omitempty"`
+	Project  *string   `json:"project,omitempty"`
+	// Data строка задачи или сотрудника после изменения, как в журнале изменений (без паспорта)
+	Data any `json:"data" swaggertype:"object"`
+}
+
+// Filter какие события получает подписчик. Пустые поля не фильтруют
+type Filter struct {
+	// PeopleIds сотрудники, nil означает всех
+	PeopleIds []int
+	// Unassigned включать события задач, не назначенных сотруднику
+	Unassigned bool
+	Project    string
+	Types      []string
+}
+
+// Match подходит ли событие под фильтр
+func (f Filter) Match(e Event) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, e.Type) {
+		return false
+	}
+	if f.Project != "" && (e.Project == nil || *e.Project != f.Project) {
+		return false
+	}
+	if e.PeopleId == nil {
+		return f.Unassigned
+	}
+	return f.PeopleIds == nil || slices.Contains(f.PeopleIds, *e.PeopleId)
+}
+
+// Config настройки шины
+type Config struct {
+	// History сколько последних событий хранится для повтора после переподключения
+	History int
+	// Buffer очередь событий подписчика. Подписчик, не успевающий ее разбирать, отключается
+	Buffer int
+	// Heartbeat интервал комментариев-пингов в потоке, чтобы прокси не закрывали соединение
+	Heartbeat time.Duration
+}
+
+// DefaultConfig настройки по умолчанию
+func DefaultConfig() Config {
+	return Config{History: 1000, Buffer: 64, Heartbeat: 15 * time.Second}
+}
+
+// ConfigFromEnv читает EVENTS_HISTORY, EVENTS_BUFFER и EVENTS_HEARTBEAT
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	for name, target := range map[string]*int{"EVENTS_HISTORY": &cfg.History, "EVENTS_BUFFER": &cfg.Buffer} {
+		if value := os.Getenv(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				return cfg, fmt.Errorf("неверное значение %s: %q", name, value)
+			}
+			*target = parsed
+		}
+	}
+	if value := os.Getenv("EVENTS_HEARTBEAT"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return cfg, fmt.Errorf("неверное значение EVENTS_HEARTBEAT: %q", value)
+		}
+		cfg.Heartbeat = parsed
+	}
+	return cfg, nil
+}
+
+var hub atomic.Pointer[Hub]
+
+// Configure заменяет шину по умолчанию. Вызывается при запуске до приема запросов
+func Configure(cfg Config) {
+	hub.Store(NewHub(cfg))
+}
+
+// Default шина по умолчанию. Если Configure не вызывался, создается с DefaultConfig
+func Default() *Hub {
+	if h := hub.Load(); h != nil {
+		return h
+	}
+	hub.CompareAndSwap(nil, NewHub(DefaultConfig()))
+	return hub.Load()
+}
@@ -0,0 +1,140 @@
+package events
+
+import (
+	"GoTimeTracker/internal/metrics"
+	"sync"
+	"time"
+)
+
+// Hub рассылает события подписчикам и хранит последние события для повтора.
+// Публикация не блокируется: подписчик с переполненной очередью отключается
+// и может переподключиться с Last-Event-ID, получив пропущенное из истории
+type Hub struct {
+	cfg Config
+
+	mu      sync.Mutex
+	lastId  uint64
+	history []Event
+	// next позиция для записи в кольцевом буфере history
+	next int
+	subs map[*Subscription]struct{}
+}
+
+// NewHub создает шину. Идентификаторы событий начинаются с текущего времени в микросекундах,
+// чтобы Last-Event-ID, полученный до перезапуска сервера, не совпал с новыми событиями
+func NewHub(cfg Config) *Hub {
+	return &Hub{
+		cfg:    cfg,
+		lastId: uint64(time.Now().UnixMicro()),
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// Heartbeat интервал пингов для потоков подписчиков
+func (h *Hub) Heartbeat() time.Duration {
+	return h.cfg.Heartbeat
+}
+
+// Publish присваивает событию идентификатор и время и рассылает его подписчикам
+func (h *Hub) Publish(e Event) Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastId++
+	e.Id = h.lastId
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+
+	if len(h.history) < h.cfg.History {
+		h.history = append(h.history, e)
+	} else {
+		h.history[h.next] = e
+		h.next = (h.next + 1) % len(h.history)
+	}
+
+	for sub := range h.subs {
+		if !sub.filter.Match(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			sub.lagged = true
+			h.remove(sub)
+			metrics.EventSubscriberDropped()
+		}
+	}
+	metrics.EventPublished(e.Type)
+	return e
+}
+
+// Subscribe подписывает на события, подходящие под filter. Если lastEventId не 0, возвращает
+// события после него из истории. complete равен false, если часть событий уже вытеснена из
+// истории или получена до перезапуска и клиенту нужно заново загрузить состояние
+func (h *Hub) Subscribe(filter Filter, lastEventId uint64) (sub *Subscription, replay []Event, complete bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub = &Subscription{hub: h, filter: filter, ch: make(chan Event, h.cfg.Buffer)}
+	h.subs[sub] = struct{}{}
+
+	complete = true
+	if lastEventId == 0 || lastEventId >= h.lastId {
+		return sub, nil, lastEventId <= h.lastId
+	}
+	ordered := append(h.history[h.next:len(h.history):len(h.history)], h.history[:h.next]...)
+	if len(ordered) == 0 || ordered[0].Id > lastEventId+1 {
+		complete = false
+	}
+	for _, e := range ordered {
+		if e.Id > lastEventId && filter.Match(e) {
+			replay = append(replay, e)
+		}
+	}
+	return sub, replay, complete
+}
+
+// Subscribers количество подписчиков
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// remove отписывает sub и закрывает его канал. Вызывается под h.mu
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	close(sub.ch)
+}
+
+// Subscription подписка на события
+type Subscription struct {
+	hub    *Hub
+	filter Filter
+	ch     chan Event
+	// lagged подписчик отключен, потому что не успевал разбирать очередь. Пишется под hub.mu
+	lagged bool
+}
+
+// Events канал событий. Закрывается при Close или отключении отстающего подписчика
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Lagged отключен ли подписчик из-за переполнения очереди. Проверяется после закрытия Events
+func (s *Subscription) Lagged() bool {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.lagged
+}
+
+// Close отписывает от событий
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
@@ -0,0 +1,58 @@
+package export
+
+import "GoTimeTracker/internal/model"
+
+// PeopleColumns колонки выгрузки сотрудников
+var PeopleColumns = []Column{
+	{Title: map[string]string{"ru": "Идентификатор", "en": "ID"}},
+	{Title: map[string]string{"ru": "Серия паспорта", "en": "Passport series"}},
+	{Title: map[string]string{"ru": "Номер паспорта", "en": "Passport number"}},
+	{Title: map[string]string{"ru": "Фамилия", "en": "Surname"}},
+	{Title: map[string]string{"ru": "Имя", "en": "Name"}},
+	{Title: map[string]string{"ru": "Отчество", "en": "Patronymic"}},
+	{Title: map[string]string{"ru": "Адрес", "en": "Address"}},
+}
+
+// PeopleRow строка выгрузки сотрудника
+func PeopleRow(p model.People) []any {
+	return []any{p.Id, p.PassportSerie, p.PassportNumber, p.Surname, p.Name, p.Patronymic, p.Address}
+}
+
+// TaskColumns колонки выгрузки трудозатрат по задачам
+var TaskColumns = []Column{
+	{Title: map[string]string{"ru": "Идентификатор", "en": "ID"}},
+	{Title: map[string]string{"ru": "Сотрудник", "en": "Person ID"}},
+	{Title: map[string]string{"ru": "Задача", "en": "Task"}},
+	{Title: map[string]string{"ru": "Описание", "en": "Description"}},
+	{Title: map[string]string{"ru": "Проект", "en": "Project"}},
+	{Title: map[string]string{"ru": "Начало", "en": "Started"}},
+	{Title: map[string]string{"ru": "Окончание", "en": "Finished"}},
+	{Title: map[string]string{"ru": "Затрачено", "en": "Duration"}},
+	{Title: map[string]string{"ru": "Оценка", "en": "Estimate"}},
+	{Title: map[string]string{"ru": "Превышение оценки", "en": "Over estimate"}},
+}
+
+// TaskRow строка выгрузки задачи
+func TaskRow(t model.Task) []any {
+	var project any
+	if t.Project != nil {
+		project = *t.Project
+	}
+	return []any{t.Id, t.PeopleId, t.Name, t.Description, project,
+		Time(t.TimeStart), Time(t.TimeEnd), Span(t.TimeStart, t.TimeEnd), Minutes(t.EstimateMinutes), t.OverEstimate}
+}
+
+// EstimateReportColumns колонки выгрузки отчета по оценкам
+var EstimateReportColumns = []Column{
+	{Title: map[string]string{"ru": "Ключ", "en": "Key"}},
+	{Title: map[string]string{"ru": "Название", "en": "Name"}},
+	{Title: map[string]string{"ru": "Задач", "en": "Tasks"}},
+	{Title: map[string]string{"ru": "Оценка", "en": "Estimate"}},
+	{Title: map[string]string{"ru": "Факт", "en": "Actual"}},
+	{Title: map[string]string{"ru": "Точность", "en": "Accuracy"}},
+}
+
+// EstimateReportRow строка выгрузки отчета по оценкам
+func EstimateReportRow(r model.EstimateReport) []any {
+	return []any{r.Key, r.Name, r.Tasks, Minutes(&r.EstimateMinutes), Minutes(&r.ActualMinutes), r.Accuracy}
+}
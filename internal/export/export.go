@@ -0,0 +1,138 @@
+package export
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"strings"
+	"time"
+)
+
+// Format формат ответа списковых и отчетных методов
+type Format string
+
+const (
+	JSON Format = "json"
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+const (
+	mimeCSV  = "text/csv"
+	mimeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// Column колонка выгрузки с заголовками на поддерживаемых языках
+type Column struct {
+	Title map[string]string
+}
+
+// Writer построчная запись выгрузки. Представление значения определяется его типом:
+// time.Time записывается как дата, time.Duration как продолжительность, nil как пустая ячейка
+type Writer interface {
+	WriteRow(values ...any) error
+	Close() error
+}
+
+// Negotiate определяет формат по параметру format, а при его отсутствии по заголовку Accept
+func Negotiate(ctx *gin.Context) (Format, error) {
+	switch format := Format(strings.ToLower(ctx.Query("format"))); format {
+	case JSON, CSV, XLSX:
+		return format, nil
+	case "":
+	default:
+		return "", fmt.Errorf("неподдерживаемый формат выгрузки: %s", format)
+	}
+
+	accept := ctx.GetHeader("Accept")
+	switch {
+	case strings.Contains(accept, mimeCSV):
+		return CSV, nil
+	case strings.Contains(accept, mimeXLSX):
+		return XLSX, nil
+	}
+	return JSON, nil
+}
+
+// Language язык заголовков колонок: параметр lang или заголовок Accept-Language, по умолчанию ru
+func Language(ctx *gin.Context) string {
+	lang := ctx.Query("lang")
+	if lang == "" {
+		lang = ctx.GetHeader("Accept-Language")
+	}
+	if strings.HasPrefix(strings.ToLower(lang), "en") {
+		return "en"
+	}
+	return "ru"
+}
+
+// Start выставляет заголовки ответа для скачивания файла и возвращает Writer,
+// пишущий строки прямо в ответ
+func Start(ctx *gin.Context, format Format, name string, columns []Column) (Writer, error) {
+	lang := Language(ctx)
+	switch format {
+	case CSV:
+		ctx.Header("Content-Type", mimeCSV+"; charset=utf-8")
+	case XLSX:
+		ctx.Header("Content-Type", mimeXLSX)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат выгрузки: %s", format)
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	return NewWriter(ctx.Writer, format, columns, lang)
+}
+
+// NewWriter создает Writer заданного формата и записывает строку заголовков
+func NewWriter(w io.Writer, format Format, columns []Column, lang string) (Writer, error) {
+	titles := make([]any, len(columns))
+	for i, column := range columns {
+		title, ok := column.Title[lang]
+		if !ok {
+			title = column.Title["ru"]
+		}
+		titles[i] = title
+	}
+
+	var writer Writer
+	var err error
+	switch format {
+	case CSV:
+		writer, err = newCSVWriter(w)
+	case XLSX:
+		writer, err = newXLSXWriter(w)
+	default:
+		err = fmt.Errorf("неподдерживаемый формат выгрузки: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = writer.WriteRow(titles...); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Time возвращает nil для отсутствующего времени, чтобы в выгрузке получилась пустая ячейка
+func Time(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+// Span продолжительность интервала, nil если интервал не начат или не завершен
+func Span(start, end *time.Time) any {
+	if start == nil || end == nil {
+		return nil
+	}
+	return end.Sub(*start)
+}
+
+// Minutes продолжительность по количеству минут, nil для отсутствующего значения
+func Minutes(minutes *int) any {
+	if minutes == nil {
+		return nil
+	}
+	return time.Duration(*minutes) * time.Minute
+}
@@ -0,0 +1,69 @@
+package export
+
+import (
+	"GoTimeTracker/internal/model"
+	"bytes"
+	"encoding/csv"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTaskRowCSV(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+	estimate := 60
+	tasks := []model.Task{
+		{Id: 1, Name: "Не начата"},
+		{Id: 2, PeopleId: 7, Name: "Запущена", TimeStart: &start, EstimateMinutes: &estimate},
+		{Id: 3, PeopleId: 7, Name: "Завершена", TimeStart: &start, TimeEnd: &end, EstimateMinutes: &estimate, OverEstimate: true},
+	}
+
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, CSV, TaskColumns, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, task := range tasks {
+		if err = writer.WriteRow(TaskRow(task)...); err != nil {
+			t.Fatalf("WriteRow(%d): %v", task.Id, err)
+		}
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(buf.Bytes(), []byte(utf8BOM)))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"ID", "Person ID", "Task", "Description", "Project", "Started", "Finished", "Duration", "Estimate", "Over estimate"},
+		{"1", "0", "Не начата", "", "", "", "", "", "", "false"},
+		{"2", "7", "Запущена", "", "", "2026-01-05 09:00:00", "", "", "01:00:00", "false"},
+		{"3", "7", "Завершена", "", "", "2026-01-05 09:00:00", "2026-01-05 10:30:00", "01:30:00", "01:00:00", "true"},
+	}
+	if !slices.EqualFunc(records, want, slices.Equal[[]string]) {
+		t.Fatalf("выгрузка %q, ожидалось %q", records, want)
+	}
+}
+
+func TestTaskRowXLSX(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, XLSX, TaskColumns, "ru")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, task := range []model.Task{{Id: 1, Name: "Не начата"}, {Id: 2, Name: "Запущена", TimeStart: &start}} {
+		if err = writer.WriteRow(TaskRow(task)...); err != nil {
+			t.Fatalf("WriteRow(%d): %v", task.Id, err)
+		}
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() == 0 {
+		t.Fatal("пустая выгрузка xlsx")
+	}
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"github.com/xuri/excelize/v2"
+	"io"
+	"time"
+)
+
+const (
+	xlsxSheet = "Sheet1"
+	// xlsxDurationFormat встроенный формат Excel [h]:mm:ss
+	xlsxDurationFormat = 46
+	xlsxDateFormat     = "yyyy-mm-dd hh:mm:ss"
+)
+
+// xlsxWriter пишет строки через потоковый writer excelize, который держит данные листа
+// во временном файле, а не в памяти. Книга целиком записывается в ответ при закрытии
+type xlsxWriter struct {
+	out      io.Writer
+	file     *excelize.File
+	stream   *excelize.StreamWriter
+	row      int
+	date     int
+	duration int
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	stream, err := file.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	dateFormat := xlsxDateFormat
+	date, err := file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	duration, err := file.NewStyle(&excelize.Style{NumFmt: xlsxDurationFormat})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &xlsxWriter{out: w, file: file, stream: stream, date: date, duration: duration}, nil
+}
+
+func (x *xlsxWriter) WriteRow(values ...any) error {
+	cells := make([]any, len(values))
+	for i, value := range values {
+		switch value.(type) {
+		case time.Time:
+			cells[i] = excelize.Cell{StyleID: x.date, Value: value}
+		case time.Duration:
+			cells[i] = excelize.Cell{StyleID: x.duration, Value: value}
+		default:
+			cells[i] = value
+		}
+	}
+
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	return x.stream.SetRow(cell, cells)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	return x.file.Write(x.out)
+}
@@ -0,0 +1,151 @@
+package graph
+
+import (
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/service"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"errors"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/gin-gonic/gin"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options настройки GraphQL API
+type Options struct {
+	// Complexity наибольшая сложность запроса: каждое поле стоит 1, списки умножают
+	// сложность вложенных полей на размер страницы или ожидаемое число задач
+	Complexity int
+	// Dev включает интроспекцию схемы и playground
+	Dev bool
+}
+
+// tasksPerPeople ожидаемое число задач сотрудника при оценке сложности запроса
+const tasksPerPeople = 10
+
+// OptionsFromEnv читает настройки из GRAPHQL_COMPLEXITY (по умолчанию 1000) и GRAPHQL_DEV
+func OptionsFromEnv() (Options, error) {
+	opts := Options{Complexity: 1000}
+	if value := os.Getenv("GRAPHQL_COMPLEXITY"); value != "" {
+		complexity, err := strconv.Atoi(value)
+		if err != nil || complexity <= 0 {
+			return opts, errors.New("неверное значение GRAPHQL_COMPLEXITY: " + value)
+		}
+		opts.Complexity = complexity
+	}
+	if value := os.Getenv("GRAPHQL_DEV"); value != "" {
+		dev, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, errors.New("неверное значение GRAPHQL_DEV: " + value)
+		}
+		opts.Dev = dev
+	}
+	return opts, nil
+}
+
+type actorKey struct{}
+
+// actor вызывающая сторона, сохраненная Handler
+func actor(ctx context.Context) auth.Identity {
+	identity, _ := ctx.Value(actorKey{}).(auth.Identity)
+	return identity
+}
+
+// withOverride контекст мутации с причиной изменения в закрытом периоде из аргумента overrideReason
+func withOverride(ctx context.Context, reason *string) context.Context {
+	if reason == nil || strings.TrimSpace(*reason) == "" {
+		return ctx
+	}
+	return service.WithOverride(ctx, strings.TrimSpace(*reason))
+}
+
+// Handler выполняет запросы GraphQL от имени вызывающей стороны, сохраненной auth.Middleware
+func Handler(opts Options) gin.HandlerFunc {
+	cfg := Config{Resolvers: &Resolver{}}
+	cfg.Complexity.Query.People = func(childComplexity int, page, pageSize int, _ *PeopleFilter, _ bool) int {
+		return pageSize * childComplexity
+	}
+	cfg.Complexity.People.Tasks = func(childComplexity int, _ bool) int {
+		return tasksPerPeople * childComplexity
+	}
+	cfg.Complexity.People.TimeEntries = func(childComplexity int, _, _ *time.Time) int {
+		return tasksPerPeople * childComplexity
+	}
+
+	server := handler.New(NewExecutableSchema(cfg))
+	server.AddTransport(transport.GET{})
+	server.AddTransport(transport.POST{})
+	server.Use(extension.FixedComplexityLimit(opts.Complexity))
+	if opts.Dev {
+		server.Use(extension.Introspection{})
+	}
+	server.SetErrorPresenter(presentError)
+
+	return func(ctx *gin.Context) {
+		identity, _ := auth.FromContext(ctx)
+		requestCtx := context.WithValue(ctx.Request.Context(), actorKey{}, identity)
+		requestCtx = context.WithValue(requestCtx, loadersKey{}, newLoaders(identity))
+		server.ServeHTTP(ctx.Writer, ctx.Request.WithContext(requestCtx))
+	}
+}
+
+// Playground страница для ручных запросов к endpoint
+func Playground(endpoint string) gin.HandlerFunc {
+	return gin.WrapH(playground.Handler("GoTimeTracker", endpoint))
+}
+
+// presentError добавляет к ошибкам сервисного слоя код в extensions.code, как статусы
+// REST API. Непредвиденные ошибки логируются
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	presented := graphql.DefaultErrorPresenter(ctx, err)
+	code := ""
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		code = "FORBIDDEN"
+	case errors.Is(err, service.ErrNotFound):
+		code = "NOT_FOUND"
+	case errors.Is(err, service.ErrPeriodLocked):
+		code = "LOCKED"
+	case errors.Is(err, service.ErrPeriodClosed):
+		code = "PERIOD_CLOSED"
+	case errors.Is(err, service.ErrTimesheetState), errors.Is(err, service.ErrTimerRunning):
+		code = "CONFLICT"
+	case errors.As(err, new(*invalidError)):
+		code = "BAD_REQUEST"
+	default:
+		var gqlErr *gqlerror.Error
+		if errors.As(err, &gqlErr) && gqlErr.Unwrap() == nil {
+			// Ошибки разбора и проверки запроса уже содержат понятное описание
+			return presented
+		}
+		logger.Ctx(ctx).Error("Ошибка при выполнении запроса GraphQL", zap.Error(err))
+		code = "INTERNAL"
+	}
+	if presented.Extensions == nil {
+		presented.Extensions = make(map[string]interface{})
+	}
+	presented.Extensions["code"] = code
+	return presented
+}
+
+// invalidError неверные аргументы запроса
+type invalidError struct {
+	message string
+}
+
+func (e *invalidError) Error() string {
+	return e.message
+}
+
+func invalid(message string) error {
+	return &invalidError{message: message}
+}
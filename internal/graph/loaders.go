@@ -0,0 +1,81 @@
+package graph
+
+import (
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/internal/service"
+	"context"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// Loaders собирают обращения резолверов к связанным записям в пакеты, чтобы список
+// сотрудников с задачами загружался двумя запросами, а не одним на каждого сотрудника.
+// Создаются на каждый запрос: кэш загрузчиков не переживает запрос и не смешивает права
+type Loaders struct {
+	// tasks задачи сотрудника по идентификатору сотрудника, отдельно с удаленными и без
+	tasks map[bool]*dataloader.Loader[int, []model.Task]
+	// people сотрудник по идентификатору, nil если он недоступен или удален
+	people *dataloader.Loader[int, *model.People]
+}
+
+type loadersKey struct{}
+
+func newLoaders(actor auth.Identity) *Loaders {
+	return &Loaders{
+		tasks: map[bool]*dataloader.Loader[int, []model.Task]{
+			false: dataloader.NewBatchedLoader(tasksBatch(actor, false)),
+			true:  dataloader.NewBatchedLoader(tasksBatch(actor, true)),
+		},
+		people: dataloader.NewBatchedLoader(peopleBatch(actor)),
+	}
+}
+
+// loaders загрузчики текущего запроса, см. Handler
+func loaders(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
+
+func tasksBatch(actor auth.Identity, includeDeleted bool) dataloader.BatchFunc[int, []model.Task] {
+	return func(ctx context.Context, peopleIds []int) []*dataloader.Result[[]model.Task] {
+		tasks, err := service.GetTasksByPeople(ctx, actor, peopleIds, includeDeleted)
+		if err != nil {
+			return failed[[]model.Task](len(peopleIds), err)
+		}
+		byPeople := make(map[int][]model.Task, len(peopleIds))
+		for _, t := range tasks {
+			byPeople[t.PeopleId] = append(byPeople[t.PeopleId], t)
+		}
+		results := make([]*dataloader.Result[[]model.Task], len(peopleIds))
+		for i, id := range peopleIds {
+			results[i] = &dataloader.Result[[]model.Task]{Data: byPeople[id]}
+		}
+		return results
+	}
+}
+
+func peopleBatch(actor auth.Identity) dataloader.BatchFunc[int, *model.People] {
+	return func(ctx context.Context, ids []int) []*dataloader.Result[*model.People] {
+		people, err := service.GetPeopleByIds(ctx, actor, ids)
+		if err != nil {
+			return failed[*model.People](len(ids), err)
+		}
+		byId := make(map[int]*model.People, len(people))
+		for i := range people {
+			byId[people[i].Id] = &people[i]
+		}
+		results := make([]*dataloader.Result[*model.People], len(ids))
+		for i, id := range ids {
+			results[i] = &dataloader.Result[*model.People]{Data: byId[id]}
+		}
+		return results
+	}
+}
+
+// failed результат пакета, в котором все ключи завершились ошибкой err
+func failed[V any](count int, err error) []*dataloader.Result[V] {
+	results := make([]*dataloader.Result[V], count)
+	for i := range results {
+		results[i] = &dataloader.Result[V]{Error: err}
+	}
+	return results
+}
@@ -0,0 +1,64 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type Mutation struct {
+}
+
+// Фильтр сотрудников, как filter=param:value в REST API
+type PeopleFilter struct {
+	Param string `json:"param"`
+	Value string `json:"value"`
+}
+
+type Query struct {
+}
+
+type ReportGroup string
+
+const (
+	ReportGroupTask    ReportGroup = "TASK"
+	ReportGroupPeople  ReportGroup = "PEOPLE"
+	ReportGroupProject ReportGroup = "PROJECT"
+)
+
+var AllReportGroup = []ReportGroup{
+	ReportGroupTask,
+	ReportGroupPeople,
+	ReportGroupProject,
+}
+
+func (e ReportGroup) IsValid() bool {
+	switch e {
+	case ReportGroupTask, ReportGroupPeople, ReportGroupProject:
+		return true
+	}
+	return false
+}
+
+func (e ReportGroup) String() string {
+	return string(e)
+}
+
+func (e *ReportGroup) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportGroup(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportGroup", str)
+	}
+	return nil
+}
+
+func (e ReportGroup) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -0,0 +1,7 @@
+package graph
+
+//go:generate go run github.com/99designs/gqlgen generate
+
+// Resolver корень резолверов GraphQL. Данные берутся из internal/service, поэтому права
+// проверяются так же, как в REST API; связанные записи загружаются пакетами, см. Loaders
+type Resolver struct{}
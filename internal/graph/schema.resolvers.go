@@ -0,0 +1,133 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.49
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/internal/service"
+	"context"
+	"strings"
+	"time"
+)
+
+// StartTask is the resolver for the startTask field.
+func (r *mutationResolver) StartTask(ctx context.Context, id int, overrideReason *string) (*model.Task, error) {
+	if err := service.StartTask(withOverride(ctx, overrideReason), actor(ctx), id); err != nil {
+		return nil, err
+	}
+	task, err := service.GetTask(ctx, actor(ctx), id)
+	return &task, err
+}
+
+// EndTask is the resolver for the endTask field.
+func (r *mutationResolver) EndTask(ctx context.Context, id int, overrideReason *string) (*model.Task, error) {
+	if err := service.EndTask(withOverride(ctx, overrideReason), actor(ctx), id); err != nil {
+		return nil, err
+	}
+	task, err := service.GetTask(ctx, actor(ctx), id)
+	return &task, err
+}
+
+// Tasks is the resolver for the tasks field.
+func (r *peopleResolver) Tasks(ctx context.Context, obj *model.People, includeDeleted bool) ([]model.Task, error) {
+	return loaders(ctx).tasks[includeDeleted].Load(ctx, obj.Id)()
+}
+
+// TimeEntries is the resolver for the timeEntries field.
+func (r *peopleResolver) TimeEntries(ctx context.Context, obj *model.People, from *time.Time, to *time.Time) ([]model.TimeEntry, error) {
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, invalid("Начало периода должно быть раньше конца")
+	}
+	tasks, err := loaders(ctx).tasks[false].Load(ctx, obj.Id)()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	entries := make([]model.TimeEntry, 0, len(tasks))
+	for _, t := range tasks {
+		if entry, ok := t.TimeEntry(from, to, now); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// TotalMinutes is the resolver for the totalMinutes field.
+func (r *peopleResolver) TotalMinutes(ctx context.Context, obj *model.People, from *time.Time, to *time.Time) (int, error) {
+	entries, err := r.TimeEntries(ctx, obj, from, to)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, entry := range entries {
+		total += entry.Minutes
+	}
+	return total, nil
+}
+
+// People is the resolver for the people field.
+func (r *queryResolver) People(ctx context.Context, page int, pageSize int, filter *PeopleFilter, includeDeleted bool) ([]model.People, error) {
+	if page < 1 || pageSize < 1 {
+		return nil, invalid("page и pageSize должны быть положительными")
+	}
+	peopleFilter := database.PeopleFilter{IncludeDeleted: includeDeleted}
+	if filter != nil {
+		peopleFilter.Param, peopleFilter.Value = filter.Param, filter.Value
+	}
+	return service.GetAllPeople(ctx, actor(ctx), page, pageSize, peopleFilter)
+}
+
+// Tasks is the resolver for the tasks field.
+func (r *queryResolver) Tasks(ctx context.Context, peopleID int, includeDeleted bool) ([]model.Task, error) {
+	return service.GetPeopleTasks(ctx, actor(ctx), peopleID, includeDeleted)
+}
+
+// EstimateReport is the resolver for the estimateReport field.
+func (r *queryResolver) EstimateReport(ctx context.Context, groupBy ReportGroup) ([]model.EstimateReport, error) {
+	return service.GetEstimateReport(ctx, actor(ctx), strings.ToLower(groupBy.String()))
+}
+
+// PeopleID is the resolver for the peopleId field.
+func (r *taskResolver) PeopleID(ctx context.Context, obj *model.Task) (*int, error) {
+	if obj.PeopleId == 0 {
+		return nil, nil
+	}
+	return &obj.PeopleId, nil
+}
+
+// People is the resolver for the people field.
+func (r *taskResolver) People(ctx context.Context, obj *model.Task) (*model.People, error) {
+	if obj.PeopleId == 0 {
+		return nil, nil
+	}
+	return loaders(ctx).people.Load(ctx, obj.PeopleId)()
+}
+
+// TimeEntry is the resolver for the timeEntry field.
+func (r *taskResolver) TimeEntry(ctx context.Context, obj *model.Task) (*model.TimeEntry, error) {
+	entry, ok := obj.TimeEntry(nil, nil, time.Now())
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// People returns PeopleResolver implementation.
+func (r *Resolver) People() PeopleResolver { return &peopleResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Task returns TaskResolver implementation.
+func (r *Resolver) Task() TaskResolver { return &taskResolver{r} }
+
+type mutationResolver struct{ *Resolver }
+type peopleResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type taskResolver struct{ *Resolver }
@@ -0,0 +1,41 @@
+package grpcapi
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/service"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// serviceError переводит ошибку сервисного слоя в статус gRPC, как controller.serviceError в HTTP.
+// Непредвиденные ошибки логируются с message
+func serviceError(ctx context.Context, err error, message string) error {
+	var duplicate *database.DuplicatePassportError
+	var enrichmentErr *service.EnrichmentError
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrPeriodLocked), errors.Is(err, service.ErrPeriodClosed),
+		errors.Is(err, service.ErrTimesheetState), errors.Is(err, service.ErrTimerRunning):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.As(err, &duplicate):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.As(err, &enrichmentErr):
+		logger.Ctx(ctx).Error("Ошибка при получении данных сотрудника из внешнего API", zap.Error(err))
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		logger.Ctx(ctx).Error(message, zap.Error(err))
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// invalid ответ на неверные параметры запроса
+func invalid(message string) error {
+	return status.Error(codes.InvalidArgument, message)
+}
@@ -0,0 +1,163 @@
+package grpcapi
+
+import (
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/request"
+	"GoTimeTracker/internal/service"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"strings"
+	"time"
+)
+
+// Метаданные запроса, соответствующие заголовкам REST API
+const (
+	metadataRequestId     = "x-request-id"
+	metadataAuthorization = "authorization"
+	metadataAPIKey        = "x-api-key"
+	// metadataOverrideReason причина изменения в закрытом периоде, как override_reason в REST API.
+	// Текст не в ASCII передается в metadataOverrideReason+"-bin"
+	metadataOverrideReason = "x-override-reason"
+)
+
+// maxRequestIdLength ограничение длины идентификатора, присланного клиентом
+const maxRequestIdLength = 128
+
+// publicServices сервисы, доступные без аутентификации
+var publicServices = []string{"/grpc.health.v1.Health/", "/grpc.reflection."}
+
+type identityKey struct{}
+
+// actor вызывающая сторона, сохраненная authInterceptor
+func actor(ctx context.Context) auth.Identity {
+	identity, _ := ctx.Value(identityKey{}).(auth.Identity)
+	return identity
+}
+
+// overrideContext контекст вызова с причиной изменения в закрытом периоде из метаданных
+func overrideContext(ctx context.Context) context.Context {
+	reason := firstMetadata(ctx, metadataOverrideReason+"-bin")
+	if reason == "" {
+		reason = firstMetadata(ctx, metadataOverrideReason)
+	}
+	if reason = strings.TrimSpace(reason); reason == "" {
+		return ctx
+	}
+	return service.WithOverride(ctx, reason)
+}
+
+// recoverInterceptor превращает панику обработчика в ответ INTERNAL
+func recoverInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Ctx(ctx).Error("Паника при обработке gRPC-запроса", zap.String("method", info.FullMethod), zap.Any("panic", r))
+			err = status.Error(codes.Internal, "внутренняя ошибка")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// requestInterceptor как request.Middleware: берет идентификатор запроса из x-request-id
+// или создает новый, возвращает его в заголовке ответа и сохраняет в контексте вместе с адресом клиента
+func requestInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	id := firstMetadata(ctx, metadataRequestId)
+	if id == "" || len(id) > maxRequestIdLength {
+		id = newRequestId()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(metadataRequestId, id))
+
+	meta := request.Meta{Id: id, ClientIp: clientIp(ctx)}
+	ctx = logger.With(request.WithMeta(ctx, meta),
+		zap.String("request_id", id),
+		zap.String("method", info.FullMethod),
+		zap.String("client_ip", meta.ClientIp),
+	)
+	return handler(ctx, req)
+}
+
+// logInterceptor записывает каждый вызов в журнал, как request.AccessLog
+func logInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err)
+	fields := []zap.Field{zap.String("code", code.String()), zap.Duration("latency", time.Since(start))}
+	log := logger.Ctx(ctx)
+	switch {
+	case code == codes.Internal || code == codes.Unknown:
+		log.Error("gRPC-запрос обработан с ошибкой", append(fields, zap.Error(err))...)
+	case isPublic(info.FullMethod):
+		log.Debug("gRPC-запрос обработан", fields...)
+	default:
+		log.Info("gRPC-запрос обработан", fields...)
+	}
+	return resp, err
+}
+
+// authInterceptor как auth.Middleware: пропускает только вызовы с действующим токеном
+// (authorization: Bearer <jwt>) или ключом доступа (x-api-key)
+func authInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if isPublic(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	identity, err := auth.Authenticate(ctx, firstMetadata(ctx, metadataAuthorization), firstMetadata(ctx, metadataAPIKey))
+	if auth.IsDenied(err) {
+		logger.Ctx(ctx).Info("Отказ в доступе", zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error("Ошибка при аутентификации", zap.Error(err))
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	ctx = logger.With(context.WithValue(ctx, identityKey{}, identity),
+		zap.String("actor_kind", identity.Kind),
+		zap.Int("actor_id", identity.Id),
+		zap.String("actor_name", identity.Name),
+	)
+	return handler(ctx, req)
+}
+
+func isPublic(method string) bool {
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	values := metadata.ValueFromIncomingContext(ctx, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func clientIp(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return host
+	}
+	return p.Addr.String()
+}
+
+func newRequestId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,173 @@
+package grpcapi
+
+import (
+	trackerv1 "GoTimeTracker/api/proto/tracker/v1"
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/internal/service"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// peopleServer реализация trackerv1.PeopleService поверх internal/service
+type peopleServer struct {
+	trackerv1.UnimplementedPeopleServiceServer
+}
+
+// taskPolicies политики задач удаляемого сотрудника, см. database.DeletePeople
+var taskPolicies = map[trackerv1.TaskPolicy]string{
+	trackerv1.TaskPolicy_TASK_POLICY_UNSPECIFIED: database.TasksKeep,
+	trackerv1.TaskPolicy_TASK_POLICY_KEEP:        database.TasksKeep,
+	trackerv1.TaskPolicy_TASK_POLICY_REASSIGN:    database.TasksReassign,
+	trackerv1.TaskPolicy_TASK_POLICY_CASCADE:     database.TasksCascade,
+}
+
+func (peopleServer) ListPeople(ctx context.Context, req *trackerv1.ListPeopleRequest) (*trackerv1.ListPeopleResponse, error) {
+	if req.Page < 1 || req.PageSize < 1 {
+		return nil, invalid("page и page_size должны быть положительными")
+	}
+	filter := database.PeopleFilter{Param: req.FilterParam, Value: req.FilterValue, IncludeDeleted: req.IncludeDeleted}
+	if (filter.Param == "") != (filter.Value == "") {
+		return nil, invalid("Неверный формат фильтра")
+	}
+
+	people, err := service.GetAllPeople(ctx, actor(ctx), int(req.Page), int(req.PageSize), filter)
+	if err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при получении списка сотрудников")
+	}
+	resp := &trackerv1.ListPeopleResponse{People: make([]*trackerv1.People, 0, len(people))}
+	for _, p := range people {
+		resp.People = append(resp.People, peopleToProto(p))
+	}
+	return resp, nil
+}
+
+func (peopleServer) GetPeople(ctx context.Context, req *trackerv1.GetPeopleRequest) (*trackerv1.People, error) {
+	if req.Id <= 0 {
+		return nil, invalid("Неверное значение id")
+	}
+	people, err := service.GetPeopleByIds(ctx, actor(ctx), []int{int(req.Id)})
+	if err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при получении сотрудника")
+	}
+	if len(people) == 0 {
+		return nil, status.Error(codes.NotFound, service.ErrNotFound.Error())
+	}
+	return peopleToProto(people[0]), nil
+}
+
+func (peopleServer) CreatePeople(ctx context.Context, req *trackerv1.CreatePeopleRequest) (*trackerv1.CreatePeopleResponse, error) {
+	passport, err := model.ParsePassport(req.PassportNumber)
+	if err != nil {
+		return nil, invalid(err.Error())
+	}
+	id, err := service.AddPeople(ctx, actor(ctx), passport)
+	if err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при добавлении сотрудника")
+	}
+	return &trackerv1.CreatePeopleResponse{Id: int64(id)}, nil
+}
+
+func (peopleServer) UpdatePeople(ctx context.Context, req *trackerv1.People) (*emptypb.Empty, error) {
+	p := model.People{
+		Id:             int(req.Id),
+		PassportSerie:  req.PassportSerie,
+		PassportNumber: req.PassportNumber,
+		Name:           req.Name,
+		Surname:        req.Surname,
+		Patronymic:     req.Patronymic,
+		Address:        req.Address,
+		ManagerId:      optionalInt(req.ManagerId),
+	}
+	if err := service.UpdatePeople(ctx, actor(ctx), p); err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при обновлении информации о сотруднике")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (peopleServer) DeletePeople(ctx context.Context, req *trackerv1.DeletePeopleRequest) (*emptypb.Empty, error) {
+	policy, ok := taskPolicies[req.Tasks]
+	if !ok {
+		return nil, invalid("Неизвестное значение tasks")
+	}
+	if policy == database.TasksReassign && req.ReassignTo <= 0 {
+		return nil, invalid("Неверное значение reassign_to")
+	}
+	if err := service.DeletePeople(overrideContext(ctx), actor(ctx), int(req.Id), policy, int(req.ReassignTo)); err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при удалении сотрудника")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (peopleServer) RestorePeople(ctx context.Context, req *trackerv1.RestorePeopleRequest) (*emptypb.Empty, error) {
+	if err := service.RestorePeople(overrideContext(ctx), actor(ctx), int(req.Id)); err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при восстановлении сотрудника")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (peopleServer) OffboardPeople(ctx context.Context, req *trackerv1.OffboardPeopleRequest) (*trackerv1.Offboarding, error) {
+	recipients := make([]int, 0, len(req.To))
+	for _, id := range req.To {
+		recipients = append(recipients, int(id))
+	}
+	result, err := service.OffboardPeople(overrideContext(ctx), actor(ctx), int(req.Id), recipients, req.Preview)
+	if err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при передаче задач уходящего сотрудника")
+	}
+
+	resp := &trackerv1.Offboarding{PeopleId: int64(result.PeopleId), Preview: result.Preview}
+	for _, id := range result.StoppedTasks {
+		resp.StoppedTasks = append(resp.StoppedTasks, int64(id))
+	}
+	for _, r := range result.Reassignments {
+		resp.Reassignments = append(resp.Reassignments, &trackerv1.Reassignment{
+			TaskId:       int64(r.TaskId),
+			TaskName:     r.TaskName,
+			FromPeopleId: int64(r.FromPeopleId),
+			ToPeopleId:   int64(r.ToPeopleId),
+		})
+	}
+	return resp, nil
+}
+
+func peopleToProto(p model.People) *trackerv1.People {
+	return &trackerv1.People{
+		Id:             int64(p.Id),
+		PassportSerie:  p.PassportSerie,
+		PassportNumber: p.PassportNumber,
+		Name:           p.Name,
+		Surname:        p.Surname,
+		Patronymic:     p.Patronymic,
+		Address:        p.Address,
+		ManagerId:      optionalInt64(p.ManagerId),
+		DeletedAt:      optionalTimestamp(p.DeletedAt),
+	}
+}
+
+func optionalInt(value *int64) *int {
+	if value == nil {
+		return nil
+	}
+	v := int(*value)
+	return &v
+}
+
+func optionalInt64(value *int) *int64 {
+	if value == nil {
+		return nil
+	}
+	v := int64(*value)
+	return &v
+}
+
+func optionalTimestamp(value *time.Time) *timestamppb.Timestamp {
+	if value == nil {
+		return nil
+	}
+	return timestamppb.New(*value)
+}
@@ -0,0 +1,68 @@
+package grpcapi
+
+import (
+	trackerv1 "GoTimeTracker/api/proto/tracker/v1"
+	"GoTimeTracker/internal/health"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	healthgrpc "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"net"
+	"time"
+)
+
+// NewServer создает gRPC-сервер с сервисами сотрудников и задач поверх того же сервисного слоя,
+// что и REST API, а также стандартными сервисами проверки состояния и reflection.
+// Состояние сервера обновляется по результатам checker, пока ctx не отменен
+func NewServer(ctx context.Context, checker *health.Checker, interval time.Duration) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(recoverInterceptor, requestInterceptor, logInterceptor, authInterceptor),
+	)
+	trackerv1.RegisterPeopleServiceServer(server, peopleServer{})
+	trackerv1.RegisterTaskServiceServer(server, taskServer{})
+	reflection.Register(server)
+
+	healthServer := healthgrpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go watchHealth(ctx, healthServer, checker, interval)
+
+	return server
+}
+
+// Serve принимает соединения на addr до остановки server
+func Serve(server *grpc.Server, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	logger.Info("Запуск gRPC-сервера", zap.String("addr", addr))
+	return server.Serve(listener)
+}
+
+// watchHealth раз в interval переносит результат проверок готовности в сервис состояния gRPC
+func watchHealth(ctx context.Context, server *healthgrpc.Server, checker *health.Checker, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status := healthpb.HealthCheckResponse_SERVING
+		if checker.Run(ctx).Status == health.StatusFail {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		server.SetServingStatus("", status)
+		for _, service := range []string{trackerv1.PeopleService_ServiceDesc.ServiceName, trackerv1.TaskService_ServiceDesc.ServiceName} {
+			server.SetServingStatus(service, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			server.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
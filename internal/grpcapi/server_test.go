@@ -0,0 +1,255 @@
+package grpcapi
+
+import (
+	trackerv1 "GoTimeTracker/api/proto/tracker/v1"
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/health"
+	"GoTimeTracker/internal/model"
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Тесты обращаются к серверу через bufconn и проверяют то, что не требует базы данных:
+// аутентификацию, права, разбор запросов, состояние и reflection
+
+func TestMain(m *testing.M) {
+	// Токены подписываются тем же секретом, что проверяет authInterceptor
+	os.Setenv("JWT_SECRET", strings.Repeat("s", 32))
+	os.Exit(m.Run())
+}
+
+// testServer сервер на bufconn. ready определяет результат критичной проверки готовности
+type testServer struct {
+	conn  *grpc.ClientConn
+	ready atomic.Bool
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	ts := &testServer{}
+	ts.ready.Store(true)
+	checker := health.NewChecker(time.Second, 0, health.Check{Name: "database", Critical: true, Run: func(context.Context) error {
+		if !ts.ready.Load() {
+			return errors.New("нет подключения")
+		}
+		return nil
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	server := NewServer(ctx, checker, 10*time.Millisecond)
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(listener) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		server.GracefulStop()
+	})
+	ts.conn = conn
+	return ts
+}
+
+// withToken контекст вызова от имени пользователя с ролью role
+func withToken(t *testing.T, role auth.Role, peopleId *int) context.Context {
+	t.Helper()
+	token, _, err := auth.IssueToken(model.User{Id: 1, Login: string(role), Role: string(role), PeopleId: peopleId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), metadataAuthorization, "Bearer "+token)
+}
+
+func wantCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	if status.Code(err) != code {
+		t.Fatalf("код %s (%v), ожидался %s", status.Code(err), err, code)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	ts := newTestServer(t)
+	people := trackerv1.NewPeopleServiceClient(ts.conn)
+	req := &trackerv1.ListPeopleRequest{Page: 1, PageSize: 10}
+
+	_, err := people.ListPeople(context.Background(), req)
+	wantCode(t, err, codes.Unauthenticated)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), metadataAuthorization, "Bearer not-a-token")
+	_, err = people.ListPeople(ctx, req)
+	wantCode(t, err, codes.Unauthenticated)
+
+	// Проверки состояния доступны без аутентификации
+	_, err = healthpb.NewHealthClient(ts.conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	wantCode(t, err, codes.OK)
+}
+
+func TestRequestId(t *testing.T) {
+	ts := newTestServer(t)
+	people := trackerv1.NewPeopleServiceClient(ts.conn)
+	ctx := withToken(t, auth.RoleAdmin, nil)
+
+	var header metadata.MD
+	_, _ = people.ListPeople(metadata.AppendToOutgoingContext(ctx, metadataRequestId, "req-1"), &trackerv1.ListPeopleRequest{}, grpc.Header(&header))
+	if got := header.Get(metadataRequestId); len(got) != 1 || got[0] != "req-1" {
+		t.Fatalf("x-request-id %v, ожидался req-1", got)
+	}
+
+	header = nil
+	_, _ = people.ListPeople(ctx, &trackerv1.ListPeopleRequest{}, grpc.Header(&header))
+	if got := header.Get(metadataRequestId); len(got) != 1 || len(got[0]) != 32 {
+		t.Fatalf("x-request-id %v, ожидался новый идентификатор", got)
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	ts := newTestServer(t)
+	people := trackerv1.NewPeopleServiceClient(ts.conn)
+	tasks := trackerv1.NewTaskServiceClient(ts.conn)
+	employee := withToken(t, auth.RoleEmployee, nil)
+	manager := withToken(t, auth.RoleManager, nil)
+
+	_, err := people.CreatePeople(employee, &trackerv1.CreatePeopleRequest{PassportNumber: "1234 567890"})
+	wantCode(t, err, codes.PermissionDenied)
+	_, err = people.CreatePeople(manager, &trackerv1.CreatePeopleRequest{PassportNumber: "1234 567890"})
+	wantCode(t, err, codes.PermissionDenied)
+	_, err = tasks.CreateTask(employee, &trackerv1.CreateTaskRequest{Name: "Задача"})
+	wantCode(t, err, codes.PermissionDenied)
+}
+
+func TestInvalidArgument(t *testing.T) {
+	ts := newTestServer(t)
+	people := trackerv1.NewPeopleServiceClient(ts.conn)
+	tasks := trackerv1.NewTaskServiceClient(ts.conn)
+	ctx := withToken(t, auth.RoleAdmin, nil)
+	negative := int32(-5)
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"страница сотрудников", func() error {
+			_, err := people.ListPeople(ctx, &trackerv1.ListPeopleRequest{Page: 0, PageSize: 10})
+			return err
+		}},
+		{"фильтр без значения", func() error {
+			_, err := people.ListPeople(ctx, &trackerv1.ListPeopleRequest{Page: 1, PageSize: 10, FilterParam: "name"})
+			return err
+		}},
+		{"сотрудник без id", func() error {
+			_, err := people.GetPeople(ctx, &trackerv1.GetPeopleRequest{})
+			return err
+		}},
+		{"паспорт", func() error {
+			_, err := people.CreatePeople(ctx, &trackerv1.CreatePeopleRequest{PassportNumber: "12 34"})
+			return err
+		}},
+		{"политика задач", func() error {
+			_, err := people.DeletePeople(ctx, &trackerv1.DeletePeopleRequest{Id: 1, Tasks: 99})
+			return err
+		}},
+		{"передача задач без получателя", func() error {
+			_, err := people.DeletePeople(ctx, &trackerv1.DeletePeopleRequest{Id: 1, Tasks: trackerv1.TaskPolicy_TASK_POLICY_REASSIGN})
+			return err
+		}},
+		{"задача без id", func() error {
+			_, err := tasks.GetTask(ctx, &trackerv1.TaskRequest{})
+			return err
+		}},
+		{"отрицательная оценка", func() error {
+			_, err := tasks.SetTaskEstimate(ctx, &trackerv1.SetTaskEstimateRequest{TaskId: 1, EstimateMinutes: &negative})
+			return err
+		}},
+		{"группировка отчета", func() error {
+			_, err := tasks.GetEstimateReport(ctx, &trackerv1.EstimateReportRequest{GroupBy: 99})
+			return err
+		}},
+		{"период отчета о времени", func() error {
+			_, err := tasks.GetWorklogReport(ctx, &trackerv1.WorklogReportRequest{PeopleId: 1, Period: 99})
+			return err
+		}},
+		{"отчет о времени без сотрудника", func() error {
+			_, err := tasks.GetWorklogReport(ctx, &trackerv1.WorklogReportRequest{})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantCode(t, tt.call(), codes.InvalidArgument)
+		})
+	}
+}
+
+func TestHealth(t *testing.T) {
+	ts := newTestServer(t)
+	client := healthpb.NewHealthClient(ts.conn)
+
+	waitStatus := func(want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: trackerv1.TaskService_ServiceDesc.ServiceName})
+			if err == nil && resp.Status == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("состояние %v (%v), ожидалось %s", resp, err, want)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	waitStatus(healthpb.HealthCheckResponse_SERVING)
+	ts.ready.Store(false)
+	waitStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	ts.ready.Store(true)
+	waitStatus(healthpb.HealthCheckResponse_SERVING)
+}
+
+func TestReflection(t *testing.T) {
+	ts := newTestServer(t)
+	stream, err := reflectionpb.NewServerReflectionClient(ts.conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.Name)
+	}
+	for _, want := range []string{trackerv1.PeopleService_ServiceDesc.ServiceName, trackerv1.TaskService_ServiceDesc.ServiceName, "grpc.health.v1.Health"} {
+		if !slices.Contains(services, want) {
+			t.Errorf("reflection не знает %s: %v", want, services)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package grpcapi
+
+import (
+	trackerv1 "GoTimeTracker/api/proto/tracker/v1"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/internal/service"
+	"context"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// taskServer реализация trackerv1.TaskService поверх internal/service
+type taskServer struct {
+	trackerv1.UnimplementedTaskServiceServer
+}
+
+// reportGroups группировки отчета по оценкам, см. database.GetEstimateReport
+var reportGroups = map[trackerv1.ReportGroup]string{
+	trackerv1.ReportGroup_REPORT_GROUP_UNSPECIFIED: "task",
+	trackerv1.ReportGroup_REPORT_GROUP_TASK:        "task",
+	trackerv1.ReportGroup_REPORT_GROUP_PEOPLE:      "people",
+	trackerv1.ReportGroup_REPORT_GROUP_PROJECT:     "project",
+}
+
+// worklogPeriods периоды отчета о времени, см. model.PeriodBounds
+var worklogPeriods = map[trackerv1.WorklogPeriod]string{
+	trackerv1.WorklogPeriod_WORKLOG_PERIOD_UNSPECIFIED: model.TimesheetWeek,
+	trackerv1.WorklogPeriod_WORKLOG_PERIOD_WEEK:        model.TimesheetWeek,
+	trackerv1.WorklogPeriod_WORKLOG_PERIOD_MONTH:       model.TimesheetMonth,
+}
+
+func (taskServer) ListTasks(ctx context.Context, req *trackerv1.ListTasksRequest) (*trackerv1.ListTasksResponse, error) {
+	tasks, err := service.GetPeopleTasks(ctx, actor(ctx), int(req.PeopleId), req.IncludeDeleted)
+	if err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при получении задач для сотрудника")
+	}
+	resp := &trackerv1.ListTasksResponse{Tasks: make([]*trackerv1.Task, 0, len(tasks))}
+	for _, t := range tasks {
+		resp.Tasks = append(resp.Tasks, taskToProto(t))
+	}
+	return resp, nil
+}
+
+func (taskServer) GetTask(ctx context.Context, req *trackerv1.TaskRequest) (*trackerv1.Task, error) {
+	if req.TaskId <= 0 {
+		return nil, invalid("Неверное значение task_id")
+	}
+	task, err := service.GetTask(ctx, actor(ctx), int(req.TaskId))
+	if err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при получении задачи")
+	}
+	return taskToProto(task), nil
+}
+
+func (taskServer) CreateTask(ctx context.Context, req *trackerv1.CreateTaskRequest) (*trackerv1.CreateTaskResponse, error) {
+	estimate, err := estimateFromProto(req.EstimateMinutes)
+	if err != nil {
+		return nil, err
+	}
+	task := model.Task{Name: req.Name, Description: req.Description, Project: req.Project, EstimateMinutes: estimate}
+	id, err := service.AddTask(ctx, actor(ctx), task)
+	if err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при добавлении задачи")
+	}
+	return &trackerv1.CreateTaskResponse{Id: int64(id)}, nil
+}
+
+func (taskServer) AssignTask(ctx context.Context, req *trackerv1.AssignTaskRequest) (*emptypb.Empty, error) {
+	if err := service.AssignPeopleOnTask(overrideContext(ctx), actor(ctx), int(req.TaskId), int(req.PeopleId)); err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при назначении сотрудника на задачу")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (taskServer) SetTaskEstimate(ctx context.Context, req *trackerv1.SetTaskEstimateRequest) (*emptypb.Empty, error) {
+	estimate, err := estimateFromProto(req.EstimateMinutes)
+	if err != nil {
+		return nil, err
+	}
+	if err = service.SetTaskEstimate(ctx, actor(ctx), int(req.TaskId), estimate); err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при обновлении оценки задачи")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (taskServer) StartTask(ctx context.Context, req *trackerv1.TaskRequest) (*emptypb.Empty, error) {
+	if err := service.StartTask(overrideContext(ctx), actor(ctx), int(req.TaskId)); err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при обновлении времени начала задачи")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (taskServer) EndTask(ctx context.Context, req *trackerv1.TaskRequest) (*emptypb.Empty, error) {
+	if err := service.EndTask(overrideContext(ctx), actor(ctx), int(req.TaskId)); err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при обновлении времени завершения задачи")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (taskServer) DeleteTask(ctx context.Context, req *trackerv1.TaskRequest) (*emptypb.Empty, error) {
+	if err := service.DeleteTask(overrideContext(ctx), actor(ctx), int(req.TaskId)); err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при удалении задачи")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (taskServer) RestoreTask(ctx context.Context, req *trackerv1.TaskRequest) (*emptypb.Empty, error) {
+	if err := service.RestoreTask(overrideContext(ctx), actor(ctx), int(req.TaskId)); err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при восстановлении задачи")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (taskServer) GetEstimateReport(ctx context.Context, req *trackerv1.EstimateReportRequest) (*trackerv1.EstimateReportResponse, error) {
+	groupBy, ok := reportGroups[req.GroupBy]
+	if !ok {
+		return nil, invalid("Неизвестная группировка отчета")
+	}
+	report, err := service.GetEstimateReport(ctx, actor(ctx), groupBy)
+	if err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при построении отчета по оценкам")
+	}
+	resp := &trackerv1.EstimateReportResponse{Rows: make([]*trackerv1.EstimateReport, 0, len(report))}
+	for _, row := range report {
+		resp.Rows = append(resp.Rows, &trackerv1.EstimateReport{
+			Key:             row.Key,
+			Name:            row.Name,
+			Tasks:           int32(row.Tasks),
+			EstimateMinutes: int32(row.EstimateMinutes),
+			ActualMinutes:   int32(row.ActualMinutes),
+			Accuracy:        row.Accuracy,
+		})
+	}
+	return resp, nil
+}
+
+func (taskServer) GetWorklogReport(ctx context.Context, req *trackerv1.WorklogReportRequest) (*trackerv1.WorklogReport, error) {
+	period, ok := worklogPeriods[req.Period]
+	if !ok {
+		return nil, invalid("Неизвестный период отчета")
+	}
+	peopleId := int(req.PeopleId)
+	if peopleId == 0 {
+		identity := actor(ctx)
+		if identity.PeopleId == nil {
+			return nil, invalid("Не указан people_id")
+		}
+		peopleId = *identity.PeopleId
+	}
+	now := time.Now()
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if req.Date != nil {
+		if err := req.Date.CheckValid(); err != nil {
+			return nil, invalid("Неверное значение date")
+		}
+		date = req.Date.AsTime()
+	}
+
+	ts, err := service.GetTimesheet(ctx, actor(ctx), peopleId, period, date)
+	if err != nil {
+		return nil, serviceError(ctx, err, "Ошибка при построении отчета о времени")
+	}
+	resp := &trackerv1.WorklogReport{
+		PeopleId:        int64(ts.PeopleId),
+		PeriodStart:     timestamppb.New(ts.PeriodStart),
+		PeriodEnd:       timestamppb.New(ts.PeriodEnd),
+		TotalMinutes:    int32(ts.TotalMinutes),
+		TimesheetId:     int64(ts.Id),
+		TimesheetStatus: ts.Status,
+	}
+	for _, entry := range ts.Entries {
+		resp.Entries = append(resp.Entries, &trackerv1.TimeEntry{
+			TaskId:  int64(entry.TaskId),
+			Start:   timestamppb.New(entry.Start),
+			End:     optionalTimestamp(entry.End),
+			Minutes: int32(entry.Minutes),
+		})
+	}
+	return resp, nil
+}
+
+// estimateFromProto оценка задачи из запроса, nil если она не задана
+func estimateFromProto(value *int32) (*int, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if *value <= 0 {
+		return nil, invalid("оценка задачи должна быть положительной")
+	}
+	estimate := int(*value)
+	return &estimate, nil
+}
+
+func taskToProto(t model.Task) *trackerv1.Task {
+	return &trackerv1.Task{
+		Id:               int64(t.Id),
+		PeopleId:         int64(t.PeopleId),
+		Name:             t.Name,
+		Description:      t.Description,
+		Project:          t.Project,
+		EstimateMinutes:  optionalInt32(t.EstimateMinutes),
+		TimeStart:        optionalTimestamp(t.TimeStart),
+		TimeEnd:          optionalTimestamp(t.TimeEnd),
+		Duration:         optionalString(t.Duration),
+		ActualMinutes:    int32(t.ActualMinutes),
+		RemainingMinutes: optionalInt32(t.RemainingMinutes),
+		OverEstimate:     t.OverEstimate,
+		DeletedAt:        optionalTimestamp(t.DeletedAt),
+	}
+}
+
+func optionalInt32(value *int) *int32 {
+	if value == nil {
+		return nil
+	}
+	v := int32(*value)
+	return &v
+}
+
+// optionalString пустая строка для отсутствующего значения: у незавершенной задачи нет продолжительности
+func optionalString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,149 @@
+package health
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Состояния проверок и сервиса в целом
+const (
+	StatusOk       = "ok"
+	StatusFail     = "fail"
+	StatusDegraded = "degraded"
+	StatusDisabled = "disabled"
+)
+
+// Check проверка зависимости. Run возвращает nil, если зависимость доступна.
+// Если Run равна nil, зависимость не настроена и проверка отображается как disabled.
+// Отказ некритичной зависимости не делает сервис неготовым
+type Check struct {
+	Name     string
+	Critical bool
+	Run      func(ctx context.Context) error
+}
+
+// Result результат проверки одной зависимости
+type Result struct {
+	Status     string    `json:"status" example:"ok"`
+	Critical   bool      `json:"critical"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Report состояние сервиса и каждой зависимости
+type Report struct {
+	Status string            `json:"status" example:"ok"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Checker выполняет проверки готовности с ограничением времени и кэширует результаты,
+// чтобы частые запросы балансировщика и оркестратора не нагружали зависимости
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+	ttl     time.Duration
+
+	mutex sync.Mutex
+	cache map[string]Result
+}
+
+// NewChecker создает набор проверок. timeout ограничивает каждую проверку,
+// ttl — время, в течение которого результат берется из кэша
+func NewChecker(timeout, ttl time.Duration, checks ...Check) *Checker {
+	return &Checker{checks: checks, timeout: timeout, ttl: ttl, cache: make(map[string]Result)}
+}
+
+// Run выполняет устаревшие проверки параллельно и возвращает общее состояние
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	var resultMutex sync.Mutex
+	now := time.Now()
+	for _, check := range c.checks {
+		if cached, ok := c.cache[check.Name]; ok && now.Sub(cached.CheckedAt) < c.ttl {
+			continue
+		}
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			result := c.run(ctx, check)
+			resultMutex.Lock()
+			c.cache[check.Name] = result
+			resultMutex.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOk, Checks: make(map[string]Result, len(c.checks))}
+	for _, check := range c.checks {
+		result := c.cache[check.Name]
+		report.Checks[check.Name] = result
+		if result.Status != StatusFail {
+			continue
+		}
+		if check.Critical {
+			report.Status = StatusFail
+		} else if report.Status == StatusOk {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	result := Result{Status: StatusDisabled, Critical: check.Critical, CheckedAt: time.Now()}
+	if check.Run == nil {
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	err := check.Run(ctx)
+	result.DurationMs = time.Since(result.CheckedAt).Milliseconds()
+	result.Status = StatusOk
+	if err != nil {
+		result.Status, result.Error = StatusFail, err.Error()
+	}
+	return result
+}
+
+// Liveness godoc
+//
+//	@Summary		Проверка жизнеспособности
+//	@Description	Отвечает 200, пока процесс обслуживает запросы. Зависимости не проверяются
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	Report
+//	@Router			/healthz [get]
+func Liveness() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, Report{Status: StatusOk})
+	}
+}
+
+// Readiness godoc
+//
+//	@Summary		Проверка готовности
+//	@Description	Проверяет базу данных, схему и внешний API. Отвечает 503, если недоступна критичная
+//	@Description	зависимость. Отказ внешнего API отображается как degraded, сервис остается готовым
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	Report
+//	@Failure		503	{object}	Report
+//	@Router			/readyz [get]
+func (c *Checker) Readiness() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		report := c.Run(ctx.Request.Context())
+		status := http.StatusOK
+		if report.Status == StatusFail {
+			status = http.StatusServiceUnavailable
+		}
+		ctx.JSON(status, report)
+	}
+}
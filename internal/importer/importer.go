@@ -0,0 +1,283 @@
+package importer
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/enrichment"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Статусы строк отчета импорта
+const (
+	StatusCreated     = "created"
+	StatusWouldCreate = "would_create"
+	StatusInvalid     = "invalid"
+	StatusDuplicate   = "duplicate"
+	StatusFailed      = "failed"
+	StatusSkipped     = "skipped"
+)
+
+// DefaultConcurrency количество одновременных запросов к API обогащения по умолчанию
+const DefaultConcurrency = 4
+
+// MaxConcurrency наибольшее допустимое количество одновременных запросов к API обогащения
+const MaxConcurrency = 32
+
+// passportColumn колонка CSV и поле JSON с паспортом, как в POST /people
+const passportColumn = "passportNumber"
+
+// Options параметры импорта
+type Options struct {
+	// DryRun только проверяет данные и показывает результат, ничего не записывая
+	DryRun bool `json:"dry_run"`
+	// Atomic добавляет сотрудников только если все строки корректны, иначе ни одного
+	Atomic bool `json:"atomic"`
+	// Concurrency ограничение одновременных запросов к API обогащения
+	Concurrency int `json:"concurrency"`
+}
+
+// Row строка импорта и результат ее обработки
+type Row struct {
+	Line     int           `json:"line"`
+	Passport string        `json:"passport"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	PeopleId int           `json:"people_id,omitempty"`
+	People   *model.People `json:"people,omitempty"`
+}
+
+// Report результат импорта по каждой строке
+type Report struct {
+	Options   Options `json:"options"`
+	Committed bool    `json:"committed"`
+	Total     int     `json:"total"`
+	Created   int     `json:"created"`
+	Rejected  int     `json:"rejected"`
+	Rows      []Row   `json:"rows"`
+}
+
+// Parse читает список паспортов из CSV с колонкой passportNumber
+// или из JSON-массива объектов {"passportNumber": "1234 567890"}
+func Parse(r io.Reader, format string) ([]Row, error) {
+	switch format {
+	case "csv":
+		return parseCSV(r)
+	case "json":
+		return parseJSON(r)
+	}
+	return nil, fmt.Errorf("неподдерживаемый формат импорта: %s", format)
+}
+
+func parseCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать заголовок CSV: %w", err)
+	}
+	column := -1
+	for i, name := range header {
+		if strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")) == passportColumn {
+			column = i
+		}
+	}
+	if column < 0 {
+		return nil, fmt.Errorf("в CSV нет колонки %s", passportColumn)
+	}
+
+	var rows []Row
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := Row{Line: line}
+		if column < len(record) {
+			row.Passport = record[column]
+		}
+		rows = append(rows, row)
+	}
+}
+
+func parseJSON(r io.Reader) ([]Row, error) {
+	var items []map[string]string
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать JSON: %w", err)
+	}
+
+	rows := make([]Row, len(items))
+	for i, item := range items {
+		rows[i] = Row{Line: i + 1, Passport: item[passportColumn]}
+	}
+	return rows, nil
+}
+
+// Run проверяет строки, отбрасывает дубликаты, обогащает данные через client (если он задан)
+// и добавляет сотрудников согласно opts от имени actor
+func Run(ctx context.Context, db *database.Database, client *enrichment.Client, actor model.AuditActor, rows []Row, opts Options) (Report, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	opts.Concurrency = min(opts.Concurrency, MaxConcurrency)
+	report := Report{Options: opts, Total: len(rows), Rows: rows}
+	logger.Ctx(ctx).Info("Начат импорт сотрудников", zap.Int("rows", len(rows)), zap.Bool("dryRun", opts.DryRun), zap.Bool("atomic", opts.Atomic))
+
+	people := make([]model.People, len(rows))
+	seen := make(map[model.Passport]int)
+	var passports []model.Passport
+	for i := range rows {
+		key, err := model.ParsePassport(rows[i].Passport)
+		if err != nil {
+			rows[i].Status, rows[i].Error = StatusInvalid, err.Error()
+			continue
+		}
+		if line, ok := seen[key]; ok {
+			rows[i].Status, rows[i].Error = StatusDuplicate, fmt.Sprintf("паспорт уже указан в строке %d", line)
+			continue
+		}
+		seen[key] = rows[i].Line
+		passports = append(passports, key)
+		people[i] = model.People{PassportSerie: key.Serie, PassportNumber: key.Number}
+	}
+
+	if len(passports) > 0 {
+		existing, err := db.FindPeopleByPassports(ctx, passports)
+		if err != nil {
+			return report, err
+		}
+		for i := range rows {
+			if rows[i].Status != "" {
+				continue
+			}
+			if id, ok := existing[people[i].Passport()]; ok {
+				rows[i].Status, rows[i].Error, rows[i].PeopleId = StatusDuplicate, "сотрудник с таким паспортом уже существует", id
+			}
+		}
+	}
+
+	if client != nil {
+		if err := enrich(ctx, client, rows, people, opts.Concurrency); err != nil {
+			logger.Ctx(ctx).Info("Импорт сотрудников прерван", zap.Error(err))
+			return report, err
+		}
+	}
+
+	var pending []int
+	for i := range rows {
+		if rows[i].Status == "" {
+			pending = append(pending, i)
+			rows[i].People = &people[i]
+		}
+	}
+	report.Rejected = len(rows) - len(pending)
+
+	switch {
+	case opts.DryRun:
+		for _, i := range pending {
+			rows[i].Status = StatusWouldCreate
+		}
+	case opts.Atomic:
+		commitAtomic(ctx, db, actor, &report, people, pending)
+	default:
+		for _, i := range pending {
+			id, err := db.AddPeople(ctx, actor, people[i])
+			var duplicate *database.DuplicatePassportError
+			if errors.As(err, &duplicate) {
+				rows[i].Status, rows[i].Error, rows[i].PeopleId = StatusDuplicate, err.Error(), duplicate.PeopleId
+				report.Rejected++
+				continue
+			}
+			if err != nil {
+				rows[i].Status, rows[i].Error = StatusFailed, err.Error()
+				report.Rejected++
+				continue
+			}
+			rows[i].Status, rows[i].PeopleId = StatusCreated, id
+			people[i].Id = id
+			report.Created++
+		}
+		report.Committed = report.Created > 0
+	}
+
+	logger.Ctx(ctx).Info("Импорт сотрудников завершен", zap.Int("created", report.Created), zap.Int("rejected", report.Rejected), zap.Bool("committed", report.Committed))
+	return report, nil
+}
+
+// commitAtomic добавляет сотрудников одной транзакцией, если ни одна строка не отклонена
+func commitAtomic(ctx context.Context, db *database.Database, actor model.AuditActor, report *Report, people []model.People, pending []int) {
+	rows := report.Rows
+	if report.Rejected > 0 || len(pending) == 0 {
+		for _, i := range pending {
+			rows[i].Status, rows[i].Error = StatusSkipped, "импорт отменен из-за ошибок в других строках"
+		}
+		return
+	}
+
+	batch := make([]model.People, len(pending))
+	for j, i := range pending {
+		batch[j] = people[i]
+	}
+	ids, err := db.AddPeopleBatch(ctx, actor, batch)
+	if err != nil {
+		for _, i := range pending {
+			rows[i].Status, rows[i].Error = StatusFailed, err.Error()
+		}
+		report.Rejected = len(rows)
+		return
+	}
+
+	for j, i := range pending {
+		rows[i].Status, rows[i].PeopleId = StatusCreated, ids[j]
+		people[i].Id = ids[j]
+	}
+	report.Created = len(pending)
+	report.Committed = true
+}
+
+// enrich запрашивает данные для еще не отклоненных строк пулом из limit обработчиков.
+// При отмене ctx новые запросы не начинаются, и возвращается ошибка контекста
+func enrich(ctx context.Context, client *enrichment.Client, rows []Row, people []model.People, limit int) error {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range limit {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				info, err := client.Info(ctx, people[i].Passport())
+				if err != nil {
+					rows[i].Status, rows[i].Error = StatusFailed, fmt.Sprintf("ошибка обогащения: %s", err)
+					continue
+				}
+				info.Apply(&people[i])
+			}
+		}()
+	}
+
+	defer wg.Wait()
+	defer close(jobs)
+	for i := range rows {
+		if rows[i].Status != "" {
+			continue
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,112 @@
+package importer
+
+import (
+	"GoTimeTracker/internal/enrichment"
+	"GoTimeTracker/internal/model"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// infoServer отвечает на /info, считая одновременные запросы. Ответ задерживается на delay
+func infoServer(t *testing.T, delay time.Duration) (*enrichment.Client, *atomic.Int32, *atomic.Int32) {
+	t.Helper()
+	var active, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		// Поля вне ФИО и адреса, например руководитель, не переносятся в сотрудника
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": "Иван", "surname": "Иванов", "manager_id": 1})
+	}))
+	t.Cleanup(srv.Close)
+	return enrichment.NewClient(srv.URL, 5*time.Second), &active, &peak
+}
+
+func importRows(n int) ([]Row, []model.People) {
+	rows := make([]Row, n)
+	people := make([]model.People, n)
+	for i := range rows {
+		rows[i] = Row{Line: i + 1}
+		people[i] = model.People{PassportSerie: "1234", PassportNumber: fmt.Sprintf("%06d", i)}
+	}
+	return rows, people
+}
+
+func TestEnrichLimitsConcurrency(t *testing.T) {
+	client, _, peak := infoServer(t, 10*time.Millisecond)
+	rows, people := importRows(40)
+	rows[3].Status = StatusInvalid
+
+	if err := enrich(context.Background(), client, rows, people, 4); err != nil {
+		t.Fatalf("enrich: %v", err)
+	}
+	if got := peak.Load(); got > 4 {
+		t.Errorf("одновременных запросов %d, ожидалось не больше 4", got)
+	}
+	for i, row := range rows {
+		if i == 3 {
+			if people[i].Surname != "" {
+				t.Errorf("отклоненная строка %d обогащена", i)
+			}
+			continue
+		}
+		if row.Status != "" || people[i].Surname != "Иванов" {
+			t.Errorf("строка %d: статус %q, фамилия %q", i, row.Status, people[i].Surname)
+		}
+		if people[i].ManagerId != nil {
+			t.Errorf("строка %d: руководитель %d из внешнего API", i, *people[i].ManagerId)
+		}
+		if people[i].PassportNumber != fmt.Sprintf("%06d", i) {
+			t.Errorf("строка %d: паспорт %q", i, people[i].PassportNumber)
+		}
+	}
+}
+
+func TestEnrichStopsOnCancel(t *testing.T) {
+	client, active, _ := infoServer(t, time.Minute)
+	rows, people := importRows(1000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	done := make(chan error, 1)
+	go func() { done <- enrich(ctx, client, rows, people, 8) }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("enrich вернул %v, ожидалась отмена", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("enrich не остановился после отмены контекста")
+	}
+
+	started := 0
+	for _, row := range rows {
+		if row.Status == StatusFailed {
+			started++
+		}
+	}
+	if started > 8 {
+		t.Errorf("после отмены начато %d запросов, ожидалось не больше 8", started)
+	}
+	if n := active.Load(); n > 8 {
+		t.Errorf("запросов в работе %d", n)
+	}
+}
@@ -0,0 +1,183 @@
+package metrics
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// namespace префикс всех метрик сервиса
+const namespace = "tracker"
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Количество HTTP-запросов по маршруту и статусу ответа",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Время обработки HTTP-запросов по маршруту и статусу ответа",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Время выполнения методов Database, включая ожидание блокировки",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"method"})
+
+	enrichmentRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "enrichment_requests_total",
+		Help:      "Запросы к внешнему API /info по результату",
+	}, []string{"outcome"})
+
+	enrichmentDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "enrichment_request_duration_seconds",
+		Help:      "Время ответа внешнего API /info",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	eventsPublished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_published_total",
+		Help:      "События потоковых обновлений по типу",
+	}, []string{"type"})
+
+	eventSubscribersDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "event_subscribers_dropped_total",
+		Help:      "Подписчики на события, отключенные из-за переполнения очереди",
+	})
+
+	webhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Попытки доставки вебхуков по результату",
+	}, []string{"outcome"})
+
+	webhookDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "webhook_delivery_duration_seconds",
+		Help:      "Время ответа получателей вебхуков",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	outboxDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbox_deliveries_total",
+		Help:      "Попытки доставки доменных событий получателю по результату",
+	}, []string{"sink", "outcome"})
+)
+
+func init() {
+	prometheus.MustRegister(httpRequests, httpDuration, queryDuration, enrichmentRequests, enrichmentDuration,
+		eventsPublished, eventSubscribersDropped, webhookDeliveries, webhookDuration,
+		outboxDeliveries)
+}
+
+// Handler отдает метрики в формате Prometheus. Подключается к внутреннему серверу метрик,
+// а не к публичному API
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Middleware считает запросы и время их обработки по шаблону маршрута, чтобы параметры пути
+// не порождали новые ряды. Запросы к неизвестным маршрутам собираются под route="unmatched"
+func Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(ctx.Writer.Status())
+		httpRequests.WithLabelValues(ctx.Request.Method, route, status).Inc()
+		httpDuration.WithLabelValues(ctx.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Query начинает замер метода Database, возвращает функцию для завершения замера:
+//
+//	defer metrics.Query("GetAllPeople")()
+func Query(method string) func() {
+	start := time.Now()
+	return func() {
+		queryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Результаты запросов к внешнему API
+const (
+	EnrichmentSuccess = "success"
+	EnrichmentError   = "error"
+	EnrichmentTimeout = "timeout"
+	EnrichmentStatus  = "bad_status"
+	EnrichmentDecode  = "bad_response"
+)
+
+// Enrichment учитывает запрос к внешнему API с результатом outcome
+func Enrichment(outcome string, duration time.Duration) {
+	enrichmentRequests.WithLabelValues(outcome).Inc()
+	enrichmentDuration.Observe(duration.Seconds())
+}
+
+// EventPublished учитывает опубликованное событие
+func EventPublished(eventType string) {
+	eventsPublished.WithLabelValues(eventType).Inc()
+}
+
+// EventSubscriberDropped учитывает подписчика, отключенного из-за переполнения очереди
+func EventSubscriberDropped() {
+	eventSubscribersDropped.Inc()
+}
+
+// Результаты попыток доставки вебхуков
+const (
+	WebhookDelivered = "delivered"
+	WebhookRetry     = "retry"
+	WebhookFailed    = "failed"
+)
+
+// WebhookDelivery учитывает попытку доставки вебхука с результатом outcome
+func WebhookDelivery(outcome string, duration time.Duration) {
+	webhookDeliveries.WithLabelValues(outcome).Inc()
+	webhookDuration.Observe(duration.Seconds())
+}
+
+// Результаты доставки доменного события получателю
+const (
+	OutboxDelivered = "delivered"
+	OutboxFailed    = "failed"
+)
+
+// OutboxDelivery учитывает попытку доставки доменного события получателю sink
+func OutboxDelivery(sink, outcome string) {
+	outboxDeliveries.WithLabelValues(sink, outcome).Inc()
+}
+
+// RegisterDB публикует статистику пула соединений (sql.DB.Stats)
+func RegisterDB(db *sql.DB, name string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterGauge публикует показатель, значение которого вычисляется fn при каждом сборе метрик
+func RegisterGauge(name, help string, fn func() float64) {
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, fn))
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditActor кто и откуда выполняет изменение
+type AuditActor struct {
+	Kind      string
+	Id        int
+	Name      string
+	RequestId string
+	ClientIp  string
+	// OverrideReason причина изменения в закрытом периоде. Задается только для администраторов
+	OverrideReason string
+}
+
+// AuditEntry запись журнала изменений. Diff содержит только изменившиеся поля
+// в виде {"поле": {"before": ..., "after": ...}}
+type AuditEntry struct {
+	Id        int64           `db:"id" json:"id"`
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+	ActorKind string          `db:"actor_kind" json:"actor_kind"`
+	ActorId   int             `db:"actor_id" json:"actor_id"`
+	ActorName string          `db:"actor_name" json:"actor_name"`
+	Action    string          `db:"action" json:"action" example:"update"`
+	Entity    string          `db:"entity" json:"entity" example:"people"`
+	EntityId  int             `db:"entity_id" json:"entity_id"`
+	Diff      json.RawMessage `db:"diff" json:"diff" swaggertype:"object"`
+	RequestId string          `db:"request_id" json:"request_id,omitempty"`
+	ClientIp  string          `db:"client_ip" json:"client_ip,omitempty"`
+	// OverrideReason причина изменения в закрытом периоде
+	OverrideReason *string `db:"override_reason" json:"override_reason,omitempty"`
+}
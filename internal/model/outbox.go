@@ -0,0 +1,23 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent доменное событие, записанное вместе с изменением сущности aggregate
+type OutboxEvent struct {
+	Id          int64   `db:"id" json:"id"`
+	Aggregate   string  `db:"aggregate" json:"aggregate" example:"task"`
+	AggregateId int     `db:"aggregate_id" json:"aggregate_id"`
+	EventType   string  `db:"event_type" json:"event_type" example:"timer.stopped"`
+	PeopleId    *int    `db:"people_id" json:"people_id,omitempty"`
+	Project     *string `db:"project" json:"project,omitempty"`
+	// Payload строка сущности после изменения (до удаления для удаления) без паспорта
+	Payload       json.RawMessage `db:"payload" json:"payload" swaggertype:"object"`
+	CreatedAt     time.Time       `db:"created_at" json:"created_at"`
+	Attempts      int             `db:"attempts" json:"attempts"`
+	NextAttemptAt time.Time       `db:"next_attempt_at" json:"next_attempt_at"`
+	LastError     *string         `db:"last_error" json:"last_error,omitempty"`
+	DispatchedAt  *time.Time      `db:"dispatched_at" json:"dispatched_at,omitempty"`
+}
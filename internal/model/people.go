@@ -1,11 +1,51 @@
 package model
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
 type People struct {
-	Id             int    `db:"id" json:"id"`
-	PassportSerie  int    `db:"passport_serie" json:"passport_serie"`
-	PassportNumber int    `db:"passport_number" json:"passport_number"`
-	Name           string `db:"name" json:"name"`
-	Surname        string `db:"surname" json:"surname"`
-	Patronymic     string `db:"patronymic" json:"patronymic,omitempty"`
-	Address        string `db:"address" json:"address"`
+	Id             int        `db:"id" json:"id"`
+	PassportSerie  string     `db:"passport_serie" json:"passport_serie,omitempty" example:"0123"`
+	PassportNumber string     `db:"passport_number" json:"passport_number,omitempty" example:"045678"`
+	Name           string     `db:"name" json:"name"`
+	Surname        string     `db:"surname" json:"surname"`
+	Patronymic     string     `db:"patronymic" json:"patronymic,omitempty"`
+	Address        string     `db:"address" json:"address"`
+	ManagerId      *int       `db:"manager_id" json:"manager_id,omitempty"`
+	DeletedAt      *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+}
+
+// Passport серия и номер паспорта. Хранятся строками, чтобы сохранить ведущие нули
+type Passport struct {
+	Serie  string
+	Number string
+}
+
+var (
+	passportSerieFormat  = regexp.MustCompile(`^[0-9]{4}$`)
+	passportNumberFormat = regexp.MustCompile(`^[0-9]{6}$`)
+)
+
+// ParsePassport разбирает паспорт в формате "серия номер": 4 цифры серии и 6 цифр номера
+func ParsePassport(passport string) (Passport, error) {
+	parts := strings.Split(strings.TrimSpace(passport), " ")
+	if len(parts) != 2 {
+		return Passport{}, fmt.Errorf("Неверный формат номера паспорта")
+	}
+	if !passportSerieFormat.MatchString(parts[0]) {
+		return Passport{}, fmt.Errorf("Серия паспорта должна состоять из 4 цифр")
+	}
+	if !passportNumberFormat.MatchString(parts[1]) {
+		return Passport{}, fmt.Errorf("Номер паспорта должен состоять из 6 цифр")
+	}
+	return Passport{Serie: parts[0], Number: parts[1]}, nil
+}
+
+// Passport возвращает паспорт сотрудника
+func (p People) Passport() Passport {
+	return Passport{Serie: p.PassportSerie, Number: p.PassportNumber}
 }
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// ClosedPeriod закрытый период [PeriodStart, PeriodEnd). Время задач, пересекающееся с ним,
+// изменяется только администратором с указанием причины
+type ClosedPeriod struct {
+	Id          int       `db:"id" json:"id"`
+	PeriodStart time.Time `db:"period_start" json:"period_start" example:"2024-06-01T00:00:00Z"`
+	PeriodEnd   time.Time `db:"period_end" json:"period_end" example:"2024-07-01T00:00:00Z"`
+	Comment     *string   `db:"comment" json:"comment,omitempty"`
+	// ClosedBy кто закрыл период
+	ClosedBy  string    `db:"closed_by" json:"closed_by"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Причины блокировки изменения времени
+const (
+	LockClosed    = "closed"
+	LockTimesheet = "timesheet"
+)
+
+// LockedEdit попытка изменить время в закрытом периоде или утвержденном табеле. Overridden
+// означает, что администратор выполнил изменение, указав причину
+type LockedEdit struct {
+	Id             int64     `db:"id" json:"id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	ActorKind      string    `db:"actor_kind" json:"actor_kind"`
+	ActorId        int       `db:"actor_id" json:"actor_id"`
+	ActorName      string    `db:"actor_name" json:"actor_name"`
+	Action         string    `db:"action" json:"action" example:"stop"`
+	Entity         string    `db:"entity" json:"entity" example:"task"`
+	EntityId       int       `db:"entity_id" json:"entity_id"`
+	Reason         string    `db:"reason" json:"reason" example:"closed"`
+	Overridden     bool      `db:"overridden" json:"overridden"`
+	OverrideReason *string   `db:"override_reason" json:"override_reason,omitempty"`
+	RequestId      string    `db:"request_id" json:"request_id,omitempty"`
+	ClientIp       string    `db:"client_ip" json:"client_ip,omitempty"`
+}
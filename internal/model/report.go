@@ -0,0 +1,13 @@
+package model
+
+// EstimateReport сравнение оценки и фактически затраченного времени.
+// Accuracy — отношение факта к оценке: 1 означает точную оценку,
+// больше 1 — задачи заняли больше запланированного
+type EstimateReport struct {
+	Key             string  `db:"key" json:"key"`
+	Name            string  `db:"name" json:"name"`
+	Tasks           int     `db:"tasks" json:"tasks"`
+	EstimateMinutes int     `db:"estimate_minutes" json:"estimate_minutes"`
+	ActualMinutes   int     `db:"actual_minutes" json:"actual_minutes"`
+	Accuracy        float64 `db:"accuracy" json:"accuracy"`
+}
@@ -8,44 +8,107 @@ import (
 )
 
 type Task struct {
-	Id          int       `db:"id" json:"id"`
-	PeopleId    int       `db:"people_id" json:"people_id,omitempty"`
-	Name        string    `db:"name" json:"name"`
-	Description string    `db:"description" json:"description"`
-	TimeStart   time.Time `db:"time_start" json:"time_start,omitempty"`
-	TimeEnd     time.Time `db:"time_end" json:"time_end,omitempty"`
-	Duration    string    `db:"duration" json:"duration,omitempty"`
+	Id               int        `db:"id" json:"id"`
+	PeopleId         int        `db:"people_id" json:"people_id,omitempty"`
+	Name             string     `db:"name" json:"name"`
+	Description      string     `db:"description" json:"description"`
+	Project          *string    `db:"project" json:"project,omitempty"`
+	EstimateMinutes  *int       `db:"estimate_minutes" json:"estimate_minutes,omitempty"`
+	TimeStart        *time.Time `db:"time_start" json:"time_start,omitempty"`
+	TimeEnd          *time.Time `db:"time_end" json:"time_end,omitempty"`
+	Duration         *string    `db:"duration" json:"duration,omitempty"`
+	ActualMinutes    int        `db:"actual_minutes" json:"actual_minutes"`
+	RemainingMinutes *int       `db:"remaining_minutes" json:"remaining_minutes,omitempty"`
+	OverEstimate     bool       `db:"over_estimate" json:"over_estimate"`
+	DeletedAt        *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+}
+
+// Reassignment передача задачи другому сотруднику
+type Reassignment struct {
+	TaskId       int    `db:"id" json:"task_id"`
+	TaskName     string `db:"name" json:"task_name"`
+	FromPeopleId int    `db:"people_id" json:"from_people_id"`
+	ToPeopleId   int    `db:"-" json:"to_people_id"`
+}
+
+// Offboarding результат передачи задач уходящего сотрудника
+type Offboarding struct {
+	PeopleId      int            `json:"people_id"`
+	Preview       bool           `json:"preview"`
+	StoppedTasks  []int          `json:"stopped_tasks"`
+	Reassignments []Reassignment `json:"reassignments"`
+}
+
+// TimeEntry отрезок учета времени по задаче. End равен nil, пока отсчет не остановлен
+type TimeEntry struct {
+	TaskId   int        `json:"task_id"`
+	PeopleId int        `json:"people_id"`
+	Start    time.Time  `json:"start"`
+	End      *time.Time `json:"end,omitempty"`
+	Minutes  int        `json:"minutes"`
+}
+
+// TimeEntry возвращает отрезок учета времени по задаче, ограниченный периодом [from, to).
+// Незавершенная задача учитывается до now. false, если задача не начата или отрезок
+// не пересекается с периодом
+func (t Task) TimeEntry(from, to *time.Time, now time.Time) (TimeEntry, bool) {
+	if t.TimeStart == nil {
+		return TimeEntry{}, false
+	}
+	entry := TimeEntry{TaskId: t.Id, PeopleId: t.PeopleId, Start: *t.TimeStart}
+	end := now
+	if t.TimeEnd != nil {
+		end = *t.TimeEnd
+		entry.End = t.TimeEnd
+	}
+
+	start := *t.TimeStart
+	if from != nil && from.After(start) {
+		start = *from
+	}
+	if to != nil && to.Before(end) {
+		end = *to
+	}
+	if from != nil && !end.After(start) || to != nil && !start.Before(*to) {
+		return TimeEntry{}, false
+	}
+	if end.After(start) {
+		entry.Minutes = int(end.Sub(start).Minutes())
+	}
+	return entry, true
 }
 
 func (t *Task) StartTask() error {
-	if !t.TimeStart.IsZero() {
+	if t.TimeStart != nil {
 		return fmt.Errorf("Задача уже начата")
 	}
-	t.TimeStart = time.Now()
+	now := time.Now()
+	t.TimeStart = &now
 
 	// Логирование начала выполнения задачи
 	logger := zap.L()
 	logger.Info("Задача начата",
 		zap.Int("taskId", t.Id),
 		zap.String("taskName", t.Name),
-		zap.Time("timeStart", t.TimeStart),
+		zap.Time("timeStart", now),
 	)
 
 	return nil
 }
 
 func (t *Task) EndTask() error {
-	if !t.TimeEnd.IsZero() {
+	if t.TimeEnd != nil {
 		return fmt.Errorf("Задача уже завершена")
 	}
-	t.TimeEnd = time.Now()
+	now := time.Now()
+	t.TimeEnd = &now
 
 	// Логирование завершения выполнения задачи
 	logger := zap.L()
 	logger.Info("Задача завершена",
 		zap.Int("taskId", t.Id),
 		zap.String("taskName", t.Name),
-		zap.Time("timeEnd", t.TimeEnd),
+		zap.Time("timeEnd", now),
 	)
 
 	return nil
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskTimeEntry(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	now := start.Add(3 * time.Hour)
+	from, to := start.Add(time.Hour), start.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name     string
+		task     Task
+		from, to *time.Time
+		ok       bool
+		end      *time.Time
+		minutes  int
+	}{
+		{"не начатая задача", Task{}, nil, nil, false, nil, 0},
+		{"запущенная задача учитывается до now", Task{TimeStart: &start}, nil, nil, true, nil, 180},
+		{"завершенная задача", Task{TimeStart: &start, TimeEnd: &end}, nil, nil, true, &end, 120},
+		{"запущенная задача в периоде", Task{TimeStart: &start}, &from, &to, true, nil, 120},
+		{"завершенная задача в периоде", Task{TimeStart: &start, TimeEnd: &end}, &from, &to, true, &end, 60},
+		{"задача до периода", Task{TimeStart: &start, TimeEnd: &end}, &end, &to, false, nil, 0},
+		{"задача после периода", Task{TimeStart: &start}, nil, &start, false, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := tt.task.TimeEntry(tt.from, tt.to, now)
+			if ok != tt.ok {
+				t.Fatalf("TimeEntry вернул %t, ожидалось %t", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if !entry.Start.Equal(start) || entry.Minutes != tt.minutes {
+				t.Fatalf("отрезок %+v, ожидалось начало %s и %d минут", entry, start, tt.minutes)
+			}
+			if (entry.End == nil) != (tt.end == nil) || entry.End != nil && !entry.End.Equal(*tt.end) {
+				t.Fatalf("окончание отрезка %v, ожидалось %v", entry.End, tt.end)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Периоды табеля
+const (
+	TimesheetWeek  = "week"
+	TimesheetMonth = "month"
+)
+
+// Состояния табеля. Rejected можно снова отправить на утверждение, approved блокирует
+// изменение времени сотрудника в периоде до возврата руководителем
+const (
+	TimesheetDraft     = "draft"
+	TimesheetSubmitted = "submitted"
+	TimesheetApproved  = "approved"
+	TimesheetRejected  = "rejected"
+)
+
+// Timesheet табель сотрудника за неделю или месяц. Id равен 0, пока табель не отправлялся
+type Timesheet struct {
+	Id       int    `db:"id" json:"id"`
+	PeopleId int    `db:"people_id" json:"people_id"`
+	Period   string `db:"period" json:"period" example:"week"`
+	// PeriodStart первый день периода, PeriodEnd — день после последнего
+	PeriodStart time.Time `db:"period_start" json:"period_start" example:"2024-07-01T00:00:00Z"`
+	PeriodEnd   time.Time `db:"period_end" json:"period_end" example:"2024-07-08T00:00:00Z"`
+	Status      string    `db:"status" json:"status" example:"submitted"`
+	// SubmittedMinutes время в табеле на момент отправки
+	SubmittedMinutes *int `db:"submitted_minutes" json:"submitted_minutes,omitempty"`
+	// Comment причина отклонения
+	Comment     *string    `db:"comment" json:"comment,omitempty"`
+	SubmittedAt *time.Time `db:"submitted_at" json:"submitted_at,omitempty"`
+	DecidedAt   *time.Time `db:"decided_at" json:"decided_at,omitempty"`
+	// DecidedBy кто утвердил или отклонил табель
+	DecidedBy *string `db:"decided_by" json:"decided_by,omitempty"`
+	// TotalMinutes и Entries текущее время сотрудника в периоде, заполняются для одного табеля
+	TotalMinutes int         `db:"-" json:"total_minutes"`
+	Entries      []TimeEntry `db:"-" json:"entries,omitempty"`
+}
+
+// PeriodBounds возвращает период табеля, содержащий день date: неделю с понедельника или
+// календарный месяц. Конец периода не включается
+func PeriodBounds(period string, date time.Time) (time.Time, time.Time, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	switch period {
+	case TimesheetWeek:
+		start := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return start, start.AddDate(0, 0, 7), nil
+	case TimesheetMonth:
+		start := day.AddDate(0, 0, 1-day.Day())
+		return start, start.AddDate(0, 1, 0), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("неизвестный период табеля %q", period)
+	}
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// User учетная запись для входа в API по логину и паролю
+type User struct {
+	Id           int    `db:"id" json:"id"`
+	Login        string `db:"login" json:"login"`
+	PasswordHash string `db:"password_hash" json:"-"`
+	Role         string `db:"role" json:"role"`
+	PeopleId     *int   `db:"people_id" json:"people_id,omitempty"`
+}
+
+// APIKey ключ доступа интеграции. Сам ключ не хранится, только его хеш
+type APIKey struct {
+	Id        int        `db:"id" json:"id"`
+	Name      string     `db:"name" json:"name"`
+	Role      string     `db:"role" json:"role"`
+	KeyHash   string     `db:"key_hash" json:"-"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook подписка внешней интеграции на события. Secret отдается только при создании
+type Webhook struct {
+	Id     int    `json:"id"`
+	URL    string `json:"url" example:"https://example.com/hooks/tracker"`
+	Secret string `json:"secret,omitempty"`
+	// EventTypes типы событий, пустой список означает все события
+	EventTypes []string `json:"event_types" example:"task.assigned,timer.stopped"`
+	Active     bool     `json:"active"`
+	// FailureCount неудачные попытки доставки подряд. При достижении порога подписка отключается
+	FailureCount int        `json:"failure_count"`
+	DisabledAt   *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Состояния доставки
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery доставка события подписчику и результат последней попытки
+type WebhookDelivery struct {
+	Id             int64           `db:"id" json:"id"`
+	WebhookId      int             `db:"webhook_id" json:"webhook_id"`
+	EventId        int64           `db:"event_id" json:"event_id"`
+	EventType      string          `db:"event_type" json:"event_type"`
+	Payload        json.RawMessage `db:"payload" json:"payload" swaggertype:"object"`
+	Status         string          `db:"status" json:"status" example:"delivered"`
+	Attempts       int             `db:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time       `db:"next_attempt_at" json:"next_attempt_at"`
+	LastStatusCode *int            `db:"last_status_code" json:"last_status_code,omitempty"`
+	LastError      *string         `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+	DeliveredAt    *time.Time      `db:"delivered_at" json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,103 @@
+package outbox
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/events"
+	"GoTimeTracker/internal/metrics"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// lease на сколько откладывается следующая попытка выбранного события. Если экземпляр упадет,
+// не записав результат, событие будет доставлено повторно по истечении lease
+const lease = time.Minute
+
+// Dispatcher доставляет недоставленные события получателям
+type Dispatcher struct {
+	db    *database.Database
+	cfg   Config
+	sinks []Sink
+}
+
+// New создает Dispatcher с получателями sinks
+func New(db *database.Database, cfg Config, sinks ...Sink) *Dispatcher {
+	return &Dispatcher{db: db, cfg: cfg, sinks: sinks}
+}
+
+// Start запускает доставку до отмены ctx
+func (d *Dispatcher) Start(ctx context.Context) {
+	go d.loop(ctx)
+}
+
+// loop раз в Interval доставляет события, время которых наступило. Пока находятся события,
+// следующая пачка выбирается без ожидания: в ней окажутся следующие события тех же сущностей
+func (d *Dispatcher) loop(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+	for ctx.Err() == nil {
+		if d.dispatchBatch(ctx) > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchBatch доставляет одну пачку событий и возвращает ее размер. События в пачке
+// относятся к разным сущностям и доставляются параллельно
+func (d *Dispatcher) dispatchBatch(ctx context.Context) int {
+	claimed, err := d.db.ClaimOutbox(ctx, d.cfg.Batch, lease)
+	if err != nil {
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	for _, e := range claimed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.dispatch(ctx, e)
+		}()
+	}
+	wg.Wait()
+	return len(claimed)
+}
+
+// dispatch доставляет событие всем получателям по порядку. При ошибке получателя следующие
+// не вызываются, а событие повторяется всем получателям после задержки
+func (d *Dispatcher) dispatch(ctx context.Context, row model.OutboxEvent) {
+	e := Event(row)
+	for _, sink := range d.sinks {
+		if err := sink.Deliver(ctx, e); err != nil {
+			metrics.OutboxDelivery(sink.Name(), metrics.OutboxFailed)
+			attempts := row.Attempts + 1
+			next := time.Now().Add(d.cfg.Backoff(attempts))
+			logger.Ctx(ctx).Error("Ошибка доставки доменного события", zap.Error(err), zap.Int64("id", row.Id),
+				zap.String("type", row.EventType), zap.String("sink", sink.Name()), zap.Int("attempts", attempts),
+				zap.Time("nextAttemptAt", next))
+			_ = d.db.RecordOutboxFailure(ctx, row.Id, fmt.Sprintf("%s: %v", sink.Name(), err), next)
+			return
+		}
+		metrics.OutboxDelivery(sink.Name(), metrics.OutboxDelivered)
+	}
+	_ = d.db.MarkOutboxDispatched(ctx, row.Id)
+}
+
+// Event событие шины, соответствующее строке outbox. Data — строка сущности (model.OutboxEvent.Payload)
+func Event(row model.OutboxEvent) events.Event {
+	return events.Event{
+		Id:       uint64(row.Id),
+		Type:     row.EventType,
+		At:       row.CreatedAt,
+		PeopleId: row.PeopleId,
+		Project:  row.Project,
+		Data:     row.Payload,
+	}
+}
@@ -0,0 +1,120 @@
+// Package outbox доставка доменных событий из таблицы outbox получателям (Sink). События
+// записывает слой database в одной транзакции с изменением, поэтому они не теряются при
+// падении сервиса. Доставка «хотя бы один раз»: событие, не доставленное хотя бы одному
+// получателю, повторяется всем получателям, и получатель должен переносить повторы (у события
+// постоянный Id). События одной сущности доставляются строго по порядку
+package outbox
+
+import (
+	"GoTimeTracker/internal/events"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sink получатель доменных событий
+type Sink interface {
+	// Name имя получателя для журнала и метрик
+	Name() string
+	// Deliver доставляет событие. Ошибка означает, что событие будет доставлено повторно
+	Deliver(ctx context.Context, e events.Event) error
+}
+
+// Получатели, которые можно включить в OUTBOX_SINKS
+const (
+	SinkWebhooks = "webhooks"
+	SinkLog      = "log"
+	SinkFile     = "file"
+)
+
+// SinkEvents поток /events. Подключается всегда, последним получателем (см. HubSink)
+const SinkEvents = "events"
+
+// Config настройки доставки
+type Config struct {
+	// Interval как часто проверяются недоставленные события. Ноль отключает доставку
+	Interval time.Duration
+	// Batch сколько событий разных сущностей доставляется одновременно
+	Batch int
+	// RetryBase задержка перед повтором после первой неудачи, каждая следующая вдвое больше
+	RetryBase time.Duration
+	// RetryMax наибольшая задержка между попытками. Попытки не ограничены по количеству
+	RetryMax time.Duration
+	// Sinks получатели по порядку доставки
+	Sinks []string
+	// File путь к файлу NDJSON для получателя file
+	File string
+}
+
+// DefaultConfig настройки по умолчанию: события доставляются только вебхукам
+func DefaultConfig() Config {
+	return Config{
+		Interval:  time.Second,
+		Batch:     50,
+		RetryBase: 5 * time.Second,
+		RetryMax:  10 * time.Minute,
+		Sinks:     []string{SinkWebhooks},
+	}
+}
+
+// ConfigFromEnv читает OUTBOX_INTERVAL, OUTBOX_BATCH, OUTBOX_RETRY_BASE, OUTBOX_RETRY_MAX,
+// OUTBOX_SINKS (через запятую) и OUTBOX_FILE
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	durations := map[string]*time.Duration{
+		"OUTBOX_INTERVAL":   &cfg.Interval,
+		"OUTBOX_RETRY_BASE": &cfg.RetryBase,
+		"OUTBOX_RETRY_MAX":  &cfg.RetryMax,
+	}
+	for name, target := range durations {
+		if value := os.Getenv(name); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed < 0 || (parsed == 0 && name != "OUTBOX_INTERVAL") {
+				return cfg, fmt.Errorf("неверное значение %s: %q", name, value)
+			}
+			*target = parsed
+		}
+	}
+	if value := os.Getenv("OUTBOX_BATCH"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return cfg, fmt.Errorf("неверное значение OUTBOX_BATCH: %q", value)
+		}
+		cfg.Batch = parsed
+	}
+
+	if value, ok := os.LookupEnv("OUTBOX_SINKS"); ok {
+		cfg.Sinks = nil
+		for _, part := range strings.Split(value, ",") {
+			if name := strings.TrimSpace(part); name != "" {
+				cfg.Sinks = append(cfg.Sinks, name)
+			}
+		}
+	}
+	cfg.File = os.Getenv("OUTBOX_FILE")
+	for _, name := range cfg.Sinks {
+		switch name {
+		case SinkWebhooks, SinkLog:
+		case SinkFile:
+			if cfg.File == "" {
+				return cfg, errors.New("для получателя file не задан OUTBOX_FILE")
+			}
+		default:
+			return cfg, fmt.Errorf("неизвестный получатель доменных событий %q", name)
+		}
+	}
+	return cfg, nil
+}
+
+// Backoff задержка перед попыткой после attempts неудачных: RetryBase·2^(attempts-1), не больше RetryMax
+func (c Config) Backoff(attempts int) time.Duration {
+	delay := c.RetryBase
+	for i := 1; i < attempts && delay < c.RetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, c.RetryMax)
+}
@@ -0,0 +1,93 @@
+package outbox
+
+import (
+	"GoTimeTracker/internal/events"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"encoding/json"
+	"go.uber.org/zap"
+	"os"
+	"slices"
+	"sync"
+)
+
+// HubSink публикует события потока /events (events.Types) в шину подписчиков. Остальные
+// доменные события в поток не попадают
+type HubSink struct {
+	hub *events.Hub
+}
+
+// NewHubSink создает получателя, публикующего события в hub
+func NewHubSink(hub *events.Hub) HubSink {
+	return HubSink{hub: hub}
+}
+
+// Name имя получателя
+func (HubSink) Name() string {
+	return SinkEvents
+}
+
+// Deliver публикует событие подписчикам. Шина присваивает событию свой Id для Last-Event-ID
+func (s HubSink) Deliver(_ context.Context, e events.Event) error {
+	if slices.Contains(events.Types, e.Type) {
+		s.hub.Publish(e)
+	}
+	return nil
+}
+
+// LogSink пишет события в журнал сервиса
+type LogSink struct{}
+
+// Name имя получателя
+func (LogSink) Name() string {
+	return SinkLog
+}
+
+// Deliver пишет событие в журнал
+func (LogSink) Deliver(ctx context.Context, e events.Event) error {
+	logger.Ctx(ctx).Info("Доменное событие", zap.Uint64("id", e.Id), zap.String("type", e.Type),
+		zap.Time("at", e.At), zap.Any("data", e.Data))
+	return nil
+}
+
+// FileSink дописывает события в файл по одному JSON на строку (NDJSON)
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink открывает файл path для дописывания, создавая его при необходимости
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o640)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Name имя получателя
+func (s *FileSink) Name() string {
+	return SinkFile
+}
+
+// Deliver дописывает событие и сбрасывает файл на диск, чтобы событие не было отмечено
+// доставленным раньше, чем оно сохранено
+func (s *FileSink) Deliver(_ context.Context, e events.Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err = s.file.Write(line); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Close закрывает файл
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,49 @@
+package outbox
+
+import (
+	"GoTimeTracker/internal/events"
+	"GoTimeTracker/internal/model"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestHubSinkDeliversStreamEvents(t *testing.T) {
+	hub := events.NewHub(events.DefaultConfig())
+	sub, _, _ := hub.Subscribe(events.Filter{PeopleIds: []int{7}}, 0)
+	defer sub.Close()
+	sink := NewHubSink(hub)
+
+	peopleId := 7
+	rows := []model.OutboxEvent{
+		// Доменное событие вне потока /events подписчикам не публикуется
+		{Id: 1, EventType: events.TimesheetApproved, PeopleId: &peopleId, Payload: json.RawMessage(`{"id":3}`)},
+		// Только что запущенная задача: окончания еще нет
+		{Id: 2, EventType: events.TimerStarted, PeopleId: &peopleId, CreatedAt: time.Now(),
+			Payload: json.RawMessage(`{"id":1,"people_id":7,"time_start":"2026-01-05T09:00:00Z","time_end":null}`)},
+	}
+	for _, row := range rows {
+		if err := sink.Deliver(context.Background(), Event(row)); err != nil {
+			t.Fatalf("Deliver(%s): %v", row.EventType, err)
+		}
+	}
+
+	select {
+	case e := <-sub.Events():
+		if e.Type != events.TimerStarted || e.PeopleId == nil || *e.PeopleId != 7 {
+			t.Fatalf("получено событие %+v, ожидалось timer.started", e)
+		}
+		data, _ := json.Marshal(e.Data)
+		if string(data) != string(rows[1].Payload) {
+			t.Fatalf("данные события %s, ожидалось %s", data, rows[1].Payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("событие timer.started не дошло до подписчика")
+	}
+	select {
+	case e := <-sub.Events():
+		t.Fatalf("лишнее событие %+v", e)
+	default:
+	}
+}
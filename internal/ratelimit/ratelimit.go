@@ -0,0 +1,158 @@
+package ratelimit
+
+import (
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/pkg/logger"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Limit корзина токенов: Rate запросов в секунду в среднем и не более Burst подряд.
+// Нулевой Rate отключает ограничение
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Config ограничение по умолчанию, ограничения отдельных маршрутов (шаблон пути gin)
+// и общее ограничение запросов с одного адреса, которое проверяется до аутентификации
+type Config struct {
+	Default Limit
+	Routes  map[string]Limit
+	IP      Limit
+}
+
+// DefaultConfig ограничения, если RATE_LIMIT, RATE_LIMIT_ROUTES и RATE_LIMIT_IP не заданы
+func DefaultConfig() Config {
+	return Config{Default: Limit{Rate: 10, Burst: 20}, Routes: map[string]Limit{}, IP: Limit{Rate: 50, Burst: 100}}
+}
+
+// ConfigFromEnv читает ограничения из RATE_LIMIT (rate:burst, например 10:20),
+// RATE_LIMIT_ROUTES (через запятую, например /allPeople=1:5,/login=0.2:5) и RATE_LIMIT_IP
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	if value := os.Getenv("RATE_LIMIT"); value != "" {
+		limit, err := parseLimit(value)
+		if err != nil {
+			return cfg, fmt.Errorf("неверное значение RATE_LIMIT: %w", err)
+		}
+		cfg.Default = limit
+	}
+	if value := os.Getenv("RATE_LIMIT_IP"); value != "" {
+		limit, err := parseLimit(value)
+		if err != nil {
+			return cfg, fmt.Errorf("неверное значение RATE_LIMIT_IP: %w", err)
+		}
+		cfg.IP = limit
+	}
+	if value := os.Getenv("RATE_LIMIT_ROUTES"); value != "" {
+		for _, entry := range strings.Split(value, ",") {
+			route, spec, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			if !ok {
+				return cfg, fmt.Errorf("неверное значение RATE_LIMIT_ROUTES: %s", entry)
+			}
+			limit, err := parseLimit(spec)
+			if err != nil {
+				return cfg, fmt.Errorf("неверное значение RATE_LIMIT_ROUTES для %s: %w", route, err)
+			}
+			cfg.Routes[route] = limit
+		}
+	}
+	return cfg, nil
+}
+
+func parseLimit(value string) (Limit, error) {
+	rateText, burstText, ok := strings.Cut(value, ":")
+	if !ok {
+		return Limit{}, fmt.Errorf("ожидается rate:burst, получено %s", value)
+	}
+	rate, err := strconv.ParseFloat(rateText, 64)
+	if err != nil || rate < 0 {
+		return Limit{}, fmt.Errorf("неверная частота %s", rateText)
+	}
+	burst, err := strconv.Atoi(burstText)
+	if err != nil || (rate > 0 && burst < 1) {
+		return Limit{}, fmt.Errorf("неверный запас %s", burstText)
+	}
+	return Limit{Rate: rate, Burst: burst}, nil
+}
+
+// Limiter ограничивает частоту запросов каждого клиента к каждому маршруту
+type Limiter struct {
+	store Store
+	cfg   Config
+}
+
+// New создает ограничитель с корзинами в store
+func New(store Store, cfg Config) *Limiter {
+	return &Limiter{store: store, cfg: cfg}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Middleware ограничивает запросы по корзине клиента и маршрута. Клиент определяется ключом
+// доступа или пользователем, если перед Middleware подключен auth.Middleware, иначе адресом.
+// Отвечает заголовками RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset и 429 с Retry-After
+// при превышении. Если хранилище недоступно, запросы пропускаются
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		route := ctx.FullPath()
+		limit, ok := l.cfg.Routes[route]
+		if !ok {
+			limit = l.cfg.Default
+		}
+		l.take(ctx, clientKey(ctx)+" "+route, limit)
+	}
+}
+
+// IPMiddleware ограничивает все запросы с одного адреса по общей корзине Config.IP. Подключается
+// перед auth.Middleware, чтобы учитывались и запросы с неверным токеном или ключом доступа
+func (l *Limiter) IPMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		l.take(ctx, "ip:"+ctx.ClientIP(), l.cfg.IP)
+	}
+}
+
+// take забирает токен из корзины key и пропускает запрос или отвечает 429
+func (l *Limiter) take(ctx *gin.Context, key string, limit Limit) {
+	if limit.Rate <= 0 {
+		ctx.Next()
+		return
+	}
+
+	result, err := l.store.Take(ctx.Request.Context(), key, limit)
+	if err != nil {
+		logger.Ctx(ctx.Request.Context()).Error("Ошибка хранилища ограничения частоты запросов", zap.Error(err))
+		ctx.Next()
+		return
+	}
+
+	ctx.Header("RateLimit-Limit", strconv.Itoa(limit.Burst))
+	ctx.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	ctx.Header("RateLimit-Reset", strconv.Itoa(int(math.Ceil(result.Reset.Seconds()))))
+	if !result.Allowed {
+		retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+		logger.Ctx(ctx.Request.Context()).Info("Превышена частота запросов", zap.Int("retryAfter", retryAfter))
+		ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+		ctx.AbortWithStatusJSON(http.StatusTooManyRequests, errorResponse{Error: "Слишком много запросов"})
+		return
+	}
+	ctx.Next()
+}
+
+// clientKey ключ корзины клиента: ключ доступа, пользователь или адрес. Адрес берется из
+// X-Forwarded-For только от доверенных прокси (gin.Engine.SetTrustedProxies)
+func clientKey(ctx *gin.Context) string {
+	if identity, ok := auth.FromContext(ctx); ok {
+		return identity.Kind + ":" + strconv.Itoa(identity.Id)
+	}
+	return "ip:" + ctx.ClientIP()
+}
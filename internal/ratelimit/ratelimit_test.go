@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newEngine маршрут /api, на котором после IPMiddleware аутентификация отклоняет все запросы
+func newEngine(t *testing.T, trustedProxies []string, cfg Config) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	if err := engine.SetTrustedProxies(trustedProxies); err != nil {
+		t.Fatal(err)
+	}
+	limiter := New(NewMemoryStore(), cfg)
+	reject := func(ctx *gin.Context) {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse{Error: "Требуется аутентификация"})
+	}
+	engine.GET("/api", limiter.IPMiddleware(), reject, limiter.Middleware(), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	return engine
+}
+
+func get(engine *gin.Engine, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodGet, "/api", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestIPMiddlewareCountsRejectedRequests(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.IP = Limit{Rate: 0.001, Burst: 3}
+	engine := newEngine(t, nil, cfg)
+
+	for i := 0; i < cfg.IP.Burst; i++ {
+		if code := get(engine, ""); code != http.StatusUnauthorized {
+			t.Fatalf("запрос %d: статус %d, ожидался 401", i+1, code)
+		}
+	}
+	if code := get(engine, ""); code != http.StatusTooManyRequests {
+		t.Fatalf("статус %d, ожидался 429 после %d отклоненных запросов", code, cfg.IP.Burst)
+	}
+}
+
+func TestIPMiddlewareIgnoresUntrustedForwardedFor(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.IP = Limit{Rate: 0.001, Burst: 2}
+	engine := newEngine(t, nil, cfg)
+
+	// Поддельный X-Forwarded-For не дает новую корзину, пока прокси не доверенный
+	for i, forwardedFor := range []string{"203.0.113.1", "203.0.113.2"} {
+		if code := get(engine, forwardedFor); code != http.StatusUnauthorized {
+			t.Fatalf("запрос %d: статус %d, ожидался 401", i+1, code)
+		}
+	}
+	if code := get(engine, "203.0.113.3"); code != http.StatusTooManyRequests {
+		t.Fatalf("статус %d, ожидался 429", code)
+	}
+}
+
+func TestIPMiddlewareUsesTrustedProxy(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.IP = Limit{Rate: 0.001, Burst: 1}
+	engine := newEngine(t, []string{"192.0.2.1"}, cfg)
+
+	// За доверенным прокси у каждого клиента своя корзина
+	for _, forwardedFor := range []string{"203.0.113.1", "203.0.113.2"} {
+		if code := get(engine, forwardedFor); code != http.StatusUnauthorized {
+			t.Fatalf("%s: статус %d, ожидался 401", forwardedFor, code)
+		}
+	}
+	if code := get(engine, "203.0.113.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("статус %d, ожидался 429", code)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("RATE_LIMIT", "5:10")
+	t.Setenv("RATE_LIMIT_ROUTES", "/login=0.2:5")
+	t.Setenv("RATE_LIMIT_IP", "20:40")
+
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Default != (Limit{Rate: 5, Burst: 10}) || cfg.Routes["/login"] != (Limit{Rate: 0.2, Burst: 5}) || cfg.IP != (Limit{Rate: 20, Burst: 40}) {
+		t.Fatalf("неверные ограничения: %+v", cfg)
+	}
+
+	t.Setenv("RATE_LIMIT_IP", "20")
+	if _, err = ConfigFromEnv(); err == nil {
+		t.Fatal("ожидалась ошибка для RATE_LIMIT_IP без запаса")
+	}
+}
@@ -0,0 +1,46 @@
+package request
+
+import (
+	"GoTimeTracker/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// AccessLog записывает каждый запрос в журнал сервиса вместо текстового журнала gin.
+// Подключается после Middleware, чтобы записи содержали идентификатор запроса и пользователя.
+// Запросы к путям quiet (проверки состояния, сбор метрик) пишутся только на уровне Debug
+func AccessLog(quiet ...string) gin.HandlerFunc {
+	quietPaths := make(map[string]bool, len(quiet))
+	for _, path := range quiet {
+		quietPaths[path] = true
+	}
+
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		path := ctx.Request.URL.Path
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		fields := []zap.Field{
+			zap.String("path", path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.Int("size", ctx.Writer.Size()),
+		}
+		if len(ctx.Errors) > 0 {
+			fields = append(fields, zap.String("errors", ctx.Errors.String()))
+		}
+
+		log := logger.Ctx(ctx.Request.Context())
+		switch {
+		case status >= http.StatusInternalServerError:
+			log.Error("Запрос обработан с ошибкой", fields...)
+		case quietPaths[path]:
+			log.Debug("Запрос обработан", fields...)
+		default:
+			log.Info("Запрос обработан", fields...)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package request
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// BodyLimits максимальный размер тела запроса в байтах по умолчанию и для отдельных маршрутов
+type BodyLimits struct {
+	Default int64
+	Routes  map[string]int64
+}
+
+// DefaultBodyLimits 1 МБ для всех маршрутов и 10 МБ для импорта сотрудников
+func DefaultBodyLimits() BodyLimits {
+	return BodyLimits{Default: 1 << 20, Routes: map[string]int64{"/peopleImport": 10 << 20}}
+}
+
+// BodyLimitsFromEnv читает ограничения из MAX_BODY_SIZE и MAX_BODY_SIZE_ROUTES
+// (через запятую, например /peopleImport=10485760)
+func BodyLimitsFromEnv() (BodyLimits, error) {
+	limits := DefaultBodyLimits()
+	if value := os.Getenv("MAX_BODY_SIZE"); value != "" {
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || size <= 0 {
+			return limits, fmt.Errorf("неверное значение MAX_BODY_SIZE: %s", value)
+		}
+		limits.Default = size
+	}
+	if value := os.Getenv("MAX_BODY_SIZE_ROUTES"); value != "" {
+		for _, entry := range strings.Split(value, ",") {
+			route, sizeText, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			size, err := strconv.ParseInt(sizeText, 10, 64)
+			if !ok || err != nil || size <= 0 {
+				return limits, fmt.Errorf("неверное значение MAX_BODY_SIZE_ROUTES: %s", entry)
+			}
+			limits.Routes[route] = size
+		}
+	}
+	return limits, nil
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// BodyLimit ограничивает размер тела запроса. Запрос с заведомо большим Content-Length
+// отклоняется сразу с 413, иначе чтение тела прерывается ошибкой *http.MaxBytesError
+func BodyLimit(limits BodyLimits) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		limit, ok := limits.Routes[ctx.FullPath()]
+		if !ok {
+			limit = limits.Default
+		}
+		if ctx.Request.ContentLength > limit {
+			ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, errorResponse{Error: "Слишком большое тело запроса"})
+			return
+		}
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, limit)
+		ctx.Next()
+	}
+}
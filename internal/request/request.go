@@ -0,0 +1,64 @@
+package request
+
+import (
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// HeaderId заголовок с идентификатором запроса
+const HeaderId = "X-Request-ID"
+
+// maxIdLength ограничение длины идентификатора, присланного клиентом
+const maxIdLength = 128
+
+type metaKey struct{}
+
+// Meta сведения о запросе, которые нужны за пределами контроллеров
+type Meta struct {
+	Id       string
+	ClientIp string
+}
+
+// Middleware берет идентификатор запроса из X-Request-ID или создает новый, возвращает его
+// в ответе и сохраняет вместе с адресом клиента в контексте запроса. Записи logger.Ctx
+// в рамках запроса дополняются идентификатором запроса, маршрутом и адресом клиента
+func Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(HeaderId)
+		if id == "" || len(id) > maxIdLength {
+			id = newId()
+		}
+		ctx.Header(HeaderId, id)
+
+		meta := Meta{Id: id, ClientIp: ctx.ClientIP()}
+		requestCtx := logger.With(WithMeta(ctx.Request.Context(), meta),
+			zap.String("request_id", id),
+			zap.String("method", ctx.Request.Method),
+			zap.String("route", ctx.FullPath()),
+			zap.String("client_ip", meta.ClientIp),
+		)
+		ctx.Request = ctx.Request.WithContext(requestCtx)
+		ctx.Next()
+	}
+}
+
+// WithMeta возвращает контекст со сведениями о запросе
+func WithMeta(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+// FromContext возвращает сведения о запросе или пустые сведения вне HTTP-запроса
+func FromContext(ctx context.Context) Meta {
+	meta, _ := ctx.Value(metaKey{}).(Meta)
+	return meta
+}
+
+func newId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1,20 +1,81 @@
 package routes
 
 import (
+	"GoTimeTracker/internal/auth"
 	"GoTimeTracker/internal/controller"
+	"GoTimeTracker/internal/graph"
+	"GoTimeTracker/internal/metrics"
+	"GoTimeTracker/internal/ratelimit"
+	"GoTimeTracker/internal/request"
+	"GoTimeTracker/internal/tracing"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func SetupRoutes(r *gin.Engine) {
-	r.GET("/allPeople", controller.GetAllPeople)
-	r.POST("/people", controller.AddPeople)
-	r.PUT("/people", controller.UpdatePeople)
-	r.DELETE("/people", controller.DeletePeople)
-
-	r.POST("/task", controller.AddTask)
-	r.PUT("/taskAssign", controller.AssignPeopleOnTask)
-	r.PUT("/taskStart", controller.StartTask)
-	r.PUT("/taskEnd", controller.EndTask)
-	r.GET("/task", controller.GetTasks)
+// SetupRoutes регистрирует маршруты API. Частота запросов ограничивается limiter по клиенту:
+// для /login по адресу, для остальных маршрутов по адресу до аутентификации и затем
+// по пользователю или ключу доступа
+func SetupRoutes(r *gin.Engine, limiter *ratelimit.Limiter, bodyLimits request.BodyLimits, graphOptions graph.Options) {
+	r.Use(otelgin.Middleware(tracing.ServiceName), request.Middleware(),
+		request.AccessLog("/healthz", "/readyz"), metrics.Middleware(), request.BodyLimit(bodyLimits))
+
+	r.POST("/login", limiter.Middleware(), controller.Login)
+
+	api := r.Group("/", limiter.IPMiddleware(), auth.Middleware(), limiter.Middleware())
+
+	api.GET("/me", controller.Me)
+
+	api.GET("/allPeople", auth.Require(auth.PeopleRead), controller.GetAllPeople)
+	api.POST("/people", auth.Require(auth.PeopleCreate), controller.AddPeople)
+	api.POST("/peopleImport", auth.Require(auth.PeopleCreate), controller.ImportPeople)
+	api.PUT("/people", auth.Require(auth.PeopleWrite), controller.UpdatePeople)
+	api.DELETE("/people", auth.Require(auth.PeopleDelete), controller.DeletePeople)
+	api.PUT("/peopleRestore", auth.Require(auth.DeletedRestore), controller.RestorePeople)
+	api.POST("/peopleOffboard", auth.Require(auth.TaskWrite), controller.OffboardPeople)
+
+	api.POST("/task", auth.Require(auth.TaskWrite), controller.AddTask)
+	api.PUT("/taskAssign", auth.Require(auth.TaskWrite), controller.AssignPeopleOnTask)
+	api.PUT("/taskEstimate", auth.Require(auth.TaskWrite), controller.SetTaskEstimate)
+	api.PUT("/taskStart", auth.Require(auth.TaskTimer), controller.StartTask)
+	api.PUT("/taskEnd", auth.Require(auth.TaskTimer), controller.EndTask)
+	api.GET("/task", auth.Require(auth.TaskRead), controller.GetTasks)
+	api.DELETE("/task", auth.Require(auth.TaskWrite), controller.DeleteTask)
+	api.PUT("/taskRestore", auth.Require(auth.DeletedRestore), controller.RestoreTask)
+
+	api.GET("/estimateReport", auth.Require(auth.ReportRead), controller.GetEstimateReport)
+
+	api.GET("/timesheet", auth.Require(auth.ReportRead), controller.GetTimesheet)
+	api.GET("/timesheets", auth.Require(auth.ReportRead), controller.GetTimesheets)
+	api.PUT("/timesheetSubmit", auth.Require(auth.TimesheetSubmit), controller.SubmitTimesheet)
+	api.PUT("/timesheetApprove", auth.Require(auth.TimesheetApprove), controller.ApproveTimesheet)
+	api.PUT("/timesheetReject", auth.Require(auth.TimesheetApprove), controller.RejectTimesheet)
+	api.PUT("/timesheetReopen", auth.Require(auth.TimesheetApprove), controller.ReopenTimesheet)
+
+	api.GET("/closedPeriod", auth.Require(auth.PeriodClose), controller.GetClosedPeriods)
+	api.POST("/closedPeriod", auth.Require(auth.PeriodClose), controller.ClosePeriod)
+	api.DELETE("/closedPeriod", auth.Require(auth.PeriodClose), controller.ReopenPeriod)
+	api.GET("/lockedEdits", auth.Require(auth.PeriodClose), controller.GetLockedEdits)
+
+	api.GET("/auditLog", auth.Require(auth.AuditRead), controller.GetAuditLog)
+
+	api.GET("/events", auth.Require(auth.TaskRead), controller.Events)
+
+	api.GET("/webhook", auth.Require(auth.WebhookManage), controller.GetWebhooks)
+	api.POST("/webhook", auth.Require(auth.WebhookManage), controller.AddWebhook)
+	api.PUT("/webhook", auth.Require(auth.WebhookManage), controller.UpdateWebhook)
+	api.DELETE("/webhook", auth.Require(auth.WebhookManage), controller.DeleteWebhook)
+	api.GET("/webhookDeliveries", auth.Require(auth.WebhookManage), controller.GetWebhookDeliveries)
+	api.POST("/webhookRedeliver", auth.Require(auth.WebhookManage), controller.RedeliverWebhook)
+
+	// Права на поля GraphQL проверяются в сервисном слое, как и для соответствующих маршрутов REST
+	graphql := graph.Handler(graphOptions)
+	api.GET("/graphql", graphql)
+	api.POST("/graphql", graphql)
+	if graphOptions.Dev {
+		r.GET("/graphql/playground", graph.Playground("/graphql"))
+	}
+
+	api.GET("/logLevel", auth.Require(auth.LogManage), controller.GetLogLevel)
+	api.PUT("/logLevel", auth.Require(auth.LogManage), controller.SetLogLevel)
 
 }
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/model"
+	"context"
+)
+
+// GetAuditLog возвращает страницу журнала изменений
+func GetAuditLog(ctx context.Context, actor auth.Identity, page, pageSize int, filter database.AuditFilter) ([]model.AuditEntry, error) {
+	if _, err := allow(actor, auth.AuditRead); err != nil {
+		return nil, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return nil, err
+	}
+	return db.GetAuditLog(ctx, page, pageSize, filter)
+}
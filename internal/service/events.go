@@ -0,0 +1,65 @@
+package service
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/events"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"go.uber.org/zap"
+	"slices"
+)
+
+// SubscribeEvents подписывает actor на события сотрудников peopleIds и команды руководителя
+// teamId в пределах его области TaskRead. Без peopleIds и teamId подписывает на все доступные
+// события. Возвращает подписку и события после lastEventId (см. events.Hub.Subscribe)
+func SubscribeEvents(ctx context.Context, actor auth.Identity, peopleIds []int, teamId *int, project string, types []string, lastEventId uint64) (*events.Subscription, []events.Event, bool, error) {
+	scope, err := allow(actor, auth.TaskRead)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	visible, err := visibleIds(ctx, db, actor, scope)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	requested := make([]int, 0, len(peopleIds))
+	for _, id := range peopleIds {
+		if err = ensureInScope(ctx, db, actor, scope, &id); err != nil {
+			return nil, nil, false, err
+		}
+		requested = append(requested, id)
+	}
+	if teamId != nil {
+		if err = ensureInScope(ctx, db, actor, scope, teamId); err != nil {
+			return nil, nil, false, err
+		}
+		team, err := db.GetTeamIds(ctx, *teamId)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		// Подчиненные руководителя из чужой команды могут быть вне области actor
+		for _, id := range team {
+			if visible == nil || slices.Contains(visible, id) {
+				requested = append(requested, id)
+			}
+		}
+	}
+
+	filter := events.Filter{Project: project, Types: types}
+	if peopleIds != nil || teamId != nil {
+		filter.PeopleIds = requested
+	} else {
+		// Не назначенные задачи доступны тем же, кто может ими распоряжаться (см. ensureInScope)
+		filter.PeopleIds, filter.Unassigned = visible, scope != auth.ScopeOwn
+	}
+
+	sub, replay, complete := events.Default().Subscribe(filter, lastEventId)
+	logger.Ctx(ctx).Info("Подписка на события", zap.Ints("peopleIds", filter.PeopleIds), zap.String("project", project),
+		zap.Strings("types", types), zap.Uint64("lastEventId", lastEventId), zap.Int("replay", len(replay)))
+	return sub, replay, complete, nil
+}
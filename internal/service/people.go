@@ -0,0 +1,208 @@
+package service
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/enrichment"
+	"GoTimeTracker/internal/importer"
+	"GoTimeTracker/internal/model"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// EnrichmentError ошибка получения данных сотрудника из внешнего API
+type EnrichmentError struct {
+	Err error
+}
+
+func (e *EnrichmentError) Error() string {
+	return fmt.Sprintf("Ошибка при получении данных сотрудника из внешнего API: %s", e.Err)
+}
+
+func (e *EnrichmentError) Unwrap() error {
+	return e.Err
+}
+
+// peopleFilter ограничивает фильтр сотрудниками, доступными actor
+func peopleFilter(ctx context.Context, db *database.Database, actor auth.Identity, filter database.PeopleFilter) (database.PeopleFilter, error) {
+	scope, err := allow(actor, auth.PeopleRead)
+	if err != nil {
+		return filter, err
+	}
+	if filter.IncludeDeleted {
+		if _, err = allow(actor, auth.DeletedRead); err != nil {
+			return filter, err
+		}
+	}
+	filter.Ids, err = visibleIds(ctx, db, actor, scope)
+	return filter, err
+}
+
+// GetAllPeople возвращает страницу сотрудников, доступных actor
+func GetAllPeople(ctx context.Context, actor auth.Identity, page, pageSize int, filter database.PeopleFilter) ([]model.People, error) {
+	db, err := database.GetInstance()
+	if err != nil {
+		return nil, err
+	}
+	if filter, err = peopleFilter(ctx, db, actor, filter); err != nil {
+		return nil, err
+	}
+
+	people, err := db.GetAllPeople(ctx, page, pageSize, filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range people {
+		hidePassport(actor, &people[i])
+	}
+	return people, nil
+}
+
+// EachPeople передает в fn всех доступных actor сотрудников, подходящих под фильтр
+func EachPeople(ctx context.Context, actor auth.Identity, filter database.PeopleFilter, fn func(model.People) error) error {
+	db, err := database.GetInstance()
+	if err != nil {
+		return err
+	}
+	if filter, err = peopleFilter(ctx, db, actor, filter); err != nil {
+		return err
+	}
+
+	return db.EachPeople(ctx, filter, func(p model.People) error {
+		hidePassport(actor, &p)
+		return fn(p)
+	})
+}
+
+// GetPeopleByIds возвращает сотрудников из ids одним запросом. Недоступные actor
+// и удаленные сотрудники пропускаются
+func GetPeopleByIds(ctx context.Context, actor auth.Identity, ids []int) ([]model.People, error) {
+	db, err := database.GetInstance()
+	if err != nil {
+		return nil, err
+	}
+	filter, err := peopleFilter(ctx, db, actor, database.PeopleFilter{})
+	if err != nil {
+		return nil, err
+	}
+	if filter.Ids == nil {
+		filter.Ids = ids
+	} else {
+		filter.Ids = slices.DeleteFunc(slices.Clone(ids), func(id int) bool {
+			return !slices.Contains(filter.Ids, id)
+		})
+	}
+
+	var people []model.People
+	err = db.EachPeople(ctx, filter, func(p model.People) error {
+		hidePassport(actor, &p)
+		people = append(people, p)
+		return nil
+	})
+	return people, err
+}
+
+// AddPeople добавляет сотрудника по паспорту, запрашивая ФИО и адрес во внешнем API.
+// Если паспорт уже занят, возвращается *database.DuplicatePassportError
+func AddPeople(ctx context.Context, actor auth.Identity, passport model.Passport) (int, error) {
+	if _, err := allow(actor, auth.PeopleCreate); err != nil {
+		return 0, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return 0, err
+	}
+
+	existing, err := db.FindPeopleByPassports(ctx, []model.Passport{passport})
+	if err != nil {
+		return 0, err
+	}
+	if id, ok := existing[passport]; ok {
+		return 0, &database.DuplicatePassportError{Passport: passport, PeopleId: id}
+	}
+
+	people := model.People{PassportSerie: passport.Serie, PassportNumber: passport.Number}
+	if client := enrichment.GetInstance(); client != nil {
+		info, err := client.Info(ctx, passport)
+		if err != nil {
+			return 0, &EnrichmentError{Err: err}
+		}
+		info.Apply(&people)
+	}
+	return db.AddPeople(ctx, auditActor(ctx, actor), people)
+}
+
+// ImportPeople массово добавляет сотрудников, см. importer.Run
+func ImportPeople(ctx context.Context, actor auth.Identity, rows []importer.Row, opts importer.Options) (importer.Report, error) {
+	if _, err := allow(actor, auth.PeopleCreate); err != nil {
+		return importer.Report{}, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return importer.Report{}, err
+	}
+	return importer.Run(ctx, db, enrichment.GetInstance(), auditActor(ctx, actor), rows, opts)
+}
+
+// UpdatePeople обновляет данные сотрудника. Руководителя сотрудника может менять только тот,
+// кому доступны все сотрудники, иначе остается прежний руководитель
+func UpdatePeople(ctx context.Context, actor auth.Identity, p model.People) error {
+	scope, err := allow(actor, auth.PeopleWrite)
+	if err != nil {
+		return err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return err
+	}
+	if err = ensureInScope(ctx, db, actor, scope, &p.Id); err != nil {
+		return err
+	}
+
+	current, err := db.GetPeople(ctx, p.Id)
+	if err != nil {
+		return err
+	}
+	if scope != auth.ScopeAll {
+		p.ManagerId = current.ManagerId
+	}
+	return db.UpdatePeople(ctx, auditActor(ctx, actor), p)
+}
+
+// DeletePeople помечает сотрудника удаленным, его задачи обрабатываются согласно policy
+// (см. database.DeletePeople). Получатель задач reassignTo должен быть доступен actor
+func DeletePeople(ctx context.Context, actor auth.Identity, id int, policy string, reassignTo int) error {
+	scope, err := allow(actor, auth.PeopleDelete)
+	if err != nil {
+		return err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return err
+	}
+	if err = ensureInScope(ctx, db, actor, scope, &id); err != nil {
+		return err
+	}
+	if policy == database.TasksReassign {
+		if err = ensureInScope(ctx, db, actor, scope, &reassignTo); err != nil {
+			return err
+		}
+		if _, err = db.GetPeople(ctx, reassignTo); err != nil {
+			return err
+		}
+	}
+	return db.DeletePeople(ctx, auditActor(ctx, actor), id, policy, reassignTo)
+}
+
+// RestorePeople восстанавливает удаленного сотрудника
+func RestorePeople(ctx context.Context, actor auth.Identity, id int) error {
+	if _, err := allow(actor, auth.DeletedRestore); err != nil {
+		return err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return err
+	}
+	return db.RestorePeople(ctx, auditActor(ctx, actor), id)
+}
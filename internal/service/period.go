@@ -0,0 +1,68 @@
+package service
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/model"
+	"context"
+	"time"
+)
+
+type overrideKey struct{}
+
+// WithOverride возвращает контекст, в котором изменения времени в закрытом периоде выполняются
+// с причиной reason. Причина учитывается только для тех, кому разрешено auth.PeriodOverride,
+// и записывается в журнал изменений и отчет о попытках изменения
+func WithOverride(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, overrideKey{}, reason)
+}
+
+// GetClosedPeriods возвращает закрытые периоды
+func GetClosedPeriods(ctx context.Context, actor auth.Identity) ([]model.ClosedPeriod, error) {
+	if _, err := allow(actor, auth.PeriodClose); err != nil {
+		return nil, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return nil, err
+	}
+	return db.GetClosedPeriods(ctx)
+}
+
+// ClosePeriod закрывает период [start, end): время задач, пересекающееся с ним, больше нельзя
+// начать, завершить, удалить или переназначить
+func ClosePeriod(ctx context.Context, actor auth.Identity, start, end time.Time, comment *string) (model.ClosedPeriod, error) {
+	if _, err := allow(actor, auth.PeriodClose); err != nil {
+		return model.ClosedPeriod{}, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return model.ClosedPeriod{}, err
+	}
+	return db.ClosePeriod(ctx, auditActor(ctx, actor), start, end, comment)
+}
+
+// ReopenPeriod снимает закрытие периода
+func ReopenPeriod(ctx context.Context, actor auth.Identity, id int) error {
+	if _, err := allow(actor, auth.PeriodClose); err != nil {
+		return err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return err
+	}
+	return db.ReopenPeriod(ctx, auditActor(ctx, actor), id)
+}
+
+// GetLockedEdits возвращает страницу отчета о попытках изменить время в закрытых периодах
+// и утвержденных табелях
+func GetLockedEdits(ctx context.Context, actor auth.Identity, page, pageSize int, reason string, from, to *time.Time) ([]model.LockedEdit, error) {
+	if _, err := allow(actor, auth.PeriodClose); err != nil {
+		return nil, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return nil, err
+	}
+	return db.GetLockedEdits(ctx, page, pageSize, reason, from, to)
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/model"
+	"context"
+)
+
+// GetEstimateReport строит отчет по оценкам по задачам сотрудников, доступных actor
+func GetEstimateReport(ctx context.Context, actor auth.Identity, groupBy string) ([]model.EstimateReport, error) {
+	scope, err := allow(actor, auth.ReportRead)
+	if err != nil {
+		return nil, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return nil, err
+	}
+	ids, err := visibleIds(ctx, db, actor, scope)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetEstimateReport(ctx, groupBy, ids)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/internal/request"
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"slices"
+)
+
+var (
+	// ErrForbidden действие не разрешено вызывающей стороне
+	ErrForbidden = errors.New("Недостаточно прав")
+	// ErrNotFound запись не найдена
+	ErrNotFound = database.ErrNotFound
+	// ErrPeriodLocked время относится к утвержденному табелю
+	ErrPeriodLocked = database.ErrPeriodLocked
+	// ErrPeriodClosed время относится к закрытому периоду
+	ErrPeriodClosed = database.ErrPeriodClosed
+	// ErrTimesheetState действие недопустимо в текущем состоянии табеля
+	ErrTimesheetState = database.ErrTimesheetState
+	// ErrTimerRunning в периоде идет отсчет времени по задаче
+	ErrTimerRunning = database.ErrTimerRunning
+)
+
+// allow возвращает область, в которой actor может выполнить действие, или ErrForbidden
+func allow(actor auth.Identity, permission auth.Permission) (auth.Scope, error) {
+	scope := actor.ScopeOf(permission)
+	if scope == auth.ScopeNone {
+		logger.Info("Недостаточно прав", zap.String("role", string(actor.Role)), zap.String("permission", string(permission)))
+		return auth.ScopeNone, ErrForbidden
+	}
+	return scope, nil
+}
+
+// visibleIds сотрудники, доступные в области scope. nil означает всех сотрудников
+func visibleIds(ctx context.Context, db *database.Database, actor auth.Identity, scope auth.Scope) ([]int, error) {
+	switch {
+	case scope == auth.ScopeAll:
+		return nil, nil
+	case actor.PeopleId == nil:
+		return []int{}, nil
+	case scope == auth.ScopeTeam:
+		return db.GetTeamIds(ctx, *actor.PeopleId)
+	default:
+		return []int{*actor.PeopleId}, nil
+	}
+}
+
+// ensureInScope проверяет, что действие над сотрудником peopleId разрешено в области scope.
+// peopleId равен nil для не назначенной задачи: ей могут распоряжаться руководители и администраторы
+func ensureInScope(ctx context.Context, db *database.Database, actor auth.Identity, scope auth.Scope, peopleId *int) error {
+	if scope == auth.ScopeAll {
+		return nil
+	}
+	if peopleId == nil {
+		if scope == auth.ScopeTeam {
+			return nil
+		}
+		return ErrForbidden
+	}
+
+	ids, err := visibleIds(ctx, db, actor, scope)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(ids, *peopleId) {
+		logger.Ctx(ctx).Info("Сотрудник вне области доступа", zap.String("role", string(actor.Role)), zap.Int("peopleId", *peopleId))
+		return ErrForbidden
+	}
+	return nil
+}
+
+// auditActor автор изменения для журнала: вызывающая сторона и сведения о запросе. Причина
+// изменения в закрытом периоде (см. WithOverride) учитывается, только если actor вправе ее указать
+func auditActor(ctx context.Context, actor auth.Identity) model.AuditActor {
+	meta := request.FromContext(ctx)
+	audit := model.AuditActor{Kind: actor.Kind, Id: actor.Id, Name: actor.Name, RequestId: meta.Id, ClientIp: meta.ClientIp}
+	if reason, _ := ctx.Value(overrideKey{}).(string); reason != "" && actor.ScopeOf(auth.PeriodOverride) != auth.ScopeNone {
+		audit.OverrideReason = reason
+	}
+	return audit
+}
+
+// hidePassport скрывает паспорт от тех, кому не разрешено его видеть
+func hidePassport(actor auth.Identity, p *model.People) {
+	if actor.ScopeOf(auth.PeoplePassport) == auth.ScopeNone {
+		p.PassportSerie, p.PassportNumber = "", ""
+	}
+}
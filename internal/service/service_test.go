@@ -0,0 +1,128 @@
+package service
+
+import (
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/model"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func peopleId(id int) *int {
+	return &id
+}
+
+func TestAllow(t *testing.T) {
+	tests := []struct {
+		role       auth.Role
+		permission auth.Permission
+		want       auth.Scope
+		wantErr    error
+	}{
+		{auth.RoleAdmin, auth.PeopleDelete, auth.ScopeAll, nil},
+		{auth.RoleManager, auth.TaskWrite, auth.ScopeTeam, nil},
+		{auth.RoleManager, auth.PeopleDelete, auth.ScopeNone, ErrForbidden},
+		{auth.RoleEmployee, auth.TaskTimer, auth.ScopeOwn, nil},
+		{auth.RoleEmployee, auth.TaskWrite, auth.ScopeNone, ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role)+" "+string(tt.permission), func(t *testing.T) {
+			scope, err := allow(auth.Identity{Role: tt.role}, tt.permission)
+			if scope != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("allow: %d, %v; ожидалось %d, %v", scope, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Проверки без обращения к базе данных: область ScopeTeam с сотрудником читает подчиненных из базы
+// и проверяется интеграционными тестами
+func TestVisibleIds(t *testing.T) {
+	tests := []struct {
+		name  string
+		actor auth.Identity
+		scope auth.Scope
+		want  []int
+	}{
+		{"все сотрудники", auth.Identity{Role: auth.RoleAdmin}, auth.ScopeAll, nil},
+		{"сам сотрудник", auth.Identity{Role: auth.RoleEmployee, PeopleId: peopleId(7)}, auth.ScopeOwn, []int{7}},
+		{"пользователь без сотрудника", auth.Identity{Role: auth.RoleEmployee}, auth.ScopeOwn, []int{}},
+		{"руководитель без сотрудника", auth.Identity{Role: auth.RoleManager}, auth.ScopeTeam, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, err := visibleIds(context.Background(), nil, tt.actor, tt.scope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (ids == nil) != (tt.want == nil) || !slices.Equal(ids, tt.want) {
+				t.Fatalf("visibleIds: %v, ожидалось %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnsureInScope(t *testing.T) {
+	employee := auth.Identity{Role: auth.RoleEmployee, PeopleId: peopleId(7)}
+	tests := []struct {
+		name     string
+		actor    auth.Identity
+		scope    auth.Scope
+		peopleId *int
+		wantErr  error
+	}{
+		{"администратор", auth.Identity{Role: auth.RoleAdmin}, auth.ScopeAll, peopleId(1), nil},
+		{"администратор и не назначенная задача", auth.Identity{Role: auth.RoleAdmin}, auth.ScopeAll, nil, nil},
+		{"руководитель и не назначенная задача", auth.Identity{Role: auth.RoleManager}, auth.ScopeTeam, nil, nil},
+		{"руководитель без сотрудника", auth.Identity{Role: auth.RoleManager}, auth.ScopeTeam, peopleId(1), ErrForbidden},
+		{"свой сотрудник", employee, auth.ScopeOwn, peopleId(7), nil},
+		{"чужой сотрудник", employee, auth.ScopeOwn, peopleId(8), ErrForbidden},
+		{"сотрудник и не назначенная задача", employee, auth.ScopeOwn, nil, ErrForbidden},
+		{"пользователь без сотрудника", auth.Identity{Role: auth.RoleEmployee}, auth.ScopeOwn, peopleId(7), ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ensureInScope(context.Background(), nil, tt.actor, tt.scope, tt.peopleId)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ensureInScope: %v, ожидалось %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuditActorOverride(t *testing.T) {
+	ctx := WithOverride(context.Background(), "исправление ошибки")
+	tests := []struct {
+		role auth.Role
+		want string
+	}{
+		{auth.RoleAdmin, "исправление ошибки"},
+		{auth.RoleManager, ""},
+		{auth.RoleEmployee, ""},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			if got := auditActor(ctx, auth.Identity{Role: tt.role}).OverrideReason; got != tt.want {
+				t.Fatalf("причина %q, ожидалась %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHidePassport(t *testing.T) {
+	for _, tt := range []struct {
+		role    auth.Role
+		visible bool
+	}{
+		{auth.RoleAdmin, true},
+		{auth.RoleManager, false},
+		{auth.RoleEmployee, false},
+	} {
+		p := model.People{PassportSerie: "1234", PassportNumber: "567890"}
+		hidePassport(auth.Identity{Role: tt.role}, &p)
+		if visible := p.PassportSerie != "" && p.PassportNumber != ""; visible != tt.visible {
+			t.Errorf("%s: паспорт виден %t, ожидалось %t", tt.role, visible, tt.visible)
+		}
+	}
+}
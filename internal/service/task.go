@@ -0,0 +1,202 @@
+package service
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/model"
+	"context"
+	"slices"
+)
+
+// taskAccess проверяет, что actor может выполнить действие над задачей, и возвращает базу
+func taskAccess(ctx context.Context, actor auth.Identity, permission auth.Permission, taskId int) (*database.Database, error) {
+	scope, err := allow(actor, permission)
+	if err != nil {
+		return nil, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return nil, err
+	}
+	owner, err := db.GetTaskOwner(ctx, taskId)
+	if err != nil {
+		return nil, err
+	}
+	return db, ensureInScope(ctx, db, actor, scope, owner)
+}
+
+// AddTask добавляет задачу и возвращает ее идентификатор
+func AddTask(ctx context.Context, actor auth.Identity, t model.Task) (int, error) {
+	if _, err := allow(actor, auth.TaskWrite); err != nil {
+		return 0, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return 0, err
+	}
+	return db.AddTask(ctx, auditActor(ctx, actor), t)
+}
+
+// AssignPeopleOnTask назначает сотрудника на задачу. И задача, и сотрудник должны быть доступны actor
+func AssignPeopleOnTask(ctx context.Context, actor auth.Identity, taskId, peopleId int) error {
+	db, err := taskAccess(ctx, actor, auth.TaskWrite, taskId)
+	if err != nil {
+		return err
+	}
+	if err = ensureInScope(ctx, db, actor, actor.ScopeOf(auth.TaskWrite), &peopleId); err != nil {
+		return err
+	}
+	return db.AssignPeopleOnTask(ctx, auditActor(ctx, actor), taskId, peopleId)
+}
+
+// SetTaskEstimate устанавливает или сбрасывает оценку задачи
+func SetTaskEstimate(ctx context.Context, actor auth.Identity, taskId int, estimateMinutes *int) error {
+	db, err := taskAccess(ctx, actor, auth.TaskWrite, taskId)
+	if err != nil {
+		return err
+	}
+	return db.SetTaskEstimate(ctx, auditActor(ctx, actor), taskId, estimateMinutes)
+}
+
+// StartTask начинает отсчет времени по задаче
+func StartTask(ctx context.Context, actor auth.Identity, taskId int) error {
+	db, err := taskAccess(ctx, actor, auth.TaskTimer, taskId)
+	if err != nil {
+		return err
+	}
+	return db.StartTaskTime(ctx, auditActor(ctx, actor), taskId)
+}
+
+// EndTask завершает отсчет времени по задаче
+func EndTask(ctx context.Context, actor auth.Identity, taskId int) error {
+	db, err := taskAccess(ctx, actor, auth.TaskTimer, taskId)
+	if err != nil {
+		return err
+	}
+	return db.EndTaskTime(ctx, auditActor(ctx, actor), taskId)
+}
+
+// DeleteTask помечает задачу удаленной
+func DeleteTask(ctx context.Context, actor auth.Identity, taskId int) error {
+	db, err := taskAccess(ctx, actor, auth.TaskWrite, taskId)
+	if err != nil {
+		return err
+	}
+	return db.DeleteTask(ctx, auditActor(ctx, actor), taskId)
+}
+
+// RestoreTask восстанавливает удаленную задачу
+func RestoreTask(ctx context.Context, actor auth.Identity, taskId int) error {
+	if _, err := allow(actor, auth.DeletedRestore); err != nil {
+		return err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return err
+	}
+	return db.RestoreTask(ctx, auditActor(ctx, actor), taskId)
+}
+
+// peopleTasksAccess проверяет, что actor может видеть задачи сотрудника, в том числе удаленные
+func peopleTasksAccess(ctx context.Context, actor auth.Identity, peopleId int, includeDeleted bool) (*database.Database, error) {
+	scope, err := allow(actor, auth.TaskRead)
+	if err != nil {
+		return nil, err
+	}
+	if includeDeleted {
+		if _, err = allow(actor, auth.DeletedRead); err != nil {
+			return nil, err
+		}
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return nil, err
+	}
+	return db, ensureInScope(ctx, db, actor, scope, &peopleId)
+}
+
+// GetPeopleTasks возвращает задачи сотрудника
+func GetPeopleTasks(ctx context.Context, actor auth.Identity, peopleId int, includeDeleted bool) ([]model.Task, error) {
+	db, err := peopleTasksAccess(ctx, actor, peopleId, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetPeopleTasks(ctx, peopleId, includeDeleted)
+}
+
+// GetTasksByPeople возвращает задачи нескольких сотрудников одним запросом.
+// Все сотрудники должны быть доступны actor
+func GetTasksByPeople(ctx context.Context, actor auth.Identity, peopleIds []int, includeDeleted bool) ([]model.Task, error) {
+	scope, err := allow(actor, auth.TaskRead)
+	if err != nil {
+		return nil, err
+	}
+	if includeDeleted {
+		if _, err = allow(actor, auth.DeletedRead); err != nil {
+			return nil, err
+		}
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return nil, err
+	}
+	if scope != auth.ScopeAll {
+		visible, err := visibleIds(ctx, db, actor, scope)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range peopleIds {
+			if !slices.Contains(visible, id) {
+				return nil, ErrForbidden
+			}
+		}
+	}
+	return db.GetTasksByPeople(ctx, peopleIds, includeDeleted)
+}
+
+// GetTask возвращает задачу с затраченным временем
+func GetTask(ctx context.Context, actor auth.Identity, taskId int) (model.Task, error) {
+	db, err := taskAccess(ctx, actor, auth.TaskRead, taskId)
+	if err != nil {
+		return model.Task{}, err
+	}
+	tasks, err := db.GetTasks(ctx, []int{taskId})
+	if err != nil {
+		return model.Task{}, err
+	}
+	if len(tasks) == 0 {
+		return model.Task{}, ErrNotFound
+	}
+	return tasks[0], nil
+}
+
+// EachPeopleTask передает в fn задачи сотрудника по одной
+func EachPeopleTask(ctx context.Context, actor auth.Identity, peopleId int, includeDeleted bool, fn func(model.Task) error) error {
+	db, err := peopleTasksAccess(ctx, actor, peopleId, includeDeleted)
+	if err != nil {
+		return err
+	}
+	return db.EachPeopleTask(ctx, peopleId, includeDeleted, fn)
+}
+
+// OffboardPeople передает задачи уходящего сотрудника получателям recipients по кругу и
+// останавливает его таймеры. Сотрудник и все получатели должны быть доступны actor
+func OffboardPeople(ctx context.Context, actor auth.Identity, peopleId int, recipients []int, preview bool) (model.Offboarding, error) {
+	scope, err := allow(actor, auth.TaskWrite)
+	if err != nil {
+		return model.Offboarding{}, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return model.Offboarding{}, err
+	}
+	for _, id := range append([]int{peopleId}, recipients...) {
+		if err = ensureInScope(ctx, db, actor, scope, &id); err != nil {
+			return model.Offboarding{}, err
+		}
+		if _, err = db.GetPeople(ctx, id); err != nil {
+			return model.Offboarding{}, err
+		}
+	}
+	return db.OffboardPeople(ctx, auditActor(ctx, actor), peopleId, recipients, preview)
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/model"
+	"context"
+	"time"
+)
+
+// GetTimesheet возвращает табель сотрудника peopleId за период, содержащий день date, с текущим
+// временем сотрудника в периоде
+func GetTimesheet(ctx context.Context, actor auth.Identity, peopleId int, period string, date time.Time) (model.Timesheet, error) {
+	scope, err := allow(actor, auth.ReportRead)
+	if err != nil {
+		return model.Timesheet{}, err
+	}
+	start, end, err := model.PeriodBounds(period, date)
+	if err != nil {
+		return model.Timesheet{}, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return model.Timesheet{}, err
+	}
+	if err = ensureInScope(ctx, db, actor, scope, &peopleId); err != nil {
+		return model.Timesheet{}, err
+	}
+
+	ts, err := db.GetTimesheet(ctx, peopleId, period, start, end)
+	if err != nil {
+		return model.Timesheet{}, err
+	}
+	tasks, err := db.GetTasksByPeople(ctx, []int{peopleId}, false)
+	if err != nil {
+		return model.Timesheet{}, err
+	}
+	now := time.Now()
+	for _, t := range tasks {
+		if entry, ok := t.TimeEntry(&ts.PeriodStart, &ts.PeriodEnd, now); ok {
+			ts.Entries = append(ts.Entries, entry)
+			ts.TotalMinutes += entry.Minutes
+		}
+	}
+	return ts, nil
+}
+
+// GetTimesheets возвращает отправленные табели сотрудников, доступных actor, в состоянии status
+// (пустая строка — в любом) с началом периода в [from, to)
+func GetTimesheets(ctx context.Context, actor auth.Identity, status string, from, to *time.Time) ([]model.Timesheet, error) {
+	scope, err := allow(actor, auth.ReportRead)
+	if err != nil {
+		return nil, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return nil, err
+	}
+	ids, err := visibleIds(ctx, db, actor, scope)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetTimesheets(ctx, ids, status, from, to)
+}
+
+// SubmitTimesheet отправляет на утверждение табель сотрудника peopleId за период, содержащий
+// день date, и возвращает его. В табеле сохраняется время сотрудника в периоде на момент отправки
+func SubmitTimesheet(ctx context.Context, actor auth.Identity, peopleId int, period string, date time.Time) (model.Timesheet, error) {
+	scope, err := allow(actor, auth.TimesheetSubmit)
+	if err != nil {
+		return model.Timesheet{}, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return model.Timesheet{}, err
+	}
+	if err = ensureInScope(ctx, db, actor, scope, &peopleId); err != nil {
+		return model.Timesheet{}, err
+	}
+
+	// Право на чтение табеля шире права на отправку, поэтому повторная проверка не мешает
+	ts, err := GetTimesheet(ctx, actor, peopleId, period, date)
+	if err != nil {
+		return model.Timesheet{}, err
+	}
+	if ts.Id, err = db.SubmitTimesheet(ctx, auditActor(ctx, actor), ts, ts.TotalMinutes); err != nil {
+		return model.Timesheet{}, err
+	}
+	ts.Status = model.TimesheetSubmitted
+	ts.SubmittedMinutes = &ts.TotalMinutes
+	ts.Comment, ts.DecidedAt, ts.DecidedBy = nil, nil, nil
+	submittedAt := time.Now()
+	ts.SubmittedAt = &submittedAt
+	return ts, nil
+}
+
+// ApproveTimesheet утверждает отправленный табель. После утверждения время сотрудника
+// в периоде нельзя изменить до возврата табеля
+func ApproveTimesheet(ctx context.Context, actor auth.Identity, id int) error {
+	return decideTimesheet(ctx, actor, id, database.AuditApprove, nil)
+}
+
+// RejectTimesheet отклоняет отправленный табель с комментарием. Отклоненный табель можно
+// исправить и отправить снова
+func RejectTimesheet(ctx context.Context, actor auth.Identity, id int, comment string) error {
+	return decideTimesheet(ctx, actor, id, database.AuditReject, &comment)
+}
+
+// ReopenTimesheet возвращает утвержденный или отклоненный табель в черновик, снимая
+// блокировку периода
+func ReopenTimesheet(ctx context.Context, actor auth.Identity, id int) error {
+	return decideTimesheet(ctx, actor, id, database.AuditReopen, nil)
+}
+
+// decideTimesheet выполняет действие руководителя над табелем. Свой табель руководитель
+// утверждать не может, это делает его руководитель или администратор
+func decideTimesheet(ctx context.Context, actor auth.Identity, id int, action string, comment *string) error {
+	scope, err := allow(actor, auth.TimesheetApprove)
+	if err != nil {
+		return err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return err
+	}
+	peopleId, err := db.GetTimesheetPeople(ctx, id)
+	if err != nil {
+		return err
+	}
+	if scope != auth.ScopeAll && actor.PeopleId != nil && *actor.PeopleId == peopleId {
+		return ErrForbidden
+	}
+	if err = ensureInScope(ctx, db, actor, scope, &peopleId); err != nil {
+		return err
+	}
+	return db.DecideTimesheet(ctx, auditActor(ctx, actor), id, action, comment)
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/model"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// secretBytes длина генерируемого секрета подписки
+const secretBytes = 32
+
+// GetWebhooks возвращает подписки на вебхуки без секретов
+func GetWebhooks(ctx context.Context, actor auth.Identity) ([]model.Webhook, error) {
+	if _, err := allow(actor, auth.WebhookManage); err != nil {
+		return nil, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return nil, err
+	}
+	return db.GetWebhooks(ctx)
+}
+
+// AddWebhook добавляет подписку. Если секрет не задан, он генерируется. Секрет возвращается
+// только в ответе на создание
+func AddWebhook(ctx context.Context, actor auth.Identity, w model.Webhook) (model.Webhook, error) {
+	if _, err := allow(actor, auth.WebhookManage); err != nil {
+		return model.Webhook{}, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return model.Webhook{}, err
+	}
+
+	if w.Secret == "" {
+		secret := make([]byte, secretBytes)
+		if _, err = rand.Read(secret); err != nil {
+			return model.Webhook{}, err
+		}
+		w.Secret = hex.EncodeToString(secret)
+	}
+	if w.EventTypes == nil {
+		w.EventTypes = []string{}
+	}
+	return db.AddWebhook(ctx, auditActor(ctx, actor), w)
+}
+
+// UpdateWebhook меняет подписку (см. database.UpdateWebhook)
+func UpdateWebhook(ctx context.Context, actor auth.Identity, w model.Webhook) error {
+	if _, err := allow(actor, auth.WebhookManage); err != nil {
+		return err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return err
+	}
+	if w.EventTypes == nil {
+		w.EventTypes = []string{}
+	}
+	return db.UpdateWebhook(ctx, auditActor(ctx, actor), w)
+}
+
+// DeleteWebhook удаляет подписку
+func DeleteWebhook(ctx context.Context, actor auth.Identity, id int) error {
+	if _, err := allow(actor, auth.WebhookManage); err != nil {
+		return err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return err
+	}
+	return db.DeleteWebhook(ctx, auditActor(ctx, actor), id)
+}
+
+// GetWebhookDeliveries возвращает страницу журнала доставок подписки
+func GetWebhookDeliveries(ctx context.Context, actor auth.Identity, webhookId int, status string, page, pageSize int) ([]model.WebhookDelivery, error) {
+	if _, err := allow(actor, auth.WebhookManage); err != nil {
+		return nil, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return nil, err
+	}
+	return db.GetWebhookDeliveries(ctx, webhookId, status, page, pageSize)
+}
+
+// RedeliverWebhook ставит событие доставки deliveryId в очередь повторно
+func RedeliverWebhook(ctx context.Context, actor auth.Identity, deliveryId int64) (model.WebhookDelivery, error) {
+	if _, err := allow(actor, auth.WebhookManage); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	db, err := database.GetInstance()
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	return db.RedeliverWebhook(ctx, deliveryId)
+}
@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"GoTimeTracker/pkg/logger"
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"os"
+)
+
+// ServiceName имя сервиса в трассировках, если OTEL_SERVICE_NAME не задан
+const ServiceName = "time-tracker"
+
+// instrumentation имя библиотеки, создающей собственные span'ы сервиса
+const instrumentation = "GoTimeTracker"
+
+// Start начинает span с именем name. Пока Init не вызван, span'ы никуда не отправляются
+func Start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name)
+}
+
+// Init настраивает экспорт трассировок по переменным окружения:
+//
+//	OTEL_TRACES_EXPORTER          otlp, stdout, file или none (по умолчанию otlp, если задан
+//	                              OTEL_EXPORTER_OTLP_ENDPOINT, иначе none)
+//	OTEL_EXPORTER_OTLP_ENDPOINT   адрес коллектора OTLP/HTTP, например http://localhost:4318
+//	OTEL_TRACES_FILE              файл для экспортера file
+//	OTEL_SERVICE_NAME             имя сервиса
+//
+// Контекст трассировки передается в заголовках W3C traceparent и tracestate в любом случае.
+// Возвращает функцию, отправляющую оставшиеся span'ы при остановке сервиса
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporterName := os.Getenv("OTEL_TRACES_EXPORTER")
+	if exporterName == "" && (os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "") {
+		exporterName = "otlp"
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch exporterName {
+	case "", "none":
+		logger.Info("Экспорт трассировок отключен")
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "file":
+		exporter, err = fileExporter(os.Getenv("OTEL_TRACES_FILE"))
+	default:
+		err = fmt.Errorf("неизвестный экспортер трассировок: %s", exporterName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = ServiceName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	logger.Info("Экспорт трассировок включен", zap.String("exporter", exporterName), zap.String("service", serviceName))
+	return provider.Shutdown, nil
+}
+
+// fileExporter пишет span'ы в файл построчно в формате JSON
+func fileExporter(path string) (sdktrace.SpanExporter, error) {
+	if path == "" {
+		return nil, fmt.Errorf("для экспортера file необходимо указать OTEL_TRACES_FILE")
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return stdouttrace.New(stdouttrace.WithWriter(file))
+}
@@ -0,0 +1,159 @@
+package webhooks
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/metrics"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/logger"
+	"GoTimeTracker/pkg/webhook"
+	"bytes"
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// userAgent заголовок User-Agent доставок
+const userAgent = "GoTimeTracker-Webhook/1"
+
+// maxDrainBody сколько байт ответа получателя дочитывается, чтобы соединение можно было
+// использовать повторно. Сам ответ не сохраняется: получатель не должен управлять тем,
+// что администратор увидит в журнале доставок
+const maxDrainBody = 64 << 10
+
+// queue очередь доставок, ее реализует *database.Database
+type queue interface {
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]database.WebhookJob, error)
+	RecordWebhookAttempt(ctx context.Context, actor model.AuditActor, job database.WebhookJob, attempt database.WebhookAttempt, disableAfter int) (bool, error)
+}
+
+// Dispatcher отправляет доставки из очереди подписчикам
+type Dispatcher struct {
+	db    queue
+	cfg   Config
+	http  *http.Client
+	actor model.AuditActor
+}
+
+// New создает Dispatcher. Доставки отправляются только на публичные адреса (см. ErrForbiddenTarget).
+// Перенаправления не выполняются: ответ 3xx считается неудачей
+func New(db *database.Database, cfg Config) *Dispatcher {
+	return &Dispatcher{
+		db:    db,
+		cfg:   cfg,
+		http:  newHTTPClient(cfg.Timeout),
+		actor: model.AuditActor{Kind: auth.KindSystem, Name: "webhooks"},
+	}
+}
+
+// Start запускает доставку до отмены ctx
+func (d *Dispatcher) Start(ctx context.Context) {
+	go d.deliverLoop(ctx)
+}
+
+// deliverLoop раз в Interval отправляет доставки, время которых наступило. Полная пачка
+// означает, что в очереди остались доставки, и следующая выбирается без ожидания
+func (d *Dispatcher) deliverLoop(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+	for ctx.Err() == nil {
+		if d.deliverBatch(ctx) == d.cfg.Batch {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverBatch отправляет одну пачку доставок и возвращает ее размер
+func (d *Dispatcher) deliverBatch(ctx context.Context) int {
+	// Доставка, не записанная за время ожидания ответа с запасом, будет выбрана повторно
+	lease := d.cfg.Timeout + time.Minute
+	jobs, err := d.db.ClaimWebhookDeliveries(ctx, d.cfg.Batch, lease)
+	if err != nil {
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, job)
+		}()
+	}
+	wg.Wait()
+	return len(jobs)
+}
+
+// deliver выполняет попытку доставки и сохраняет ее результат
+func (d *Dispatcher) deliver(ctx context.Context, job database.WebhookJob) {
+	log := logger.Ctx(ctx).With(zap.Int64("deliveryId", job.Id), zap.Int("webhookId", job.WebhookId),
+		zap.String("event", job.EventType))
+
+	start := time.Now()
+	attempt := d.send(ctx, job, start)
+	duration := time.Since(start)
+
+	outcome := metrics.WebhookDelivered
+	if !attempt.Success {
+		if attempts := job.Attempts + 1; attempts < d.cfg.MaxAttempts {
+			next := time.Now().Add(d.cfg.Backoff(attempts))
+			attempt.NextAttemptAt = &next
+			outcome = metrics.WebhookRetry
+		} else {
+			outcome = metrics.WebhookFailed
+		}
+	}
+	metrics.WebhookDelivery(outcome, duration)
+
+	disabled, err := d.db.RecordWebhookAttempt(ctx, d.actor, job, attempt, d.cfg.DisableAfter)
+	if err != nil {
+		return
+	}
+	switch {
+	case attempt.Success:
+		log.Debug("Вебхук доставлен", zap.Duration("duration", duration))
+	case attempt.NextAttemptAt != nil:
+		log.Info("Ошибка доставки вебхука, попытка будет повторена", zap.String("error", attempt.Error),
+			zap.Time("nextAttemptAt", *attempt.NextAttemptAt))
+	default:
+		log.Warn("Вебхук не доставлен, попытки исчерпаны", zap.String("error", attempt.Error))
+	}
+	if disabled {
+		log.Warn("Подписка отключена после неудачных доставок подряд", zap.Int("failures", d.cfg.DisableAfter))
+	}
+}
+
+// send отправляет подписанное событие. Успехом считается любой ответ 2xx
+func (d *Dispatcher) send(ctx context.Context, job database.WebhookJob, at time.Time) database.WebhookAttempt {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(job.Payload))
+	if err != nil {
+		return database.WebhookAttempt{Error: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(webhook.EventHeader, job.EventType)
+	req.Header.Set(webhook.DeliveryHeader, strconv.FormatInt(job.Id, 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(job.Secret, at, job.Payload))
+
+	resp, err := d.http.Do(req)
+	if err != nil {
+		return database.WebhookAttempt{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBody))
+
+	status := resp.StatusCode
+	if status >= 200 && status < 300 {
+		return database.WebhookAttempt{Success: true, StatusCode: &status}
+	}
+	return database.WebhookAttempt{StatusCode: &status, Error: fmt.Sprintf("HTTP %d", status)}
+}
@@ -0,0 +1,327 @@
+package webhooks
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/pkg/webhook"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testSecret = "whsec_test"
+
+// fakeQueue очередь доставок в памяти с той же логикой счетчика неудач, что у
+// database.RecordWebhookAttempt. Сам запрос проверяется интеграционным тестом database
+type fakeQueue struct {
+	mutex    sync.Mutex
+	jobs     []database.WebhookJob
+	attempts []database.WebhookAttempt
+	failures int
+	active   bool
+}
+
+func newFakeQueue(url string, deliveries int) *fakeQueue {
+	q := &fakeQueue{active: true}
+	for i := 1; i <= deliveries; i++ {
+		q.jobs = append(q.jobs, database.WebhookJob{
+			WebhookDelivery: model.WebhookDelivery{Id: int64(i), WebhookId: 1, EventId: int64(i), EventType: "task.created",
+				Payload: []byte(`{"id":` + strconv.Itoa(i) + `,"type":"task.created"}`), Status: model.DeliveryPending},
+			URL:    url,
+			Secret: testSecret,
+		})
+	}
+	return q
+}
+
+func (q *fakeQueue) ClaimWebhookDeliveries(_ context.Context, limit int, _ time.Duration) ([]database.WebhookJob, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	var due []database.WebhookJob
+	for _, job := range q.jobs {
+		if q.active && job.Status == model.DeliveryPending && len(due) < limit {
+			due = append(due, job)
+		}
+	}
+	return due, nil
+}
+
+func (q *fakeQueue) RecordWebhookAttempt(_ context.Context, _ model.AuditActor, job database.WebhookJob, attempt database.WebhookAttempt, disableAfter int) (bool, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.attempts = append(q.attempts, attempt)
+	for i := range q.jobs {
+		if q.jobs[i].Id != job.Id {
+			continue
+		}
+		q.jobs[i].Attempts++
+		switch {
+		case attempt.Success:
+			q.jobs[i].Status = model.DeliveryDelivered
+		case attempt.NextAttemptAt == nil:
+			q.jobs[i].Status = model.DeliveryFailed
+		}
+	}
+	if attempt.Success {
+		q.failures = 0
+		return false, nil
+	}
+	q.failures++
+	if disableAfter > 0 && q.failures == disableAfter {
+		q.active = false
+		return true, nil
+	}
+	return false, nil
+}
+
+// receiver получатель вебхуков, отвечающий status и проверяющий подпись
+type receiver struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	status   int
+	requests int
+	errors   []error
+}
+
+func newReceiver(t *testing.T, status int) *receiver {
+	t.Helper()
+	r := &receiver{status: status}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		err := webhook.Verify(testSecret, req.Header.Get(webhook.SignatureHeader), body, time.Minute, time.Now())
+		if err == nil && (req.Header.Get(webhook.EventHeader) != "task.created" || req.Header.Get(webhook.DeliveryHeader) == "" ||
+			req.Header.Get("Content-Type") != "application/json" || req.Header.Get("User-Agent") != userAgent) {
+			err = errors.New("неверные заголовки доставки")
+		}
+
+		r.mutex.Lock()
+		r.requests++
+		if err != nil {
+			r.errors = append(r.errors, err)
+		}
+		status := r.status
+		r.mutex.Unlock()
+
+		if status == http.StatusFound {
+			w.Header().Set("Location", "http://example.com/")
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("внутренние подробности получателя"))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) setStatus(status int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.status = status
+}
+
+// newTestDispatcher Dispatcher без запрета внутренних адресов: получатель httptest слушает loopback
+func newTestDispatcher(q queue, cfg Config) *Dispatcher {
+	d := New(nil, cfg)
+	d.db = q
+	d.http = &http.Client{Timeout: cfg.Timeout, CheckRedirect: d.http.CheckRedirect}
+	return d
+}
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.Timeout = 5 * time.Second
+	cfg.RetryBase = time.Minute
+	cfg.RetryMax = time.Hour
+	return cfg
+}
+
+func TestSendSignsDelivery(t *testing.T) {
+	r := newReceiver(t, http.StatusNoContent)
+	q := newFakeQueue(r.URL, 1)
+	d := newTestDispatcher(q, testConfig())
+
+	attempt := d.send(context.Background(), q.jobs[0], time.Now())
+	if !attempt.Success || attempt.StatusCode == nil || *attempt.StatusCode != http.StatusNoContent {
+		t.Fatalf("попытка %+v, ожидался успех", attempt)
+	}
+	if len(r.errors) > 0 {
+		t.Fatalf("получатель отклонил доставку: %v", r.errors)
+	}
+}
+
+func TestSendFailureOmitsResponseBody(t *testing.T) {
+	for _, status := range []int{http.StatusInternalServerError, http.StatusFound} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			r := newReceiver(t, status)
+			q := newFakeQueue(r.URL, 1)
+			d := newTestDispatcher(q, testConfig())
+
+			attempt := d.send(context.Background(), q.jobs[0], time.Now())
+			if attempt.Success || attempt.StatusCode == nil || *attempt.StatusCode != status {
+				t.Fatalf("попытка %+v, ожидалась неудача с кодом %d", attempt, status)
+			}
+			if want := "HTTP " + strconv.Itoa(status); attempt.Error != want {
+				t.Fatalf("ошибка %q, ожидалась %q без тела ответа", attempt.Error, want)
+			}
+		})
+	}
+}
+
+func TestDeliverRetriesWithBackoff(t *testing.T) {
+	r := newReceiver(t, http.StatusServiceUnavailable)
+	q := newFakeQueue(r.URL, 1)
+	cfg := testConfig()
+	cfg.MaxAttempts = 3
+	cfg.DisableAfter = 0
+	d := newTestDispatcher(q, cfg)
+
+	for attempt := 1; attempt <= cfg.MaxAttempts; attempt++ {
+		start := time.Now()
+		if n := d.deliverBatch(context.Background()); n != 1 {
+			t.Fatalf("попытка %d: выбрано доставок %d, ожидалась 1", attempt, n)
+		}
+		recorded := q.attempts[attempt-1]
+		if attempt == cfg.MaxAttempts {
+			if recorded.NextAttemptAt != nil {
+				t.Fatalf("после последней попытки назначен повтор на %s", recorded.NextAttemptAt)
+			}
+			break
+		}
+		if recorded.NextAttemptAt == nil {
+			t.Fatalf("попытка %d: повтор не назначен", attempt)
+		}
+		if delay := recorded.NextAttemptAt.Sub(start); delay < cfg.Backoff(attempt) || delay > cfg.Backoff(attempt)+time.Second {
+			t.Fatalf("попытка %d: повтор через %s, ожидалось %s", attempt, delay, cfg.Backoff(attempt))
+		}
+	}
+
+	if q.jobs[0].Status != model.DeliveryFailed || r.requests != cfg.MaxAttempts {
+		t.Fatalf("доставка %s после %d запросов, ожидалась failed после %d", q.jobs[0].Status, r.requests, cfg.MaxAttempts)
+	}
+	if n := d.deliverBatch(context.Background()); n != 0 {
+		t.Fatalf("неудачная доставка выбрана повторно")
+	}
+}
+
+func TestDeliverDisablesWebhook(t *testing.T) {
+	r := newReceiver(t, http.StatusInternalServerError)
+	q := newFakeQueue(r.URL, 5)
+	cfg := testConfig()
+	cfg.Batch = 1
+	cfg.DisableAfter = 3
+	d := newTestDispatcher(q, cfg)
+
+	// Успешная доставка сбрасывает счетчик неудач подряд
+	d.deliverBatch(context.Background())
+	d.deliverBatch(context.Background())
+	r.setStatus(http.StatusOK)
+	d.deliverBatch(context.Background())
+	r.setStatus(http.StatusInternalServerError)
+	for i := 0; i < cfg.DisableAfter; i++ {
+		if !q.active {
+			t.Fatalf("подписка отключена после %d неудач подряд, ожидалось %d", i, cfg.DisableAfter)
+		}
+		d.deliverBatch(context.Background())
+	}
+
+	if q.active {
+		t.Fatalf("подписка не отключена после %d неудач подряд", cfg.DisableAfter)
+	}
+	if n := d.deliverBatch(context.Background()); n != 0 {
+		t.Fatalf("отключенной подписке отправлено доставок: %d", n)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cfg := Config{RetryBase: 30 * time.Second, RetryMax: 10 * time.Minute}
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{6, 10 * time.Minute},
+		{40, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := cfg.Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, ожидалось %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestDeliveryClientRejectsInternalTargets(t *testing.T) {
+	r := newReceiver(t, http.StatusOK)
+	q := newFakeQueue(r.URL, 1)
+	d := New(nil, testConfig())
+	d.db = q
+
+	attempt := d.send(context.Background(), q.jobs[0], time.Now())
+	if attempt.Success || !strings.Contains(attempt.Error, ErrForbiddenTarget.Error()) {
+		t.Fatalf("попытка %+v, ожидался отказ в соединении с %s", attempt, r.URL)
+	}
+	if r.requests != 0 {
+		t.Fatal("запрос дошел до получателя во внутренней сети")
+	}
+}
+
+func TestForbiddenIP(t *testing.T) {
+	tests := []struct {
+		ip        string
+		forbidden bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"fe80::1", true},
+		{"fd00::1", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"100.64.0.1", true},
+		{"224.0.0.1", true},
+		{"::ffff:127.0.0.1", true},
+		{"93.184.216.34", false},
+		{"2606:4700::1111", false},
+	}
+	for _, tt := range tests {
+		if got := ForbiddenIP(net.ParseIP(tt.ip)); got != tt.forbidden {
+			t.Errorf("ForbiddenIP(%s) = %t, ожидалось %t", tt.ip, got, tt.forbidden)
+		}
+	}
+}
+
+func TestCheckTarget(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr error
+	}{
+		{"https://93.184.216.34/hooks", nil},
+		{"http://[2606:4700::1111]:8080/hooks", nil},
+		{"ftp://93.184.216.34/hooks", errInvalidTarget},
+		{"/hooks", errInvalidTarget},
+		{"http://127.0.0.1:8080/hooks", ErrForbiddenTarget},
+		{"http://169.254.169.254/latest/meta-data", ErrForbiddenTarget},
+		{"http://[::1]/hooks", ErrForbiddenTarget},
+		{"http://10.0.0.5/hooks", ErrForbiddenTarget},
+		{"http://localhost:8080/hooks", ErrForbiddenTarget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if err := CheckTarget(context.Background(), tt.url); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckTarget: %v, ожидалось %v", err, tt.wantErr)
+			}
+		})
+	}
+}
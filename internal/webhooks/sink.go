@@ -0,0 +1,34 @@
+package webhooks
+
+import (
+	"GoTimeTracker/database"
+	"GoTimeTracker/internal/events"
+	"context"
+	"encoding/json"
+)
+
+// Sink получатель доменных событий (см. outbox.Sink), ставящий их в очередь доставок
+// подписчикам. Повторная постановка того же события ничего не делает
+type Sink struct {
+	db *database.Database
+}
+
+// NewSink создает Sink
+func NewSink(db *database.Database) *Sink {
+	return &Sink{db: db}
+}
+
+// Name имя получателя
+func (s *Sink) Name() string {
+	return "webhooks"
+}
+
+// Deliver создает доставки события для подписок на его тип
+func (s *Sink) Deliver(ctx context.Context, e events.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.EnqueueWebhookDeliveries(ctx, int64(e.Id), e.Type, payload)
+	return err
+}
@@ -0,0 +1,101 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenTarget адрес получателя во внутренней сети: loopback, частные, link-local и
+// прочие адреса, недоступные из интернета. Вебхуки на них позволили бы администратору подписки
+// обращаться от имени сервиса к внутренним системам
+var ErrForbiddenTarget = errors.New("адрес получателя вебхука во внутренней сети запрещен")
+
+// errInvalidTarget адрес подписки не абсолютный адрес http или https
+var errInvalidTarget = errors.New("Адрес подписки должен быть абсолютным адресом http или https")
+
+// reservedNets непубличные сети, которые не считаются частными в net.IP.IsPrivate:
+// «эта сеть», CGNAT (RFC 6598) и сети для тестов производительности
+var reservedNets = []*net.IPNet{
+	{IP: net.IPv4(0, 0, 0, 0), Mask: net.CIDRMask(8, 32)},
+	{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)},
+	{IP: net.IPv4(198, 18, 0, 0), Mask: net.CIDRMask(15, 32)},
+}
+
+// ForbiddenIP адрес не публичный: loopback, частный, link-local, unspecified, multicast
+// или из reservedNets
+func ForbiddenIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsMulticast() {
+		return true
+	}
+	for _, reserved := range reservedNets {
+		if reserved.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTarget проверяет адрес подписки: абсолютный http или https, хост не во внутренней сети.
+// Имя хоста разрешается через DNS. Если разрешить его не удалось, адрес принимается: при отправке
+// адрес соединения все равно проверяет dialControl
+func CheckTarget(ctx context.Context, rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return errInvalidTarget
+	}
+
+	host := target.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if ForbiddenIP(ip) {
+			return ErrForbiddenTarget
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if ForbiddenIP(addr.IP) {
+			return ErrForbiddenTarget
+		}
+	}
+	return nil
+}
+
+// dialControl запрещает соединения с адресами во внутренней сети. Проверяется адрес, с которым
+// действительно устанавливается соединение, поэтому запрет не обходится ни перенаправлением DNS
+// после проверки подписки, ни именем хоста, разрешающимся в несколько адресов
+func dialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ForbiddenIP(ip) {
+		return ErrForbiddenTarget
+	}
+	return nil
+}
+
+// newHTTPClient клиент доставок: соединяется только с публичными адресами, не ходит через прокси
+// из окружения (иначе проверялся бы адрес прокси, а не получателя) и не выполняет перенаправления
+func newHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second, Control: dialControl}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: otelhttp.NewTransport(transport),
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,88 @@
+// Package webhooks доставка доменных событий подписчикам-вебхукам. Sink ставит события из
+// outbox (см. internal/outbox) в очередь webhook_delivery в базе, откуда их разбирает Dispatcher:
+// отправляет подписанный JSON (см. pkg/webhook), повторяет неудачные попытки с
+// экспоненциальной задержкой и отключает подписку после серии неудач
+package webhooks
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config настройки доставки
+type Config struct {
+	// Interval как часто проверяется очередь доставок. Ноль отключает доставку
+	Interval time.Duration
+	// Batch сколько доставок отправляется одновременно
+	Batch int
+	// Timeout время ожидания ответа получателя
+	Timeout time.Duration
+	// MaxAttempts попыток доставки одного события, после чего доставка считается неудачной
+	MaxAttempts int
+	// RetryBase задержка перед второй попыткой, каждая следующая вдвое больше
+	RetryBase time.Duration
+	// RetryMax наибольшая задержка между попытками
+	RetryMax time.Duration
+	// DisableAfter неудачных попыток подряд, после которых подписка отключается. Ноль не отключает
+	DisableAfter int
+}
+
+// DefaultConfig настройки по умолчанию: 8 попыток в течение примерно часа
+func DefaultConfig() Config {
+	return Config{
+		Interval:     2 * time.Second,
+		Batch:        20,
+		Timeout:      10 * time.Second,
+		MaxAttempts:  8,
+		RetryBase:    30 * time.Second,
+		RetryMax:     time.Hour,
+		DisableAfter: 50,
+	}
+}
+
+// ConfigFromEnv читает WEBHOOK_INTERVAL, WEBHOOK_BATCH, WEBHOOK_TIMEOUT, WEBHOOK_MAX_ATTEMPTS,
+// WEBHOOK_RETRY_BASE, WEBHOOK_RETRY_MAX и WEBHOOK_DISABLE_AFTER
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	durations := map[string]*time.Duration{
+		"WEBHOOK_INTERVAL":   &cfg.Interval,
+		"WEBHOOK_TIMEOUT":    &cfg.Timeout,
+		"WEBHOOK_RETRY_BASE": &cfg.RetryBase,
+		"WEBHOOK_RETRY_MAX":  &cfg.RetryMax,
+	}
+	for name, target := range durations {
+		if value := os.Getenv(name); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed < 0 || (parsed == 0 && name != "WEBHOOK_INTERVAL") {
+				return cfg, fmt.Errorf("неверное значение %s: %q", name, value)
+			}
+			*target = parsed
+		}
+	}
+	counts := map[string]*int{
+		"WEBHOOK_BATCH":         &cfg.Batch,
+		"WEBHOOK_MAX_ATTEMPTS":  &cfg.MaxAttempts,
+		"WEBHOOK_DISABLE_AFTER": &cfg.DisableAfter,
+	}
+	for name, target := range counts {
+		if value := os.Getenv(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 || (parsed == 0 && name != "WEBHOOK_DISABLE_AFTER") {
+				return cfg, fmt.Errorf("неверное значение %s: %q", name, value)
+			}
+			*target = parsed
+		}
+	}
+	return cfg, nil
+}
+
+// Backoff задержка перед попыткой после attempts неудачных: RetryBase·2^(attempts-1), не больше RetryMax
+func (c Config) Backoff(attempts int) time.Duration {
+	delay := c.RetryBase
+	for i := 1; i < attempts && delay < c.RetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, c.RetryMax)
+}
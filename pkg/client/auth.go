@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Authenticator добавляет к запросу данные для аутентификации
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthFunc функция как Authenticator, например для токенов из внешнего хранилища
+type AuthFunc func(req *http.Request) error
+
+func (f AuthFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// BearerToken аутентификация по JWT, выданному /login
+func BearerToken(token string) Authenticator {
+	return AuthFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// APIKey аутентификация по ключу доступа сервиса
+func APIKey(key string) Authenticator {
+	return AuthFunc(func(req *http.Request) error {
+		req.Header.Set("X-API-Key", key)
+		return nil
+	})
+}
+
+// LoginResponse токен, выданный по логину и паролю
+type LoginResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Identity пользователь или ключ доступа, от имени которого выполняются запросы
+type Identity struct {
+	Kind     string `json:"kind"`
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	PeopleId *int   `json:"people_id,omitempty"`
+}
+
+// Login проверяет логин и пароль и возвращает токен для BearerToken. Запрос не повторяется
+func (c *Client) Login(ctx context.Context, login, password string) (LoginResponse, error) {
+	var resp LoginResponse
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/login",
+		body:   map[string]string{"login": login, "password": password},
+	}, &resp)
+	return resp, err
+}
+
+// Me возвращает пользователя или ключ доступа, от имени которого работает клиент
+func (c *Client) Me(ctx context.Context) (Identity, error) {
+	var resp Identity
+	err := c.do(ctx, call{method: http.MethodGet, path: "/me", idempotent: true}, &resp)
+	return resp, err
+}
@@ -0,0 +1,249 @@
+// Package client типизированный клиент REST API тайм-трекера для других сервисов на Go.
+//
+//	c, err := client.New("http://tracker:8080", client.WithAuth(client.APIKey(key)))
+//	tasks, err := c.Tasks(ctx, peopleId, false)
+//
+// Ошибки сервера возвращаются как *Error с кодом ответа и текстом из ErrorResponse.
+// Идемпотентные запросы повторяются при сетевых ошибках и ответах 429, 502, 503 и 504
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultTimeout время ожидания ответа, если http.Client не передан через WithHTTPClient
+const defaultTimeout = 30 * time.Second
+
+// maxErrorBody сколько байт тела ответа с ошибкой читается для разбора
+const maxErrorBody = 64 << 10
+
+// Client клиент API. Безопасен для одновременного использования
+type Client struct {
+	baseURL   *url.URL
+	http      *http.Client
+	auth      Authenticator
+	retry     RetryPolicy
+	userAgent string
+}
+
+// Option настройка клиента
+type Option func(*Client)
+
+// WithHTTPClient задает http.Client, например с собственным транспортом или таймаутом
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.http = httpClient
+	}
+}
+
+// WithAuth задает способ аутентификации запросов
+func WithAuth(auth Authenticator) Option {
+	return func(c *Client) {
+		c.auth = auth
+	}
+}
+
+// WithRetry задает политику повторов идемпотентных запросов
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithUserAgent задает заголовок User-Agent
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New создает клиент для сервера по адресу baseURL
+func New(baseURL string, opts ...Option) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("неверный адрес сервера: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, fmt.Errorf("неверный адрес сервера %q", baseURL)
+	}
+
+	c := &Client{
+		baseURL:   parsed,
+		http:      &http.Client{Timeout: defaultTimeout},
+		retry:     DefaultRetryPolicy,
+		userAgent: "GoTimeTracker-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// call описание запроса к API
+type call struct {
+	method string
+	path   string
+	query  url.Values
+	// body тело запроса в формате JSON. Сериализуется один раз и повторяется при ретраях
+	body any
+	// raw тело запроса как есть, для загрузки файлов. Такие запросы не повторяются
+	raw         io.Reader
+	contentType string
+	// idempotent повторный запрос не меняет результат, его можно повторить при сбое
+	idempotent bool
+}
+
+// do выполняет запрос и разбирает ответ в out, если он не nil. Ответ с кодом 400 и выше
+// возвращается как *Error
+func (c *Client) do(ctx context.Context, call call, out any) error {
+	var payload []byte
+	if call.body != nil {
+		var err error
+		if payload, err = json.Marshal(call.body); err != nil {
+			return err
+		}
+	}
+
+	attempts := 1
+	if call.idempotent && call.raw == nil && c.retry.MaxAttempts > 1 {
+		attempts = c.retry.MaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, call, payload)
+		if err == nil && resp.StatusCode < http.StatusBadRequest {
+			defer resp.Body.Close()
+			if out == nil {
+				_, _ = io.Copy(io.Discard, resp.Body)
+				return nil
+			}
+			if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return fmt.Errorf("неверный ответ сервера: %w", err)
+			}
+			return nil
+		}
+		if err == nil {
+			err = readError(resp)
+		}
+		if attempt >= attempts || !retryable(ctx, err) {
+			return err
+		}
+		if err = c.retry.wait(ctx, attempt, err); err != nil {
+			return err
+		}
+	}
+}
+
+// send отправляет один запрос
+func (c *Client) send(ctx context.Context, call call, payload []byte) (*http.Response, error) {
+	endpoint := c.baseURL.JoinPath(call.path)
+	endpoint.RawQuery = call.query.Encode()
+
+	body, contentType := call.raw, call.contentType
+	if payload != nil {
+		body, contentType = bytes.NewReader(payload), "application/json"
+	}
+	req, err := http.NewRequestWithContext(ctx, call.method, endpoint.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.auth != nil {
+		if err = c.auth.Authenticate(req); err != nil {
+			return nil, fmt.Errorf("ошибка аутентификации запроса: %w", err)
+		}
+	}
+	return c.http.Do(req)
+}
+
+// readError читает ответ с ошибкой в *Error и закрывает тело
+func readError(resp *http.Response) *Error {
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+	e := &Error{StatusCode: resp.StatusCode, RequestId: resp.Header.Get("X-Request-ID"), body: data}
+	var body struct {
+		Error    string `json:"error"`
+		PeopleId int    `json:"people_id"`
+	}
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		e.Message, e.PeopleId = body.Error, body.PeopleId
+	} else if text := strings.TrimSpace(string(data)); text != "" && !json.Valid(data) {
+		e.Message = text
+	} else {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		e.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return e
+}
+
+// retryable можно ли повторить запрос, завершившийся ошибкой err
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	// Сетевая ошибка: сервер недоступен или соединение разорвано
+	return true
+}
+
+// RetryPolicy политика повторов идемпотентных запросов с экспоненциальной задержкой
+type RetryPolicy struct {
+	// MaxAttempts количество попыток, включая первую. 1 отключает повторы
+	MaxAttempts int
+	// MinBackoff задержка перед второй попыткой, далее удваивается
+	MinBackoff time.Duration
+	// MaxBackoff наибольшая задержка между попытками
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy политика повторов по умолчанию
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, MinBackoff: 200 * time.Millisecond, MaxBackoff: 5 * time.Second}
+
+// NoRetry отключает повторы
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// wait ждет перед попыткой attempt+1. Если сервер указал Retry-After, ждет не меньше него
+func (p RetryPolicy) wait(ctx context.Context, attempt int, err error) error {
+	delay := p.MinBackoff << (attempt - 1)
+	if delay > p.MaxBackoff || delay <= 0 {
+		delay = p.MaxBackoff
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > delay {
+		delay = apiErr.RetryAfter
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
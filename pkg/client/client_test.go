@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"GoTimeTracker/internal/auth"
+	"GoTimeTracker/internal/graph"
+	"GoTimeTracker/internal/model"
+	"GoTimeTracker/internal/ratelimit"
+	"GoTimeTracker/internal/request"
+	"GoTimeTracker/internal/routes"
+)
+
+func TestMain(m *testing.M) {
+	// Токены подписываются тем же секретом, что проверяет auth.Middleware
+	os.Setenv("JWT_SECRET", strings.Repeat("s", 32))
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// newAPIServer настоящие маршруты API. База данных не нужна, пока запрос отклоняется
+// до обработчика: аутентификацией, проверкой прав, разбором тела или ограничением частоты
+func newAPIServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	limits := ratelimit.DefaultConfig()
+	limits.Routes["/login"] = ratelimit.Limit{Rate: 0.001, Burst: 1}
+
+	engine := gin.New()
+	routes.SetupRoutes(engine, ratelimit.New(ratelimit.NewMemoryStore(), limits), request.DefaultBodyLimits(), graph.Options{Complexity: 1000})
+	server := httptest.NewServer(engine)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newClient(t *testing.T, url string, opts ...Option) *Client {
+	t.Helper()
+	c, err := New(url, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func token(t *testing.T, role auth.Role) Authenticator {
+	t.Helper()
+	value, _, err := auth.IssueToken(model.User{Id: 1, Login: string(role), Role: string(role)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return BearerToken(value)
+}
+
+func TestAPIErrors(t *testing.T) {
+	server := newAPIServer(t)
+	ctx := context.Background()
+
+	_, err := newClient(t, server.URL).Me(ctx)
+	var apiErr *Error
+	if !IsUnauthorized(err) || !errors.As(err, &apiErr) {
+		t.Fatalf("Me без токена: %v, ожидался 401", err)
+	}
+	if apiErr.Message != "Требуется аутентификация" || apiErr.RequestId == "" {
+		t.Fatalf("ошибка разобрана неверно: %+v", apiErr)
+	}
+
+	err = newClient(t, server.URL, WithAuth(token(t, auth.RoleEmployee))).AddPeople(ctx, "1234 567890")
+	if !IsForbidden(err) {
+		t.Fatalf("AddPeople от сотрудника: %v, ожидался 403", err)
+	}
+
+	identity, err := newClient(t, server.URL, WithAuth(token(t, auth.RoleAdmin))).Me(ctx)
+	if err != nil || identity.Role != string(auth.RoleAdmin) || identity.Kind != auth.KindUser {
+		t.Fatalf("Me: %+v, %v", identity, err)
+	}
+
+	anonymous := newClient(t, server.URL)
+	if _, err = anonymous.Login(ctx, "", ""); StatusCode(err) != http.StatusBadRequest {
+		t.Fatalf("Login без пароля: %v, ожидался 400", err)
+	}
+	_, err = anonymous.Login(ctx, "", "")
+	if StatusCode(err) != http.StatusTooManyRequests || !errors.As(err, &apiErr) || apiErr.RetryAfter <= 0 {
+		t.Fatalf("Login сверх ограничения: %v, ожидался 429 с Retry-After", err)
+	}
+}
+
+func TestReadError(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		wantMessage string
+		wantPeople  int
+	}{
+		{"ErrorResponse", "application/json", `{"error":"Сотрудник уже существует","people_id":5}`, http.StatusConflict, "Сотрудник уже существует", 5},
+		{"текст", "text/plain", "upstream unavailable\n", http.StatusBadGateway, "upstream unavailable", 0},
+		{"пустое тело", "", "", http.StatusNotFound, "Not Found", 0},
+		{"JSON без error", "application/json", `{"message":"x"}`, http.StatusInternalServerError, "Internal Server Error", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				w.Header().Set("X-Request-ID", "req-1")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			err := newClient(t, server.URL, WithRetry(NoRetry)).AddPeople(context.Background(), "1234 567890")
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("ошибка %v, ожидалась *Error", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.wantMessage || apiErr.PeopleId != tt.wantPeople || apiErr.RequestId != "req-1" {
+				t.Fatalf("ошибка разобрана неверно: %+v", apiErr)
+			}
+		})
+	}
+}
+
+// flakyServer отвечает status с Retry-After первые failures раз, затем пустым списком
+func flakyServer(t *testing.T, status int, retryAfter string, failures int32) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte("[]"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &attempts
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			server, attempts := flakyServer(t, status, "1", 1)
+			c := newClient(t, server.URL, WithRetry(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}))
+
+			start := time.Now()
+			if _, err := c.Tasks(context.Background(), 1, false); err != nil {
+				t.Fatalf("Tasks: %v", err)
+			}
+			if got := attempts.Load(); got != 2 {
+				t.Fatalf("попыток %d, ожидалось 2", got)
+			}
+			if elapsed := time.Since(start); elapsed < time.Second {
+				t.Fatalf("повтор через %s, ожидалось не раньше Retry-After", elapsed)
+			}
+		})
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	server, attempts := flakyServer(t, http.StatusServiceUnavailable, "", 10)
+	c := newClient(t, server.URL, WithRetry(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}))
+
+	if _, err := c.Tasks(context.Background(), 1, false); StatusCode(err) != http.StatusServiceUnavailable {
+		t.Fatalf("Tasks: %v, ожидался 503", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Fatalf("попыток %d, ожидалось 3", got)
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	server, attempts := flakyServer(t, http.StatusServiceUnavailable, "60", 10)
+	c := newClient(t, server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.Tasks(ctx, 1, false); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Tasks: %v, ожидалась отмена во время ожидания", err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("попыток %d, ожидалась 1", got)
+	}
+}
+
+func TestPostNotRetried(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(c *Client) error
+	}{
+		{"AddTask", func(c *Client) error {
+			return c.AddTask(context.Background(), model.Task{Name: "Задача", Description: "Описание"})
+		}},
+		{"AddPeople", func(c *Client) error {
+			return c.AddPeople(context.Background(), "1234 567890")
+		}},
+		{"Login", func(c *Client) error {
+			_, err := c.Login(context.Background(), "admin", "secret")
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, attempts := flakyServer(t, http.StatusServiceUnavailable, "", 10)
+			c := newClient(t, server.URL, WithRetry(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}))
+
+			if err := tt.call(c); StatusCode(err) != http.StatusServiceUnavailable {
+				t.Fatalf("ошибка %v, ожидался 503", err)
+			}
+			if got := attempts.Load(); got != 1 {
+				t.Fatalf("попыток %d, POST не должен повторяться", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Error ответ сервера с кодом 400 и выше
+type Error struct {
+	StatusCode int
+	// Message текст ошибки из ErrorResponse
+	Message string
+	// PeopleId существующий сотрудник при ответе 409 на добавление сотрудника
+	PeopleId int
+	// RequestId идентификатор запроса из X-Request-ID для поиска в журналах сервера
+	RequestId string
+	// RetryAfter через сколько можно повторить запрос при ответе 429
+	RetryAfter time.Duration
+
+	body []byte
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// StatusCode код ответа сервера для ошибки запроса, 0 для прочих ошибок
+func StatusCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound запись не найдена
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsForbidden недостаточно прав
+func IsForbidden(err error) bool {
+	return StatusCode(err) == http.StatusForbidden
+}
+
+// IsUnauthorized нет действующего токена или ключа доступа
+func IsUnauthorized(err error) bool {
+	return StatusCode(err) == http.StatusUnauthorized
+}
+
+// IsConflict запись уже существует или действие недопустимо в текущем состоянии табеля
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
+
+// IsLocked время относится к утвержденному табелю или закрытому периоду
+func IsLocked(err error) bool {
+	return StatusCode(err) == http.StatusLocked
+}
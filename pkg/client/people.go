@@ -0,0 +1,178 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"GoTimeTracker/internal/model"
+)
+
+// PeopleQuery параметры выборки сотрудников
+type PeopleQuery struct {
+	Page     int
+	PageSize int
+	// Filter название поля и значение через двоеточие, например "surname:Иванов"
+	Filter         string
+	IncludeDeleted bool
+}
+
+// People возвращает страницу сотрудников
+func (c *Client) People(ctx context.Context, q PeopleQuery) ([]model.People, error) {
+	query := url.Values{"page": {strconv.Itoa(q.Page)}, "page_size": {strconv.Itoa(q.PageSize)}}
+	if q.Filter != "" {
+		query.Set("filter", q.Filter)
+	}
+	if q.IncludeDeleted {
+		query.Set("include_deleted", "true")
+	}
+
+	var resp []model.People
+	err := c.do(ctx, call{method: http.MethodGet, path: "/allPeople", query: query, idempotent: true}, &resp)
+	return resp, err
+}
+
+// AddPeople добавляет сотрудника по паспорту ("1234 567890"). Если паспорт уже занят,
+// возвращает *Error с кодом 409 и идентификатором существующего сотрудника в PeopleId
+func (c *Client) AddPeople(ctx context.Context, passport string) error {
+	return c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/people",
+		query:  url.Values{"passportNumber": {passport}},
+	}, nil)
+}
+
+// UpdatePeople обновляет данные сотрудника people.Id. Серия и номер паспорта не изменяются
+func (c *Client) UpdatePeople(ctx context.Context, people model.People) error {
+	return c.do(ctx, call{method: http.MethodPut, path: "/people", body: people, idempotent: true}, nil)
+}
+
+// Что сделать с задачами удаляемого сотрудника
+const (
+	TasksKeep     = "keep"
+	TasksReassign = "reassign"
+	TasksCascade  = "cascade"
+)
+
+// DeletePeopleOptions что сделать с задачами удаляемого сотрудника
+type DeletePeopleOptions struct {
+	// Tasks TasksKeep, TasksReassign или TasksCascade, по умолчанию TasksKeep
+	Tasks string
+	// ReassignTo кому передать задачи при TasksReassign
+	ReassignTo int
+}
+
+// DeletePeople удаляет сотрудника
+func (c *Client) DeletePeople(ctx context.Context, id int, opts DeletePeopleOptions) error {
+	query := withOverride(ctx, url.Values{"id": {strconv.Itoa(id)}})
+	if opts.Tasks != "" {
+		query.Set("tasks", opts.Tasks)
+	}
+	if opts.ReassignTo != 0 {
+		query.Set("reassign_to", strconv.Itoa(opts.ReassignTo))
+	}
+	return c.do(ctx, call{method: http.MethodDelete, path: "/people", query: query, idempotent: true}, nil)
+}
+
+// RestorePeople восстанавливает удаленного сотрудника
+func (c *Client) RestorePeople(ctx context.Context, id int) error {
+	return c.do(ctx, call{
+		method:     http.MethodPut,
+		path:       "/peopleRestore",
+		query:      withOverride(ctx, url.Values{"id": {strconv.Itoa(id)}}),
+		idempotent: true,
+	}, nil)
+}
+
+// OffboardPeople останавливает задачи уходящего сотрудника и передает их сотрудникам to.
+// При preview только возвращает результат без сохранения
+func (c *Client) OffboardPeople(ctx context.Context, id int, to []int, preview bool) (model.Offboarding, error) {
+	recipients := make([]string, len(to))
+	for i, peopleId := range to {
+		recipients[i] = strconv.Itoa(peopleId)
+	}
+	query := withOverride(ctx, url.Values{"id": {strconv.Itoa(id)}, "to": {strings.Join(recipients, ",")}})
+	if preview {
+		query.Set("preview", "true")
+	}
+
+	var resp model.Offboarding
+	err := c.do(ctx, call{method: http.MethodPost, path: "/peopleOffboard", query: query, idempotent: preview}, &resp)
+	return resp, err
+}
+
+// ImportOptions параметры импорта сотрудников
+type ImportOptions struct {
+	// Format "csv" или "json"
+	Format string `json:"-"`
+	// DryRun только проверить данные, ничего не записывая
+	DryRun bool `json:"dry_run"`
+	// Atomic добавить сотрудников, только если ни одна строка не отклонена
+	Atomic bool `json:"atomic"`
+	// Concurrency одновременные запросы к внешнему API, 0 — значение сервера по умолчанию
+	Concurrency int `json:"concurrency"`
+}
+
+// ImportRow результат импорта строки
+type ImportRow struct {
+	Line     int           `json:"line"`
+	Passport string        `json:"passport"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	PeopleId int           `json:"people_id,omitempty"`
+	People   *model.People `json:"people,omitempty"`
+}
+
+// ImportReport результат импорта по каждой строке
+type ImportReport struct {
+	Options   ImportOptions `json:"options"`
+	Committed bool          `json:"committed"`
+	Total     int           `json:"total"`
+	Created   int           `json:"created"`
+	Rejected  int           `json:"rejected"`
+	Rows      []ImportRow   `json:"rows"`
+}
+
+// ImportPeople импортирует сотрудников из CSV (колонка passportNumber) или JSON-массива
+// [{"passportNumber": "1234 567890"}]. Если в режиме Atomic импорт отменен, возвращает
+// отчет вместе с *Error с кодом 422. Запрос не повторяется
+func (c *Client) ImportPeople(ctx context.Context, file io.Reader, opts ImportOptions) (ImportReport, error) {
+	format := opts.Format
+	if format == "" {
+		format = "json"
+	}
+	query := url.Values{"format": {format}}
+	if opts.DryRun {
+		query.Set("dry_run", "true")
+	}
+	if opts.Atomic {
+		query.Set("atomic", "true")
+	}
+	if opts.Concurrency > 0 {
+		query.Set("concurrency", strconv.Itoa(opts.Concurrency))
+	}
+	contentType := "application/json"
+	if format == "csv" {
+		contentType = "text/csv"
+	}
+
+	var report ImportReport
+	err := c.do(ctx, call{
+		method:      http.MethodPost,
+		path:        "/peopleImport",
+		query:       query,
+		raw:         file,
+		contentType: contentType,
+	}, &report)
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnprocessableEntity {
+		_ = json.Unmarshal(apiErr.body, &report)
+	}
+	return report, err
+}
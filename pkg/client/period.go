@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"GoTimeTracker/internal/model"
+)
+
+type overrideKey struct{}
+
+// WithOverride возвращает контекст, в котором назначение, запуск, остановка, удаление и
+// восстановление задачи, а также удаление, восстановление и передача задач сотрудника выполняются
+// в закрытом периоде с причиной reason. Сервер учитывает причину только для администраторов и
+// записывает ее в журнал изменений
+func WithOverride(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, overrideKey{}, reason)
+}
+
+// withOverride добавляет к query причину изменения в закрытом периоде из ctx
+func withOverride(ctx context.Context, query url.Values) url.Values {
+	if reason, _ := ctx.Value(overrideKey{}).(string); reason != "" {
+		query.Set("override_reason", reason)
+	}
+	return query
+}
+
+// closePeriodRequest тело закрытия периода
+type closePeriodRequest struct {
+	PeriodStart string `json:"period_start"`
+	PeriodEnd   string `json:"period_end"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// ClosedPeriods возвращает закрытые периоды, последние первыми
+func (c *Client) ClosedPeriods(ctx context.Context) ([]model.ClosedPeriod, error) {
+	var resp []model.ClosedPeriod
+	err := c.do(ctx, call{method: http.MethodGet, path: "/closedPeriod", idempotent: true}, &resp)
+	return resp, err
+}
+
+// ClosePeriod закрывает период с дня start по день end, не включая его. Изменение времени
+// задач в закрытом периоде возвращает ошибку, для которой IsLocked истинно
+func (c *Client) ClosePeriod(ctx context.Context, start, end time.Time, comment string) (model.ClosedPeriod, error) {
+	var resp model.ClosedPeriod
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/closedPeriod",
+		body:   closePeriodRequest{PeriodStart: start.Format(dateLayout), PeriodEnd: end.Format(dateLayout), Comment: comment},
+	}, &resp)
+	return resp, err
+}
+
+// ReopenPeriod снимает закрытие периода id
+func (c *Client) ReopenPeriod(ctx context.Context, id int) error {
+	return c.do(ctx, call{
+		method:     http.MethodDelete,
+		path:       "/closedPeriod",
+		query:      url.Values{"id": {strconv.Itoa(id)}},
+		idempotent: true,
+	}, nil)
+}
+
+// LockedEditsQuery параметры выборки отчета о попытках изменить заблокированное время.
+// Пустые поля не фильтруют
+type LockedEditsQuery struct {
+	Page     int
+	PageSize int
+	// Reason model.LockClosed или model.LockTimesheet
+	Reason string
+	From   *time.Time
+	// To конец периода, не включая
+	To *time.Time
+}
+
+// LockedEdits возвращает страницу отчета о попытках изменить время в закрытых периодах
+// и утвержденных табелях, новые попытки первыми
+func (c *Client) LockedEdits(ctx context.Context, q LockedEditsQuery) ([]model.LockedEdit, error) {
+	query := url.Values{"page": {strconv.Itoa(q.Page)}, "page_size": {strconv.Itoa(q.PageSize)}}
+	if q.Reason != "" {
+		query.Set("reason", q.Reason)
+	}
+	if q.From != nil {
+		query.Set("from", q.From.Format(time.RFC3339))
+	}
+	if q.To != nil {
+		query.Set("to", q.To.Format(time.RFC3339))
+	}
+
+	var resp []model.LockedEdit
+	err := c.do(ctx, call{method: http.MethodGet, path: "/lockedEdits", query: query, idempotent: true}, &resp)
+	return resp, err
+}
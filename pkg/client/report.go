@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"GoTimeTracker/internal/model"
+)
+
+// Группировка отчета по оценкам
+const (
+	GroupByTask    = "task"
+	GroupByPeople  = "people"
+	GroupByProject = "project"
+)
+
+// EstimateReport сравнивает оценку и фактически затраченное время. groupBy — GroupByTask,
+// GroupByPeople или GroupByProject, пустая строка группирует по задачам
+func (c *Client) EstimateReport(ctx context.Context, groupBy string) ([]model.EstimateReport, error) {
+	query := url.Values{}
+	if groupBy != "" {
+		query.Set("group_by", groupBy)
+	}
+
+	var resp []model.EstimateReport
+	err := c.do(ctx, call{method: http.MethodGet, path: "/estimateReport", query: query, idempotent: true}, &resp)
+	return resp, err
+}
+
+// AuditQuery параметры выборки журнала изменений. Пустые поля не фильтруют
+type AuditQuery struct {
+	Page      int
+	PageSize  int
+	ActorKind string
+	ActorId   int
+	Action    string
+	Entity    string
+	EntityId  int
+	RequestId string
+	ClientIp  string
+	From      *time.Time
+	// To конец периода, не включая
+	To *time.Time
+}
+
+// AuditLog возвращает страницу журнала изменений, новые записи первыми
+func (c *Client) AuditLog(ctx context.Context, q AuditQuery) ([]model.AuditEntry, error) {
+	query := url.Values{"page": {strconv.Itoa(q.Page)}, "page_size": {strconv.Itoa(q.PageSize)}}
+	for param, value := range map[string]string{
+		"actor_kind": q.ActorKind,
+		"action":     q.Action,
+		"entity":     q.Entity,
+		"request_id": q.RequestId,
+		"client_ip":  q.ClientIp,
+	} {
+		if value != "" {
+			query.Set(param, value)
+		}
+	}
+	if q.ActorId != 0 {
+		query.Set("actor_id", strconv.Itoa(q.ActorId))
+	}
+	if q.EntityId != 0 {
+		query.Set("entity_id", strconv.Itoa(q.EntityId))
+	}
+	if q.From != nil {
+		query.Set("from", q.From.Format(time.RFC3339))
+	}
+	if q.To != nil {
+		query.Set("to", q.To.Format(time.RFC3339))
+	}
+
+	var resp []model.AuditEntry
+	err := c.do(ctx, call{method: http.MethodGet, path: "/auditLog", query: query, idempotent: true}, &resp)
+	return resp, err
+}
+
+// logLevelResponse ответ /logLevel
+type logLevelResponse struct {
+	Level string `json:"level"`
+}
+
+// LogLevel возвращает текущий уровень журнала сервера
+func (c *Client) LogLevel(ctx context.Context) (string, error) {
+	var resp logLevelResponse
+	err := c.do(ctx, call{method: http.MethodGet, path: "/logLevel", idempotent: true}, &resp)
+	return resp.Level, err
+}
+
+// SetLogLevel меняет уровень журнала сервера до перезапуска: debug, info, warn или error
+func (c *Client) SetLogLevel(ctx context.Context, level string) (string, error) {
+	var resp logLevelResponse
+	err := c.do(ctx, call{
+		method:     http.MethodPut,
+		path:       "/logLevel",
+		query:      url.Values{"level": {level}},
+		idempotent: true,
+	}, &resp)
+	return resp.Level, err
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"GoTimeTracker/internal/model"
+)
+
+// AddTask добавляет задачу. Используются поля Name, Description, Project и EstimateMinutes
+func (c *Client) AddTask(ctx context.Context, task model.Task) error {
+	query := url.Values{"name": {task.Name}, "description": {task.Description}}
+	if task.Project != nil {
+		query.Set("project", *task.Project)
+	}
+	if task.EstimateMinutes != nil {
+		query.Set("estimate_minutes", strconv.Itoa(*task.EstimateMinutes))
+	}
+	return c.do(ctx, call{method: http.MethodPost, path: "/task", query: query}, nil)
+}
+
+// Tasks возвращает задачи сотрудника. Удаленные задачи видны только администраторам
+func (c *Client) Tasks(ctx context.Context, peopleId int, includeDeleted bool) ([]model.Task, error) {
+	query := url.Values{"people_id": {strconv.Itoa(peopleId)}}
+	if includeDeleted {
+		query.Set("include_deleted", "true")
+	}
+
+	var resp []model.Task
+	err := c.do(ctx, call{method: http.MethodGet, path: "/task", query: query, idempotent: true}, &resp)
+	return resp, err
+}
+
+// AssignTask назначает сотрудника на задачу
+func (c *Client) AssignTask(ctx context.Context, id, peopleId int) error {
+	return c.do(ctx, call{
+		method:     http.MethodPut,
+		path:       "/taskAssign",
+		query:      withOverride(ctx, url.Values{"id": {strconv.Itoa(id)}, "people_id": {strconv.Itoa(peopleId)}}),
+		idempotent: true,
+	}, nil)
+}
+
+// SetTaskEstimate задает оценку задачи в минутах, nil снимает оценку
+func (c *Client) SetTaskEstimate(ctx context.Context, id int, minutes *int) error {
+	query := url.Values{"id": {strconv.Itoa(id)}}
+	if minutes != nil {
+		query.Set("estimate_minutes", strconv.Itoa(*minutes))
+	}
+	return c.do(ctx, call{method: http.MethodPut, path: "/taskEstimate", query: query, idempotent: true}, nil)
+}
+
+// StartTask начинает отсчет времени по задаче. Запрос не повторяется: повтор сдвинул бы начало
+func (c *Client) StartTask(ctx context.Context, id int) error {
+	return c.do(ctx, call{method: http.MethodPut, path: "/taskStart", query: withOverride(ctx, url.Values{"id": {strconv.Itoa(id)}})}, nil)
+}
+
+// EndTask останавливает отсчет времени по задаче. Запрос не повторяется: повтор сдвинул бы конец
+func (c *Client) EndTask(ctx context.Context, id int) error {
+	return c.do(ctx, call{method: http.MethodPut, path: "/taskEnd", query: withOverride(ctx, url.Values{"id": {strconv.Itoa(id)}})}, nil)
+}
+
+// DeleteTask удаляет задачу
+func (c *Client) DeleteTask(ctx context.Context, id int) error {
+	return c.do(ctx, call{
+		method:     http.MethodDelete,
+		path:       "/task",
+		query:      withOverride(ctx, url.Values{"id": {strconv.Itoa(id)}}),
+		idempotent: true,
+	}, nil)
+}
+
+// RestoreTask восстанавливает удаленную задачу
+func (c *Client) RestoreTask(ctx context.Context, id int) error {
+	return c.do(ctx, call{
+		method:     http.MethodPut,
+		path:       "/taskRestore",
+		query:      withOverride(ctx, url.Values{"id": {strconv.Itoa(id)}}),
+		idempotent: true,
+	}, nil)
+}
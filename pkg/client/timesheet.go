@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"GoTimeTracker/internal/model"
+)
+
+// dateLayout формат дня в параметрах табеля
+const dateLayout = "2006-01-02"
+
+// timesheetQuery параметры табеля сотрудника peopleId (0 — свой) за период, содержащий день date
+// (нулевой — сегодня). Пустой period означает неделю
+func timesheetQuery(peopleId int, period string, date time.Time) url.Values {
+	query := url.Values{}
+	if peopleId != 0 {
+		query.Set("people_id", strconv.Itoa(peopleId))
+	}
+	if period != "" {
+		query.Set("period", period)
+	}
+	if !date.IsZero() {
+		query.Set("date", date.Format(dateLayout))
+	}
+	return query
+}
+
+// Timesheet возвращает табель сотрудника peopleId (0 — свой) за неделю или месяц
+// (model.TimesheetWeek, model.TimesheetMonth), содержащий день date (нулевой — сегодня)
+func (c *Client) Timesheet(ctx context.Context, peopleId int, period string, date time.Time) (model.Timesheet, error) {
+	var resp model.Timesheet
+	err := c.do(ctx, call{
+		method:     http.MethodGet,
+		path:       "/timesheet",
+		query:      timesheetQuery(peopleId, period, date),
+		idempotent: true,
+	}, &resp)
+	return resp, err
+}
+
+// Timesheets возвращает отправленные табели доступных сотрудников в состоянии status (пустая
+// строка — в любом) с началом периода в [from, to), новые периоды первыми
+func (c *Client) Timesheets(ctx context.Context, status string, from, to *time.Time) ([]model.Timesheet, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	if from != nil {
+		query.Set("from", from.Format(dateLayout))
+	}
+	if to != nil {
+		query.Set("to", to.Format(dateLayout))
+	}
+
+	var resp []model.Timesheet
+	err := c.do(ctx, call{method: http.MethodGet, path: "/timesheets", query: query, idempotent: true}, &resp)
+	return resp, err
+}
+
+// SubmitTimesheet отправляет табель на утверждение. Повторная отправка уже отправленного
+// табеля возвращает ошибку, для которой IsConflict истинно
+func (c *Client) SubmitTimesheet(ctx context.Context, peopleId int, period string, date time.Time) (model.Timesheet, error) {
+	var resp model.Timesheet
+	err := c.do(ctx, call{method: http.MethodPut, path: "/timesheetSubmit", query: timesheetQuery(peopleId, period, date)}, &resp)
+	return resp, err
+}
+
+// ApproveTimesheet утверждает табель id. Время сотрудника в периоде блокируется до ReopenTimesheet
+func (c *Client) ApproveTimesheet(ctx context.Context, id int) error {
+	return c.do(ctx, call{method: http.MethodPut, path: "/timesheetApprove", query: url.Values{"id": {strconv.Itoa(id)}}}, nil)
+}
+
+// timesheetRejectRequest тело отклонения табеля
+type timesheetRejectRequest struct {
+	Comment string `json:"comment"`
+}
+
+// RejectTimesheet отклоняет табель id с комментарием
+func (c *Client) RejectTimesheet(ctx context.Context, id int, comment string) error {
+	return c.do(ctx, call{
+		method: http.MethodPut,
+		path:   "/timesheetReject",
+		query:  url.Values{"id": {strconv.Itoa(id)}},
+		body:   timesheetRejectRequest{Comment: comment},
+	}, nil)
+}
+
+// ReopenTimesheet возвращает утвержденный или отклоненный табель id в черновик
+func (c *Client) ReopenTimesheet(ctx context.Context, id int) error {
+	return c.do(ctx, call{method: http.MethodPut, path: "/timesheetReopen", query: url.Values{"id": {strconv.Itoa(id)}}}, nil)
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"GoTimeTracker/internal/model"
+)
+
+// webhookRequest тело создания и изменения подписки
+type webhookRequest struct {
+	Id         int      `json:"id,omitempty"`
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	EventTypes []string `json:"event_types"`
+	Active     *bool    `json:"active,omitempty"`
+}
+
+// Webhooks возвращает подписки на вебхуки без секретов
+func (c *Client) Webhooks(ctx context.Context) ([]model.Webhook, error) {
+	var resp []model.Webhook
+	err := c.do(ctx, call{method: http.MethodGet, path: "/webhook", idempotent: true}, &resp)
+	return resp, err
+}
+
+// AddWebhook добавляет подписку на события eventTypes, пустой список подписывает на все.
+// Если секрет не задан, сервер генерирует его. Секрет возвращается только здесь, подпись
+// доставок проверяется пакетом pkg/webhook
+func (c *Client) AddWebhook(ctx context.Context, target, secret string, eventTypes []string) (model.Webhook, error) {
+	var resp model.Webhook
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/webhook",
+		body:   webhookRequest{URL: target, Secret: secret, EventTypes: eventTypes},
+	}, &resp)
+	return resp, err
+}
+
+// UpdateWebhook меняет подписку w.Id. Пустой w.Secret оставляет прежний секрет, включение
+// подписки сбрасывает счетчик неудачных доставок
+func (c *Client) UpdateWebhook(ctx context.Context, w model.Webhook) error {
+	return c.do(ctx, call{
+		method:     http.MethodPut,
+		path:       "/webhook",
+		body:       webhookRequest{Id: w.Id, URL: w.URL, Secret: w.Secret, EventTypes: w.EventTypes, Active: &w.Active},
+		idempotent: true,
+	}, nil)
+}
+
+// DeleteWebhook удаляет подписку вместе с журналом доставок
+func (c *Client) DeleteWebhook(ctx context.Context, id int) error {
+	return c.do(ctx, call{
+		method:     http.MethodDelete,
+		path:       "/webhook",
+		query:      url.Values{"id": {strconv.Itoa(id)}},
+		idempotent: true,
+	}, nil)
+}
+
+// WebhookDeliveries возвращает страницу журнала доставок подписки, новые первыми. status —
+// model.DeliveryPending, model.DeliveryDelivered, model.DeliveryFailed или пустая строка
+func (c *Client) WebhookDeliveries(ctx context.Context, webhookId int, status string, page, pageSize int) ([]model.WebhookDelivery, error) {
+	query := url.Values{
+		"webhook_id": {strconv.Itoa(webhookId)},
+		"page":       {strconv.Itoa(page)},
+		"page_size":  {strconv.Itoa(pageSize)},
+	}
+	if status != "" {
+		query.Set("status", status)
+	}
+
+	var resp []model.WebhookDelivery
+	err := c.do(ctx, call{method: http.MethodGet, path: "/webhookDeliveries", query: query, idempotent: true}, &resp)
+	return resp, err
+}
+
+// RedeliverWebhook ставит событие доставки id в очередь повторно и возвращает новую доставку
+func (c *Client) RedeliverWebhook(ctx context.Context, id int64) (model.WebhookDelivery, error) {
+	var resp model.WebhookDelivery
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/webhookRedeliver",
+		query:  url.Values{"id": {strconv.FormatInt(id, 10)}},
+	}, &resp)
+	return resp, err
+}
@@ -0,0 +1,36 @@
+// Package config читает настройки сервиса из переменных окружения
+package config
+
+import (
+	"GoTimeTracker/pkg/logger"
+	"go.uber.org/zap"
+	"os"
+	"strings"
+	"time"
+)
+
+// Duration читает продолжительность из переменной окружения name, fallback если она не задана.
+// Неверное значение останавливает сервис: настройки проверяются при запуске
+func Duration(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		logger.Fatal("Неверное значение продолжительности", zap.String("name", name), zap.Error(err))
+	}
+	return duration
+}
+
+// List читает список значений через запятую из переменной окружения name. Пустые элементы
+// отбрасываются, nil если переменная не задана
+func List(name string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	t.Setenv("TEST_DURATION", "")
+	if got := Duration("TEST_DURATION", time.Minute); got != time.Minute {
+		t.Fatalf("без переменной %s, ожидалось значение по умолчанию", got)
+	}
+
+	t.Setenv("TEST_DURATION", "90s")
+	if got := Duration("TEST_DURATION", time.Minute); got != 90*time.Second {
+		t.Fatalf("получено %s, ожидалось 1m30s", got)
+	}
+}
+
+func TestList(t *testing.T) {
+	t.Setenv("TEST_LIST", "")
+	if got := List("TEST_LIST"); got != nil {
+		t.Fatalf("без переменной %v, ожидался nil", got)
+	}
+
+	t.Setenv("TEST_LIST", " 10.0.0.1, ,10.0.1.0/24 ")
+	if got := List("TEST_LIST"); len(got) != 2 || got[0] != "10.0.0.1" || got[1] != "10.0.1.0/24" {
+		t.Fatalf("получено %v", got)
+	}
+}
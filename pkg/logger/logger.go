@@ -1,60 +1,222 @@
 package logger
 
 import (
+	"context"
+	"fmt"
 	"github.com/natefinch/lumberjack"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
-var zapLog *zap.Logger
+// Config настройки логгера
+type Config struct {
+	// Level минимальный уровень записей: debug, info, warn, error
+	Level string
+	// Format формат записей в stdout и stderr: console или json. В файл записи пишутся в JSON
+	Format string
+	// Outputs куда писать записи: stdout, stderr, file
+	Outputs []string
+	// File путь к файлу журнала и параметры его ротации
+	File       string
+	MaxSize    int // мегабайты
+	MaxBackups int
+	MaxAge     int // дни
+	Compress   bool
+}
 
-func init() {
-	zapLog = CreateLogger()
+// DefaultConfig настройки, с которыми логгер работает до вызова Configure
+func DefaultConfig() Config {
+	return Config{
+		Level:      "info",
+		Format:     "console",
+		Outputs:    []string{"stdout", "file"},
+		File:       "pkg/logger/app.log",
+		MaxSize:    10,
+		MaxBackups: 3,
+		MaxAge:     7,
+	}
 }
 
-func CreateLogger() *zap.Logger {
-	stdout := zapcore.AddSync(os.Stdout)
+// ConfigFromEnv читает настройки из переменных окружения LOG_LEVEL, LOG_FORMAT, LOG_OUTPUTS
+// (через запятую), LOG_FILE, LOG_MAX_SIZE, LOG_MAX_BACKUPS, LOG_MAX_AGE и LOG_COMPRESS.
+// Незаданные переменные берутся из DefaultConfig
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		cfg.Level = value
+	}
+	if value := os.Getenv("LOG_FORMAT"); value != "" {
+		cfg.Format = value
+	}
+	if value := os.Getenv("LOG_OUTPUTS"); value != "" {
+		cfg.Outputs = nil
+		for _, output := range strings.Split(value, ",") {
+			cfg.Outputs = append(cfg.Outputs, strings.TrimSpace(output))
+		}
+	}
+	if value := os.Getenv("LOG_FILE"); value != "" {
+		cfg.File = value
+	}
+	for name, target := range map[string]*int{"LOG_MAX_SIZE": &cfg.MaxSize, "LOG_MAX_BACKUPS": &cfg.MaxBackups, "LOG_MAX_AGE": &cfg.MaxAge} {
+		if value := os.Getenv(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return cfg, fmt.Errorf("неверное значение %s: %w", name, err)
+			}
+			*target = parsed
+		}
+	}
+	if value := os.Getenv("LOG_COMPRESS"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return cfg, fmt.Errorf("неверное значение LOG_COMPRESS: %w", err)
+		}
+		cfg.Compress = parsed
+	}
+	return cfg, nil
+}
 
-	file := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   "pkg/logger/app.log",
-		MaxSize:    10, // megabytes
-		MaxBackups: 3,
-		MaxAge:     7, // days
-	})
+var (
+	zapLog atomic.Pointer[zap.Logger]
+	// level общий для всех конфигураций, поэтому SetLevel действует без пересоздания логгера
+	level = zap.NewAtomicLevelAt(zap.InfoLevel)
 
-	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+	// configMutex защищает file при повторной настройке
+	configMutex sync.Mutex
+	file        *lumberjack.Logger
+)
+
+func init() {
+	if err := Configure(DefaultConfig()); err != nil {
+		panic(err)
+	}
+}
+
+// Configure пересоздает логгер с настройками cfg. Может вызываться повторно, например по SIGHUP:
+// записи, уже начатые старым логгером, дописываются, файл старого логгера закрывается
+func Configure(cfg Config) error {
+	var newLevel zapcore.Level
+	if err := newLevel.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return fmt.Errorf("неизвестный уровень журнала: %s", cfg.Level)
+	}
 
 	productionCfg := zap.NewProductionEncoderConfig()
 	productionCfg.TimeKey = "timestamp"
 	productionCfg.EncodeTime = zapcore.ISO8601TimeEncoder
 
-	developmentCfg := zap.NewDevelopmentEncoderConfig()
-	developmentCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	var streamEncoder zapcore.Encoder
+	switch cfg.Format {
+	case "console":
+		developmentCfg := zap.NewDevelopmentEncoderConfig()
+		developmentCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		streamEncoder = zapcore.NewConsoleEncoder(developmentCfg)
+	case "json":
+		streamEncoder = zapcore.NewJSONEncoder(productionCfg)
+	default:
+		return fmt.Errorf("неизвестный формат журнала: %s", cfg.Format)
+	}
 
-	consoleEncoder := zapcore.NewConsoleEncoder(developmentCfg)
-	fileEncoder := zapcore.NewJSONEncoder(productionCfg)
+	var cores []zapcore.Core
+	var newFile *lumberjack.Logger
+	for _, output := range cfg.Outputs {
+		switch output {
+		case "stdout":
+			cores = append(cores, zapcore.NewCore(streamEncoder, zapcore.AddSync(os.Stdout), level))
+		case "stderr":
+			cores = append(cores, zapcore.NewCore(streamEncoder, zapcore.AddSync(os.Stderr), level))
+		case "file":
+			if cfg.File == "" {
+				return fmt.Errorf("для вывода file необходимо указать путь к файлу журнала")
+			}
+			newFile = &lumberjack.Logger{
+				Filename:   cfg.File,
+				MaxSize:    cfg.MaxSize,
+				MaxBackups: cfg.MaxBackups,
+				MaxAge:     cfg.MaxAge,
+				Compress:   cfg.Compress,
+			}
+			cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(productionCfg), zapcore.AddSync(newFile), level))
+		default:
+			return fmt.Errorf("неизвестный вывод журнала: %s", output)
+		}
+	}
 
-	core := zapcore.NewTee(
-		zapcore.NewCore(consoleEncoder, stdout, level),
-		zapcore.NewCore(fileEncoder, file, level),
-	)
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	level.SetLevel(newLevel)
+	if old := zapLog.Swap(zap.New(zapcore.NewTee(cores...))); old != nil {
+		_ = old.Sync()
+	}
+	if file != nil {
+		_ = file.Close()
+	}
+	file = newFile
+	return nil
+}
+
+// Level возвращает текущий уровень журнала
+func Level() string {
+	return level.String()
+}
+
+// SetLevel меняет уровень журнала без пересоздания логгера, в том числе для логгеров запросов
+func SetLevel(text string) error {
+	var newLevel zapcore.Level
+	if err := newLevel.UnmarshalText([]byte(text)); err != nil {
+		return fmt.Errorf("неизвестный уровень журнала: %s", text)
+	}
+	level.SetLevel(newLevel)
+	return nil
+}
+
+type loggerKey struct{}
 
-	return zap.New(core)
+// With возвращает контекст, записи логгера которого дополняются полями fields.
+// Поля накапливаются: запрос добавляет request_id и маршрут, аутентификация — пользователя
+func With(ctx context.Context, fields ...zap.Field) context.Context {
+	return context.WithValue(ctx, loggerKey{}, fromContext(ctx).With(fields...))
+}
+
+// fromContext возвращает логгер, сохраненный With, или общий логгер
+func fromContext(ctx context.Context) *zap.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return l
+	}
+	return zapLog.Load()
+}
+
+// Ctx возвращает логгер запроса из ctx (см. With), добавляющий к записям trace_id и span_id
+// текущего span'а, чтобы записи можно было сопоставить с трассировкой
+func Ctx(ctx context.Context) *zap.Logger {
+	l := fromContext(ctx)
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return l
+	}
+	return l.With(
+		zap.String("trace_id", spanContext.TraceID().String()),
+		zap.String("span_id", spanContext.SpanID().String()),
+	)
 }
 
 func Info(message string, fields ...zap.Field) {
-	zapLog.Info(message, fields...)
+	zapLog.Load().Info(message, fields...)
 }
 
 func Debug(message string, fields ...zap.Field) {
-	zapLog.Debug(message, fields...)
+	zapLog.Load().Debug(message, fields...)
 }
 
 func Error(message string, fields ...zap.Field) {
-	zapLog.Error(message, fields...)
+	zapLog.Load().Error(message, fields...)
 }
 
 func Fatal(message string, fields ...zap.Field) {
-	zapLog.Fatal(message, fields...)
+	zapLog.Load().Fatal(message, fields...)
 }
@@ -0,0 +1,89 @@
+// Package webhook подпись доставок исходящих вебхуков тайм-трекера и ее проверка на стороне
+// получателя:
+//
+//	body, _ := io.ReadAll(r.Body)
+//	err := webhook.Verify(secret, r.Header.Get(webhook.SignatureHeader), body, 5*time.Minute, time.Now())
+//
+// Подпись — HMAC-SHA256 от "<время>.<тело>" с секретом подписки. Время в подписи защищает от
+// повторной отправки перехваченного запроса
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Заголовки доставки
+const (
+	// SignatureHeader подпись в формате "t=<unix-время>,v1=<hex HMAC-SHA256>"
+	SignatureHeader = "X-Tracker-Signature"
+	// EventHeader тип события
+	EventHeader = "X-Tracker-Event"
+	// DeliveryHeader идентификатор доставки. Повторные попытки одной доставки передают тот же
+	// идентификатор, по нему получатель отбрасывает дубликаты
+	DeliveryHeader = "X-Tracker-Delivery"
+)
+
+var (
+	// ErrInvalidSignature подпись отсутствует, повреждена или не совпадает
+	ErrInvalidSignature = errors.New("неверная подпись вебхука")
+	// ErrExpiredSignature подпись старше допустимого
+	ErrExpiredSignature = errors.New("подпись вебхука устарела")
+)
+
+// Sign возвращает значение SignatureHeader для тела body, отправленного в момент at
+func Sign(secret string, at time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, hex.EncodeToString(mac(secret, timestamp, body)))
+}
+
+// Verify проверяет подпись header тела body. Подпись отклоняется, если она сделана больше
+// tolerance назад относительно now. Нулевой tolerance не проверяет время
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			if signature, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	expected := mac(secret, timestamp, body)
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			if tolerance > 0 && now.Sub(time.Unix(seconds, 0)).Abs() > tolerance {
+				return ErrExpiredSignature
+			}
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// mac HMAC-SHA256 от "<timestamp>.<body>"
+func mac(secret, timestamp string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte(`{"id":1,"type":"task.created"}`)
+	at := time.Unix(1700000000, 0)
+	header := Sign(secret, at, body)
+	rotated := Sign("whsec_old", at, body) + "," + header[len("t=1700000000,"):]
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		body    []byte
+		now     time.Time
+		wantErr error
+	}{
+		{"верная подпись", secret, header, body, at.Add(time.Minute), nil},
+		{"несколько значений v1", secret, rotated, body, at, nil},
+		{"измененное тело", secret, header, []byte(`{"id":2,"type":"task.created"}`), at, ErrInvalidSignature},
+		{"чужой секрет", "whsec_other", header, body, at, ErrInvalidSignature},
+		{"подпись устарела", secret, header, body, at.Add(10 * time.Minute), ErrExpiredSignature},
+		{"подпись из будущего", secret, header, body, at.Add(-10 * time.Minute), ErrExpiredSignature},
+		{"без подписи", secret, "", body, at, ErrInvalidSignature},
+		{"без времени", secret, header[len("t=1700000000,"):], body, at, ErrInvalidSignature},
+		{"другое время", secret, "t=1700000001," + header[len("t=1700000000,"):], body, at, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify: %v, ожидалось %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Нулевой tolerance не проверяет время
+	if err := Verify(secret, header, body, 0, at.Add(24*time.Hour)); err != nil {
+		t.Fatalf("Verify без ограничения времени: %v", err)
+	}
+}